@@ -0,0 +1,155 @@
+package chain
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// arbSysArbOSVersion describes the value reported by the ArbSys stub's arbOSVersion method. Arbitrum reports its ArbOS
+// version offset by 55, so this describes ArbOS version 32.
+const arbSysArbOSVersion = 55 + 32
+
+// arbSysL1MessageCountSlot describes the storage slot of the ArbSys stub which holds the number of messages sent to L1.
+var arbSysL1MessageCountSlot = common.Hash{}
+
+// getArbSysPrecompileContract obtains a CheatCodeContract which stubs the ArbSys system contract found on Arbitrum
+// chains. Methods which query the chain are answered using the test chain, while methods which send messages to L1
+// only return a unique identifier for the message.
+// Returns the precompiled contract, or an error if one occurs.
+func getArbSysPrecompileContract(tracer *cheatCodeTracer, address common.Address) (*CheatCodeContract, error) {
+	// Create a new precompile to add methods to.
+	contract := newCheatCodeContract(tracer, address, "ArbSys")
+
+	// Define some basic ABI argument types
+	typeAddress, err := abi.NewType("address", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	typeBytes, err := abi.NewType("bytes", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	typeBytes32, err := abi.NewType("bytes32", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	typeUint256, err := abi.NewType("uint256", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	typeBool, err := abi.NewType("bool", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// arbBlockNumber: Returns the current block number
+	contract.addMethod(
+		"arbBlockNumber", abi.Arguments{}, abi.Arguments{{Type: typeUint256}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return []any{new(big.Int).Set(tracer.evmContext.BlockNumber)}, nil
+		},
+	)
+
+	// arbBlockHash: Returns the hash of one of the 256 most recent blocks
+	contract.addMethod(
+		"arbBlockHash", abi.Arguments{{Type: typeUint256}}, abi.Arguments{{Type: typeBytes32}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			// Verify the block number references one of the 256 most recent blocks.
+			blockNumber := inputs[0].(*big.Int)
+			currentBlockNumber := tracer.evmContext.BlockNumber
+			if blockNumber.Cmp(currentBlockNumber) >= 0 || new(big.Int).Sub(currentBlockNumber, blockNumber).Cmp(big.NewInt(256)) > 0 {
+				return nil, cheatCodeRevertData([]byte("arbBlockHash: invalid block number"))
+			}
+
+			// Obtain the block hash from our chain.
+			blockHash, err := tracer.chain.BlockHashFromNumber(blockNumber.Uint64())
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(err.Error()))
+			}
+			return []any{blockHash}, nil
+		},
+	)
+
+	// arbChainID: Returns the chain ID
+	contract.addMethod(
+		"arbChainID", abi.Arguments{}, abi.Arguments{{Type: typeUint256}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return []any{new(big.Int).Set(tracer.evmContext.ChainConfig.ChainID)}, nil
+		},
+	)
+
+	// arbOSVersion: Returns the ArbOS version
+	contract.addMethod(
+		"arbOSVersion", abi.Arguments{}, abi.Arguments{{Type: typeUint256}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return []any{big.NewInt(arbSysArbOSVersion)}, nil
+		},
+	)
+
+	// getStorageGasAvailable: Returns the storage gas available, which is always zero since ArbOS 2
+	contract.addMethod(
+		"getStorageGasAvailable", abi.Arguments{}, abi.Arguments{{Type: typeUint256}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return []any{big.NewInt(0)}, nil
+		},
+	)
+
+	// isTopLevelCall: Returns whether the caller was called directly by an externally owned account
+	contract.addMethod(
+		"isTopLevelCall", abi.Arguments{}, abi.Arguments{{Type: typeBool}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return []any{tracer.callDepth <= 1}, nil
+		},
+	)
+
+	// wasMyCallersAddressAliased: Returns whether the caller's caller was an aliased L1 contract. Messages are never
+	// sent from L1 on the test chain, so this is always false.
+	contract.addMethod(
+		"wasMyCallersAddressAliased", abi.Arguments{}, abi.Arguments{{Type: typeBool}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return []any{false}, nil
+		},
+	)
+
+	// myCallersAddressWithoutAliasing: Returns the caller's caller, without L1 contract address aliasing.
+	contract.addMethod(
+		"myCallersAddressWithoutAliasing", abi.Arguments{}, abi.Arguments{{Type: typeAddress}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			callerFrame := tracer.PreviousCallFrame()
+			if callerFrame == nil || callerFrame.vmScope == nil {
+				return []any{common.Address{}}, nil
+			}
+			return []any{callerFrame.vmScope.Caller()}, nil
+		},
+	)
+
+	// Messages sent to L1 are not delivered, but each is assigned a unique identifier. The number of messages sent is
+	// kept in the stub's storage, so it is reverted along with the messages, and is carried over when the chain is
+	// cloned or imported.
+	sendL1Message := func(tracer *cheatCodeTracer) *big.Int {
+		stateDB := tracer.chain.State()
+		messageId := stateDB.GetState(address, arbSysL1MessageCountSlot).Big()
+		stateDB.SetState(address, arbSysL1MessageCountSlot, common.BigToHash(new(big.Int).Add(messageId, big.NewInt(1))))
+		return messageId
+	}
+
+	// sendTxToL1: Sends a transaction to L1, returning a unique identifier for it
+	contract.addMethod(
+		"sendTxToL1", abi.Arguments{{Type: typeAddress}, {Type: typeBytes}}, abi.Arguments{{Type: typeUint256}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return []any{sendL1Message(tracer)}, nil
+		},
+	)
+
+	// withdrawEth: Sends the call value to an address on L1, returning a unique identifier for the withdrawal
+	contract.addMethod(
+		"withdrawEth", abi.Arguments{{Type: typeAddress}}, abi.Arguments{{Type: typeUint256}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return []any{sendL1Message(tracer)}, nil
+		},
+	)
+
+	return contract, nil
+}
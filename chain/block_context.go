@@ -1,15 +1,22 @@
 package chain
 
 import (
+	chainTypes "github.com/crytic/medusa/chain/types"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"math/big"
 )
 
 // newTestChainBlockContext obtains a new vm.BlockContext that is tailored to provide data from a TestChain.
-func newTestChainBlockContext(testChain *TestChain, header *types.Header) vm.BlockContext {
+// If the chain is configured for an EVM version prior to the merge, no randomness is provided, which indicates to the EVM
+// that pre-merge rules (e.g. DIFFICULTY rather than PREVRANDAO) apply.
+func newTestChainBlockContext(testChain *TestChain, block *chainTypes.Block) vm.BlockContext {
+	header := block.Header
+	var random *common.Hash
+	if testChain.chainConfig.TerminalTotalDifficulty != nil {
+		random = &header.MixDigest
+	}
 	return vm.BlockContext{
 		CanTransfer: core.CanTransfer,
 		Transfer:    core.Transfer,
@@ -23,8 +30,9 @@ func newTestChainBlockContext(testChain *TestChain, header *types.Header) vm.Blo
 		BlockNumber: new(big.Int).Set(header.Number),
 		Time:        header.Time,
 		Difficulty:  new(big.Int).Set(header.Difficulty),
-		BaseFee:     new(big.Int).Set(testChain.Head().Header.BaseFee),
+		BaseFee:     new(big.Int).Set(header.BaseFee),
 		GasLimit:    header.GasLimit,
-		Random:      &header.MixDigest,
+		Random:      random,
+		BlobBaseFee: new(big.Int).Set(block.BlobBaseFee),
 	}
 }
@@ -0,0 +1,42 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/holiman/uint256"
+	"golang.org/x/exp/slices"
+)
+
+// deployContractArtifact deploys the contract artifact registered with the chain which matches the provided identifier,
+// from the account whose storage the provided call frame executes over, with the provided constructor arguments.
+// Returns the address of the deployed contract, or an error if one occurred.
+func (t *cheatCodeTracer) deployContractArtifact(callFrame *cheatCodeTracerCallFrame, identifier string, constructorArgs []byte) (common.Address, error) {
+	// Obtain the init bytecode of our contract, with our constructor arguments appended.
+	artifact, err := t.chain.ContractArtifact(identifier)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(artifact.CompiledContract.InitBytecode) == 0 {
+		return common.Address{}, fmt.Errorf("%s:%s has no bytecode to deploy", artifact.SourcePath, artifact.Name)
+	}
+	initBytecode := append(slices.Clone(artifact.CompiledContract.InitBytecode), constructorArgs...)
+
+	// We deploy the contract with the EVM which called the cheat code, so the deployment is traced as a child of the
+	// cheat code's call frame, and its changes are reverted if the call to the cheat code is. Under a DELEGATECALL, the
+	// call frame's address is that of the code it executes, so we deploy from the account whose storage it executes
+	// over instead. The deployment does not consume the caller's gas.
+	if callFrame == nil || callFrame.vmScope == nil {
+		return common.Address{}, errors.New("contracts can only be deployed from a contract")
+	}
+	deployer := callFrame.vmScope.Address()
+	_, address, _, err := t.chain.pendingEVM.Create(vm.AccountRef(deployer), initBytecode, t.chain.pendingBlockContext.GasLimit, uint256.NewInt(0))
+	if errors.Is(err, vm.ErrExecutionReverted) {
+		return common.Address{}, fmt.Errorf("the constructor of %s:%s reverted", artifact.SourcePath, artifact.Name)
+	} else if err != nil {
+		return common.Address{}, fmt.Errorf("%s:%s could not be deployed: %v", artifact.SourcePath, artifact.Name, err)
+	}
+	return address, nil
+}
@@ -27,6 +27,10 @@ type CheatCodeContract struct {
 
 	// abi refers to the cheat code contract's ABI definition.
 	abi abi.ABI
+
+	// callersRestricted indicates whether calls to the cheat code contract are restricted to the allowed callers
+	// described by the cheat code configuration.
+	callersRestricted bool
 }
 
 // cheatCodeMethod defines the method information for a given precompiledContract.
@@ -121,6 +125,16 @@ func (c *CheatCodeContract) Abi() *abi.ABI {
 	return &c.abi
 }
 
+// ExpectationFailures provides messages describing each expectation set by a cheat code (e.g. expectRevert) which was
+// not met so far during the transaction currently or last executed by the chain the contract is installed on. This
+// can be used by other tracers to attribute failures to call frames as they exit.
+func (c *CheatCodeContract) ExpectationFailures() []string {
+	if c.tracer.results == nil {
+		return nil
+	}
+	return c.tracer.results.expectationFailures
+}
+
 // addMethod adds a new method to the precompiled contract.
 // Throws a panic if either the name is the empty string or the handler is nil.
 func (c *CheatCodeContract) addMethod(name string, inputs abi.Arguments, outputs abi.Arguments, handler cheatCodeMethodHandler) {
@@ -170,6 +184,15 @@ func (c *CheatCodeContract) Run(input []byte) ([]byte, error) {
 		return []byte{}, vm.ErrExecutionReverted
 	}
 
+	// If calls to this contract are restricted, ensure the caller is permitted to call cheat codes.
+	if c.callersRestricted {
+		caller := c.tracer.cheatCodeCaller()
+		if !c.tracer.isCheatCodeCallerAllowed(caller) {
+			rawReturnData := c.tracer.denyCheatCodeCall(fmt.Sprintf("%v: caller %v is not permitted to call cheat codes", methodInfo.method.Name, caller.String()))
+			return rawReturnData.ReturnData, rawReturnData.Err
+		}
+	}
+
 	// This call is targeting a valid method, unpack its arguments
 	inputValues, err := methodInfo.method.Inputs.Unpack(input[4:])
 	if err != nil {
@@ -0,0 +1,131 @@
+package chain
+
+import (
+	"fmt"
+	"math/big"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// resolveCheatCodePath resolves the provided path against the project root, and verifies the chain configuration
+// allows file system cheat codes to access it. Paths are readable if they are within a readable or writable path, and
+// writable if they are within a writable path.
+// Returns the absolute path, or an error if it could not be resolved or its access is not allowed.
+func (t *cheatCodeTracer) resolveCheatCodePath(path string, write bool) (string, error) {
+	absolutePath, err := resolveAbsolutePath(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Check whether the path is within any path we are allowed to access.
+	cheatCodeConfig := t.chain.testChainConfig.CheatCodeConfig
+	allowedPaths := cheatCodeConfig.WritablePaths
+	if !write {
+		allowedPaths = append(append([]string{}, cheatCodeConfig.ReadablePaths...), cheatCodeConfig.WritablePaths...)
+	}
+	for _, allowedPath := range allowedPaths {
+		absoluteAllowedPath, err := resolveAbsolutePath(allowedPath)
+		if err != nil {
+			continue
+		}
+		relativePath, err := filepath.Rel(absoluteAllowedPath, absolutePath)
+		if err == nil && relativePath != ".." && !strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
+			return absolutePath, nil
+		}
+	}
+
+	if write {
+		return "", fmt.Errorf("'%s' is not a writable path in the chain configuration", path)
+	}
+	return "", fmt.Errorf("'%s' is not a readable path in the chain configuration", path)
+}
+
+// resolveAbsolutePath resolves the provided path against the project root (the working directory), following any
+// symbolic links in it, so it cannot escape a directory it appears to be in.
+// Returns the absolute path, or an error if it could not be resolved.
+func resolveAbsolutePath(path string) (string, error) {
+	absolutePath, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Resolve symbolic links in the longest part of the path which exists, as the rest may not exist yet.
+	existingPath, remainingPath := absolutePath, ""
+	for {
+		if resolvedPath, err := filepath.EvalSymlinks(existingPath); err == nil {
+			return filepath.Join(resolvedPath, remainingPath), nil
+		}
+		parentPath := filepath.Dir(existingPath)
+		if parentPath == existingPath {
+			return absolutePath, nil
+		}
+		remainingPath = filepath.Join(filepath.Base(existingPath), remainingPath)
+		existingPath = parentPath
+	}
+}
+
+// parseCheatCodeStringValue parses a string provided to a cheat code (e.g. the value of an environment variable) as a
+// value of the provided ABI type. Integers may be decimal or 0x-prefixed hexadecimal, and bytes and bytes32 values must
+// be 0x-prefixed hexadecimal.
+// Returns the parsed value, or an error if the value could not be parsed.
+func parseCheatCodeStringValue(value string, typ abi.Type) (any, error) {
+	// Strings are returned as-is, while other values may be surrounded by whitespace.
+	if typ.T == abi.StringTy {
+		return value, nil
+	}
+	value = strings.TrimSpace(value)
+
+	switch typ.T {
+	case abi.UintTy, abi.IntTy:
+		// Parse our integer and verify it fits in our type.
+		base, digits := 10, value
+		if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+			base, digits = 16, value[2:]
+		}
+		n, ok := new(big.Int).SetString(digits, base)
+		if !ok {
+			return nil, fmt.Errorf("'%s' is not a valid integer", value)
+		}
+		min, max := utils.GetIntegerConstraints(typ.T == abi.IntTy, typ.Size)
+		if n.Cmp(min) < 0 || n.Cmp(max) > 0 {
+			return nil, fmt.Errorf("'%s' is out of bounds for type %s", value, typ)
+		}
+		return n, nil
+	case abi.AddressTy:
+		if !strings.HasPrefix(value, "0x") || len(value) != 42 {
+			return nil, fmt.Errorf("'%s' is not a valid address", value)
+		}
+		address, err := utils.HexStringToAddress(value)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid address", value)
+		}
+		return address, nil
+	case abi.BoolTy:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid boolean", value)
+		}
+		return b, nil
+	case abi.FixedBytesTy:
+		b, err := hexutil.Decode(value)
+		if err != nil || len(b) > typ.Size {
+			return nil, fmt.Errorf("'%s' is not a valid %s value", value, typ)
+		}
+		var bArray [32]byte
+		copy(bArray[:], b)
+		return bArray, nil
+	case abi.BytesTy:
+		b, err := hexutil.Decode(value)
+		if err != nil {
+			return nil, fmt.Errorf("'%s' is not a valid bytes value", value)
+		}
+		return b, nil
+	default:
+		return nil, fmt.Errorf("strings cannot be parsed as type %s", typ)
+	}
+}
@@ -0,0 +1,349 @@
+package chain
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	coretypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// cheatCodeExpectedRevert describes a revert expected of a call, set by the expectRevert cheat codes.
+type cheatCodeExpectedRevert struct {
+	// revertData describes the data the call is expected to revert with. If nil, the call may revert with any data.
+	revertData []byte
+
+	// prefixMatch indicates whether the revert data of the call only needs to begin with revertData, rather than
+	// match it exactly. This is used to match custom errors by their selector alone.
+	prefixMatch bool
+}
+
+// matches indicates whether a call which exited with the provided output and error satisfies the expected revert.
+// Returns a message describing why the call did not satisfy the expected revert, or an empty string if it did.
+func (e *cheatCodeExpectedRevert) matches(output []byte, err error) string {
+	// Verify the call reverted.
+	if err == nil {
+		return "expectRevert: call did not revert as expected"
+	}
+
+	// Verify the call reverted with the data we expected, if any.
+	if e.revertData == nil {
+		return ""
+	}
+	if !errors.Is(err, vm.ErrExecutionReverted) {
+		return fmt.Sprintf("expectRevert: call failed with error '%v' rather than reverting with data %v", err, hexutil.Encode(e.revertData))
+	}
+	if (e.prefixMatch && !bytes.HasPrefix(output, e.revertData)) || (!e.prefixMatch && !bytes.Equal(output, e.revertData)) {
+		return fmt.Sprintf("expectRevert: call reverted with data %v rather than %v", hexutil.Encode(output), hexutil.Encode(e.revertData))
+	}
+	return ""
+}
+
+// cheatCodeExpectedEmit describes an event expected to be emitted, set by the expectEmit cheat codes.
+type cheatCodeExpectedEmit struct {
+	// checkTopics describes whether each of the first, second, and third indexed topics of the expected event should
+	// be matched. The event signature topic is always matched.
+	checkTopics [3]bool
+
+	// checkData describes whether the non-indexed data of the expected event should be matched.
+	checkData bool
+
+	// emitter describes the address the expected event must be emitted by. If nil, it may be emitted by any address.
+	emitter *common.Address
+
+	// log describes the expected event. It is nil until the call frame which set the expectation emits the event it
+	// expects, which is captured rather than matched.
+	log *coretypes.Log
+
+	// logIndex describes the index of the call frame's recorded logs from which the expected event may be matched.
+	logIndex int
+}
+
+// matches indicates whether the provided log satisfies the expected event.
+func (e *cheatCodeExpectedEmit) matches(log *coretypes.Log) bool {
+	// Verify the number of topics and the event signature topic match.
+	if len(log.Topics) != len(e.log.Topics) || (len(log.Topics) > 0 && log.Topics[0] != e.log.Topics[0]) {
+		return false
+	}
+
+	// Verify each of the indexed topics we were asked to check match.
+	for i, checkTopic := range e.checkTopics {
+		if checkTopic && i+1 < len(log.Topics) && log.Topics[i+1] != e.log.Topics[i+1] {
+			return false
+		}
+	}
+
+	// Verify the data and emitter, if we were asked to check them.
+	if e.checkData && !bytes.Equal(log.Data, e.log.Data) {
+		return false
+	}
+	return e.emitter == nil || log.Address == *e.emitter
+}
+
+// String returns a string describing the expected event.
+func (e *cheatCodeExpectedEmit) String() string {
+	topics := make([]string, 0)
+	for i, topic := range e.log.Topics {
+		if i == 0 || e.checkTopics[i-1] {
+			topics = append(topics, topic.Hex())
+		} else {
+			topics = append(topics, "<any>")
+		}
+	}
+	data := "<any>"
+	if e.checkData {
+		data = hexutil.Encode(e.log.Data)
+	}
+	emitter := "<any>"
+	if e.emitter != nil {
+		emitter = e.emitter.String()
+	}
+	return fmt.Sprintf("event(emitter=%v, topics=%v, data=%v)", emitter, topics, data)
+}
+
+// cheatCodeExpectedCall describes a call expected to be made, set by the expectCall cheat codes.
+type cheatCodeExpectedCall struct {
+	// callee describes the address the expected call must be made to.
+	callee common.Address
+
+	// data describes the data the call data of the expected call must begin with.
+	data []byte
+
+	// value describes the value the expected call must be made with. If nil, it may be made with any value.
+	value *big.Int
+
+	// callIndex describes the index of the call frame's recorded calls from which the expected call may be matched.
+	callIndex int
+}
+
+// matches indicates whether the provided call satisfies the expected call.
+func (e *cheatCodeExpectedCall) matches(call *cheatCodeTracedCall) bool {
+	if call.to != e.callee || !bytes.HasPrefix(call.input, e.data) {
+		return false
+	}
+	if e.value == nil {
+		return true
+	}
+	callValue := call.value
+	if callValue == nil {
+		callValue = big.NewInt(0)
+	}
+	return callValue.Cmp(e.value) == 0
+}
+
+// String returns a string describing the expected call.
+func (e *cheatCodeExpectedCall) String() string {
+	if e.value == nil {
+		return fmt.Sprintf("call(to=%v, data=%v)", e.callee, hexutil.Encode(e.data))
+	}
+	return fmt.Sprintf("call(to=%v, data=%v, value=%v)", e.callee, hexutil.Encode(e.data), e.value)
+}
+
+// cheatCodeTracedCall describes a call recorded by the cheatCodeTracer, to verify calls expected by the expectCall
+// cheat codes.
+type cheatCodeTracedCall struct {
+	// to describes the address the call was made to.
+	to common.Address
+
+	// input describes the call data the call was made with.
+	input []byte
+
+	// value describes the value the call was made with.
+	value *big.Int
+}
+
+// isCheatCodeContractAddress indicates whether the provided address is that of a cheat code contract. Calls to cheat
+// code contracts do not satisfy cheat code expectations, so expectations can be set ahead of other cheat codes.
+func isCheatCodeContractAddress(address common.Address) bool {
+	return address == StandardCheatcodeContractAddress || address == ConsoleLogContractAddress
+}
+
+// cheatCodeReturnMemory describes the region of memory a call copies its return data to, as it was prior to the call.
+type cheatCodeReturnMemory struct {
+	// offset describes the offset of the region in memory.
+	offset uint64
+
+	// data describes the contents of the region prior to the call.
+	data []byte
+}
+
+// checkExpectedRevert verifies a call which exited with the provided output and error satisfied the provided expected
+// revert. Once the parent call frame resumes execution, the result of the call it observes is updated: calls which
+// satisfied the expectation are reported as successful with no return data, as they are in Foundry, while calls which
+// did not are reported as failed, so the parent call frame reverts, and the failure is recorded in the tracer results.
+func (t *cheatCodeTracer) checkExpectedRevert(expectedRevert *cheatCodeExpectedRevert, parentCallFrame *cheatCodeTracerCallFrame, output []byte, err error) {
+	failureMessage := expectedRevert.matches(output, err)
+	if failureMessage != "" {
+		t.addExpectationFailure(failureMessage)
+	} else {
+		t.clearReturnData(parentCallFrame)
+	}
+
+	t.setCallResult(parentCallFrame, failureMessage == "")
+}
+
+// clearReturnData clears the return data of the call the provided parent call frame last made, as observed by the
+// parent call frame once it resumes execution. The memory the call copied its return data to is restored, and the
+// return data is observed as empty until the parent call frame makes another call.
+func (t *cheatCodeTracer) clearReturnData(parentCallFrame *cheatCodeTracerCallFrame) {
+	returnMemory := parentCallFrame.nextCallReturnMemory
+	parentCallFrame.nextCallReturnMemory = nil
+	parentCallFrame.onNextOpcodeHooks.Push(func() {
+		// We can cast OpContext to ScopeContext because that is the type passed to OnOpcode.
+		if returnMemory != nil && len(returnMemory.data) > 0 {
+			scopeContext := parentCallFrame.vmScope.(*vm.ScopeContext)
+			scopeContext.Memory.Set(returnMemory.offset, uint64(len(returnMemory.data)), returnMemory.data)
+		}
+		parentCallFrame.returnDataCleared = true
+	})
+}
+
+// applyClearedReturnData is called before the provided call frame executes the provided instruction, while the return
+// data of its last call is cleared, or its next call is expected to revert. Instructions which read the return data are
+// updated to observe it as empty, and the memory a call expected to revert copies its return data to is recorded, so
+// it can be restored if the call satisfies the expectation.
+func (t *cheatCodeTracer) applyClearedReturnData(callFrame *cheatCodeTracerCallFrame, opCode vm.OpCode) {
+	// We can cast OpContext to ScopeContext because that is the type passed to OnOpcode.
+	scopeContext := callFrame.vmScope.(*vm.ScopeContext)
+	switch opCode {
+	case vm.RETURNDATASIZE:
+		// Report the size of the return data as zero once it has been pushed onto the stack.
+		if callFrame.returnDataCleared {
+			callFrame.onNextOpcodeHooks.Push(func() {
+				scopeContext.Stack.Back(0).Clear()
+			})
+		}
+	case vm.RETURNDATACOPY:
+		// Copying any data from empty return data is out of bounds, so we make the data offset overflow to fail the
+		// copy as it would with empty return data.
+		dataOffset, length := scopeContext.Stack.Back(1), scopeContext.Stack.Back(2)
+		if callFrame.returnDataCleared && (!dataOffset.IsZero() || !length.IsZero()) {
+			dataOffset.SetAllOne()
+		}
+	case vm.CALL, vm.CALLCODE, vm.DELEGATECALL, vm.STATICCALL, vm.CREATE, vm.CREATE2:
+		// Each of these instructions replaces the return data, so it is no longer cleared.
+		callFrame.returnDataCleared = false
+
+		// If this call is expected to revert, record the memory it copies its return data to. Creations do not
+		// satisfy expected reverts, and copy no return data to memory.
+		callFrame.nextCallReturnMemory = nil
+		if callFrame.nextCallExpectedRevert == nil || opCode == vm.CREATE || opCode == vm.CREATE2 {
+			return
+		}
+		returnOffsetIndex := 5
+		if opCode == vm.DELEGATECALL || opCode == vm.STATICCALL {
+			returnOffsetIndex = 4
+		}
+		returnOffset, returnSize := scopeContext.Stack.Back(returnOffsetIndex), scopeContext.Stack.Back(returnOffsetIndex+1)
+		if returnSize.IsZero() || !returnOffset.IsUint64() || !returnSize.IsUint64() {
+			return
+		}
+
+		// If the call frame cannot afford to expand memory to fit the region, the call fails before it is made, so we
+		// do not record it.
+		if returnSize.Uint64()/32*params.MemoryGas > callFrame.vmGas {
+			return
+		}
+
+		// Memory is expanded to fit the region once the call begins, so any part of the region not yet in memory is
+		// empty.
+		returnMemory := &cheatCodeReturnMemory{
+			offset: returnOffset.Uint64(),
+			data:   make([]byte, returnSize.Uint64()),
+		}
+		if memory := scopeContext.MemoryData(); returnMemory.offset < uint64(len(memory)) {
+			copy(returnMemory.data, memory[returnMemory.offset:])
+		}
+		callFrame.nextCallReturnMemory = returnMemory
+	}
+}
+
+// recordLog records a log emitted by the provided call frame. If the call frame expects an event it has not yet
+// described, the log is captured as the expected event instead.
+func (t *cheatCodeTracer) recordLog(callFrame *cheatCodeTracerCallFrame, log *coretypes.Log) {
+	for _, expectedEmit := range callFrame.expectedEmits {
+		if expectedEmit.log == nil {
+			expectedEmit.log = log
+			expectedEmit.logIndex = len(callFrame.logs)
+			return
+		}
+	}
+	callFrame.logs = append(callFrame.logs, log)
+}
+
+// checkExpectedEmitsAndCalls verifies a call frame which exited without error emitted the events and made the calls it
+// expected. If it did not, each unmet expectation is recorded in the tracer results, and the call is reported as failed
+// to the parent call frame, if one is provided.
+func (t *cheatCodeTracer) checkExpectedEmitsAndCalls(callFrame *cheatCodeTracerCallFrame, parentCallFrame *cheatCodeTracerCallFrame) {
+	met := true
+
+	// Verify our expected events were emitted in the order they were expected.
+	nextLogIndex := 0
+	for _, expectedEmit := range callFrame.expectedEmits {
+		if expectedEmit.log == nil {
+			t.addExpectationFailure("expectEmit: no event was emitted to describe the expected event")
+			met = false
+			continue
+		}
+
+		found := false
+		for i := max(nextLogIndex, expectedEmit.logIndex); i < len(callFrame.logs); i++ {
+			if expectedEmit.matches(callFrame.logs[i]) {
+				nextLogIndex = i + 1
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.addExpectationFailure(fmt.Sprintf("expectEmit: expected %v was not emitted", expectedEmit))
+			met = false
+		}
+	}
+
+	// Verify our expected calls were made.
+	for _, expectedCall := range callFrame.expectedCalls {
+		found := false
+		for _, call := range callFrame.calls[expectedCall.callIndex:] {
+			if expectedCall.matches(call) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.addExpectationFailure(fmt.Sprintf("expectCall: expected %v was not made", expectedCall))
+			met = false
+		}
+	}
+
+	// If any expectation was not met, report the call as failed.
+	if !met && parentCallFrame != nil {
+		t.setCallResult(parentCallFrame, false)
+	}
+}
+
+// setCallResult overrides the result of the call the provided parent call frame last made, as observed by the parent
+// call frame once it resumes execution.
+func (t *cheatCodeTracer) setCallResult(parentCallFrame *cheatCodeTracerCallFrame, success bool) {
+	// The call instruction in the parent call frame pushed a flag indicating whether the call succeeded onto the
+	// stack, which we override before the next instruction executes.
+	parentCallFrame.onNextOpcodeHooks.Push(func() {
+		// We can cast OpContext to ScopeContext because that is the type passed to OnOpcode.
+		scopeContext := parentCallFrame.vmScope.(*vm.ScopeContext)
+		successFlag := scopeContext.Stack.Back(0)
+		if success {
+			successFlag.SetOne()
+		} else {
+			successFlag.Clear()
+		}
+	})
+}
+
+// addExpectationFailure records a message describing a cheat code expectation which was not met in the tracer results.
+func (t *cheatCodeTracer) addExpectationFailure(message string) {
+	t.results.expectationFailures = append(t.results.expectationFailures, message)
+}
@@ -0,0 +1,90 @@
+package chain
+
+import (
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// CheatCodeGasSnapshot describes the gas used by a call frame between the startSnapshotGas and stopSnapshotGas cheat
+// codes.
+type CheatCodeGasSnapshot struct {
+	// Label describes the label provided to the startSnapshotGas cheat code.
+	Label string
+
+	// GasUsed describes the gas used by the call frame while the snapshot was in progress.
+	GasUsed uint64
+}
+
+// cheatCodeActiveGasSnapshot describes a gas snapshot started by the startSnapshotGas cheat code, which has not yet been
+// stopped.
+type cheatCodeActiveGasSnapshot struct {
+	// label describes the label provided to the startSnapshotGas cheat code.
+	label string
+
+	// startGas describes the gas remaining in the call frame when the snapshot started.
+	startGas uint64
+}
+
+// meterGas applies paused gas metering to the provided call frame as it executes an instruction, given the gas it had
+// remaining prior to the instruction and the instruction's cost. While gas metering is paused, the call frame's gas is
+// held at the amount it had remaining when metering was paused. Once metering resumes, the call frame continues from
+// that amount.
+func (t *cheatCodeTracer) meterGas(callFrame *cheatCodeTracerCallFrame, gas uint64, cost uint64) {
+	// If gas metering is paused and this call frame has not yet been held, hold it at its current gas.
+	if t.gasMeteringPaused && callFrame.pausedGas == nil {
+		callFrame.pausedGas = &gas
+	}
+	if callFrame.pausedGas == nil {
+		return
+	}
+
+	// We can cast OpContext to ScopeContext because that is the type passed to OnOpcode.
+	// The cost of the instruction has already been deducted, so we refund it while paused. Once resumed, we charge it
+	// against our held gas instead, as gas returned by calls made while paused would otherwise be kept.
+	scopeContext := callFrame.vmScope.(*vm.ScopeContext)
+	pausedGas := *callFrame.pausedGas
+	callFrame.vmGas = pausedGas
+	if t.gasMeteringPaused {
+		scopeContext.Contract.Gas = pausedGas
+	} else {
+		if cost <= pausedGas {
+			scopeContext.Contract.Gas = pausedGas - cost
+		}
+		callFrame.pausedGas = nil
+	}
+}
+
+// startGasSnapshot starts a gas snapshot with the provided label in the provided call frame, measuring from the next
+// instruction it executes.
+// Returns false if a gas snapshot is already in progress in the call frame.
+func (t *cheatCodeTracer) startGasSnapshot(callFrame *cheatCodeTracerCallFrame, label string) bool {
+	if callFrame.activeGasSnapshot != nil {
+		return false
+	}
+	snapshot := &cheatCodeActiveGasSnapshot{label: label}
+	callFrame.activeGasSnapshot = snapshot
+	callFrame.onNextOpcodeHooks.Push(func() {
+		snapshot.startGas = callFrame.vmGas
+	})
+	return true
+}
+
+// stopGasSnapshot stops the gas snapshot in progress in the provided call frame, recording the gas used since it started.
+// Returns the gas used, and a boolean indicating whether a gas snapshot was in progress.
+func (t *cheatCodeTracer) stopGasSnapshot(callFrame *cheatCodeTracerCallFrame) (uint64, bool) {
+	snapshot := callFrame.activeGasSnapshot
+	if snapshot == nil {
+		return 0, false
+	}
+	callFrame.activeGasSnapshot = nil
+
+	// The call frame's gas was last recorded prior to the call to the cheat code contract which stopped the snapshot.
+	var gasUsed uint64
+	if snapshot.startGas > callFrame.vmGas {
+		gasUsed = snapshot.startGas - callFrame.vmGas
+	}
+	callFrame.gasSnapshots = append(callFrame.gasSnapshots, CheatCodeGasSnapshot{
+		Label:   snapshot.label,
+		GasUsed: gasUsed,
+	})
+	return gasUsed, true
+}
@@ -0,0 +1,146 @@
+package chain
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"golang.org/x/exp/slices"
+)
+
+// cheatCodeMockedCall describes a call whose result is mocked, set by the mockCall cheat codes.
+type cheatCodeMockedCall struct {
+	// callee describes the address of the mocked calls.
+	callee common.Address
+
+	// data describes the data the call data of mocked calls must begin with.
+	data []byte
+
+	// value describes the value mocked calls must be made with. If nil, they may be made with any value.
+	value *big.Int
+
+	// returnData describes the data mocked calls return, or revert with.
+	returnData []byte
+
+	// revert indicates whether mocked calls revert with the return data, rather than returning it.
+	revert bool
+}
+
+// matches indicates whether a call with the provided callee, call data and value should be mocked.
+func (m *cheatCodeMockedCall) matches(callee common.Address, input []byte, value *big.Int) bool {
+	if callee != m.callee || !bytes.HasPrefix(input, m.data) {
+		return false
+	}
+	return m.value == nil || m.value.Cmp(value) == 0
+}
+
+// code obtains the bytecode which is executed in place of the callee's code when a call is mocked. It copies the
+// return data appended to it into memory, and returns or reverts with it.
+func (m *cheatCodeMockedCall) code() []byte {
+	// The length of our bytecode prior to the return data, which is appended to it.
+	const codeLength = 18
+	length := make([]byte, 4)
+	binary.BigEndian.PutUint32(length, uint32(len(m.returnData)))
+
+	code := make([]byte, 0, codeLength+len(m.returnData))
+	code = append(code, byte(vm.PUSH4))
+	code = append(code, length...)
+	code = append(code, byte(vm.PUSH1), codeLength, byte(vm.PUSH1), 0, byte(vm.CODECOPY), byte(vm.PUSH4))
+	code = append(code, length...)
+	code = append(code, byte(vm.PUSH1), 0)
+	if m.revert {
+		code = append(code, byte(vm.REVERT))
+	} else {
+		code = append(code, byte(vm.RETURN))
+	}
+	return append(code, m.returnData...)
+}
+
+// addMockedCall adds a mocked call to the tracer, replacing any existing mock of the same calls. The mock is removed
+// if the provided call frame reverts, or the chain reverts the transaction which added it.
+func (t *cheatCodeTracer) addMockedCall(callFrame *cheatCodeTracerCallFrame, mockedCall *cheatCodeMockedCall) {
+	// Create a new list of mocks rather than modifying the existing one, so it can be restored on revert.
+	original := t.mockedCalls
+	t.mockedCalls = make([]*cheatCodeMockedCall, 0, len(original)+1)
+	for _, existingMockedCall := range original {
+		sameValue := (existingMockedCall.value == nil && mockedCall.value == nil) ||
+			(existingMockedCall.value != nil && mockedCall.value != nil && existingMockedCall.value.Cmp(mockedCall.value) == 0)
+		if existingMockedCall.callee != mockedCall.callee || !bytes.Equal(existingMockedCall.data, mockedCall.data) || !sameValue {
+			t.mockedCalls = append(t.mockedCalls, existingMockedCall)
+		}
+	}
+	t.mockedCalls = append(t.mockedCalls, mockedCall)
+	callFrame.onChainRevertRestoreHooks.Push(func() {
+		t.mockedCalls = original
+	})
+}
+
+// clearMockedCalls removes all mocked calls from the tracer. The mocks are restored if the provided call frame
+// reverts, or the chain reverts the transaction which cleared them.
+func (t *cheatCodeTracer) clearMockedCalls(callFrame *cheatCodeTracerCallFrame) {
+	original := t.mockedCalls
+	t.mockedCalls = nil
+	callFrame.onChainRevertRestoreHooks.Push(func() {
+		t.mockedCalls = original
+	})
+}
+
+// isPrecompile indicates whether the provided address refers to a precompiled contract in the current context,
+// including any cheat code contracts or precompiles provided by the chain config.
+func (t *cheatCodeTracer) isPrecompile(address common.Address) bool {
+	if _, ok := t.chain.vmConfigExtensions.AdditionalPrecompiles[address]; ok {
+		return true
+	}
+	rules := t.evmContext.ChainConfig.Rules(t.evmContext.BlockNumber, t.evmContext.Random != nil, t.evmContext.Time)
+	return slices.Contains(vm.ActivePrecompiles(rules), address)
+}
+
+// applyMockedCall checks whether the call the provided call frame is about to make with the provided call instruction
+// is mocked. If it is, the callee's code is replaced with code which produces the mocked result, until the call exits.
+func (t *cheatCodeTracer) applyMockedCall(callFrame *cheatCodeTracerCallFrame, op vm.OpCode) {
+	// We can cast OpContext to ScopeContext because that is the type passed to OnOpcode.
+	scopeContext := callFrame.vmScope.(*vm.ScopeContext)
+
+	// Obtain the callee, value, and call data location from the stack, which differ in layout by instruction.
+	callee := common.Address(scopeContext.Stack.Back(1).Bytes20())
+	value := new(big.Int)
+	argsIndex := 2
+	if op == vm.CALL || op == vm.CALLCODE {
+		value = scopeContext.Stack.Back(2).ToBig()
+		argsIndex = 3
+	}
+	argsOffset, argsSize := scopeContext.Stack.Back(argsIndex), scopeContext.Stack.Back(argsIndex+1)
+	if !argsOffset.IsUint64() || !argsSize.IsUint64() {
+		return
+	}
+
+	// Obtain the call data from memory. Memory is not yet expanded for the call, so any data beyond it is zero.
+	input := make([]byte, argsSize.Uint64())
+	memory := scopeContext.Memory.Data()
+	if argsOffset.Uint64() < uint64(len(memory)) {
+		copy(input, memory[argsOffset.Uint64():])
+	}
+
+	// Find the mock which applies to the call, preferring the most specific (longest) call data, then the most
+	// recently added.
+	var mockedCall *cheatCodeMockedCall
+	for _, candidate := range t.mockedCalls {
+		if candidate.matches(callee, input, value) && (mockedCall == nil || len(candidate.data) >= len(mockedCall.data)) {
+			mockedCall = candidate
+		}
+	}
+	if mockedCall == nil {
+		return
+	}
+
+	// Replace the callee's code with our mock code, and restore it when the call exits.
+	stateDB := t.evmContext.StateDB.(*state.StateDB)
+	original := stateDB.GetCode(callee)
+	stateDB.SetCode(callee, mockedCall.code())
+	callFrame.onNextFrameExitRestoreHooks.Push(func() {
+		stateDB.SetCode(callee, original)
+	})
+}
@@ -0,0 +1,134 @@
+package chain
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+
+	"github.com/crytic/medusa/chain/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// cheatCodePersistentState describes the state of a cheatCodeTracer which persists across transactions, such as mocked
+// calls and remembered keys. It is stored in the results of every transaction which leaves it non-empty, so it can be
+// restored when blocks are restored onto another chain (e.g. when cloning or importing a chain) without re-executing
+// their messages. The values it holds are never modified once stored, as the tracer replaces them when they change.
+type cheatCodePersistentState struct {
+	// mockedCalls describes the calls whose results are mocked.
+	mockedCalls []*cheatCodeMockedCall
+
+	// rememberedKeys describes the private keys remembered by the rememberKey cheat code, by their address.
+	rememberedKeys map[common.Address]*ecdsa.PrivateKey
+}
+
+// exportedCheatCodePersistentState describes a cheatCodePersistentState in a serializable form, as stored in a
+// TestChainExport.
+type exportedCheatCodePersistentState struct {
+	// MockedCalls describes the calls whose results are mocked.
+	MockedCalls []exportedCheatCodeMockedCall `json:"mockedCalls"`
+
+	// RememberedKeys describes the private keys remembered by the rememberKey cheat code.
+	RememberedKeys []hexutil.Bytes `json:"rememberedKeys"`
+}
+
+// exportedCheatCodeMockedCall describes a cheatCodeMockedCall in a serializable form, as stored in a TestChainExport.
+type exportedCheatCodeMockedCall struct {
+	// Callee describes the address of the mocked calls.
+	Callee common.Address `json:"callee"`
+
+	// Data describes the data the call data of mocked calls must begin with.
+	Data hexutil.Bytes `json:"data"`
+
+	// Value describes the value mocked calls must be made with, or nil if they may be made with any value.
+	Value *hexutil.Big `json:"value"`
+
+	// ReturnData describes the data mocked calls return, or revert with.
+	ReturnData hexutil.Bytes `json:"returnData"`
+
+	// Revert indicates whether mocked calls revert with the return data, rather than returning it.
+	Revert bool `json:"revert"`
+}
+
+// cheatCodePersistentStateKey describes the key to use when storing the persistent state of the cheat code tracer in
+// call message results, or when querying it.
+const cheatCodePersistentStateKey = "CheatCodePersistentState"
+
+// getCheatCodePersistentState obtains the persistent state of the cheat code tracer after the execution of a message,
+// from its results.
+// Returns the persistent state, or nil if it was empty.
+func getCheatCodePersistentState(messageResults *types.MessageResults) *cheatCodePersistentState {
+	if genericResult, ok := messageResults.AdditionalResults[cheatCodePersistentStateKey]; ok {
+		if castedResult, ok := genericResult.(*cheatCodePersistentState); ok {
+			return castedResult
+		}
+	}
+	return nil
+}
+
+// MarshalJSON provides JSON marshalling for a cheatCodePersistentState, so it can be stored in a TestChainExport.
+// Returns the JSON marshalled data, or an error if one occurs.
+func (s *cheatCodePersistentState) MarshalJSON() ([]byte, error) {
+	exported := exportedCheatCodePersistentState{
+		MockedCalls:    make([]exportedCheatCodeMockedCall, 0, len(s.mockedCalls)),
+		RememberedKeys: make([]hexutil.Bytes, 0, len(s.rememberedKeys)),
+	}
+	for _, mockedCall := range s.mockedCalls {
+		exported.MockedCalls = append(exported.MockedCalls, exportedCheatCodeMockedCall{
+			Callee:     mockedCall.callee,
+			Data:       mockedCall.data,
+			Value:      (*hexutil.Big)(mockedCall.value),
+			ReturnData: mockedCall.returnData,
+			Revert:     mockedCall.revert,
+		})
+	}
+	for _, privateKey := range s.rememberedKeys {
+		exported.RememberedKeys = append(exported.RememberedKeys, crypto.FromECDSA(privateKey))
+	}
+	return json.Marshal(exported)
+}
+
+// UnmarshalJSON provides JSON unmarshalling for a cheatCodePersistentState, so it can be restored from a
+// TestChainExport.
+// Returns an error if one occurs.
+func (s *cheatCodePersistentState) UnmarshalJSON(b []byte) error {
+	var exported exportedCheatCodePersistentState
+	err := json.Unmarshal(b, &exported)
+	if err != nil {
+		return err
+	}
+
+	s.mockedCalls = make([]*cheatCodeMockedCall, 0, len(exported.MockedCalls))
+	for _, mockedCall := range exported.MockedCalls {
+		s.mockedCalls = append(s.mockedCalls, &cheatCodeMockedCall{
+			callee:     mockedCall.Callee,
+			data:       mockedCall.Data,
+			value:      (*hexutil.Big)(mockedCall.Value).ToInt(),
+			returnData: mockedCall.ReturnData,
+			revert:     mockedCall.Revert,
+		})
+	}
+	s.rememberedKeys = make(map[common.Address]*ecdsa.PrivateKey, len(exported.RememberedKeys))
+	for _, exportedKey := range exported.RememberedKeys {
+		privateKey, err := crypto.ToECDSA(exportedKey)
+		if err != nil {
+			return err
+		}
+		s.rememberedKeys[crypto.PubkeyToAddress(privateKey.PublicKey)] = privateKey
+	}
+	return nil
+}
+
+// restorePersistentState sets the persistent state of the tracer to the state after the execution of a message, from
+// its results. This is used when a block is restored onto the chain without re-executing its messages. A hook is added
+// to the results to restore the current state if the chain reverts the message.
+func (t *cheatCodeTracer) restorePersistentState(results *types.MessageResults) {
+	originalMockedCalls, originalRememberedKeys := t.mockedCalls, t.rememberedKeys
+	t.mockedCalls, t.rememberedKeys = nil, nil
+	if persistentState := getCheatCodePersistentState(results); persistentState != nil {
+		t.mockedCalls, t.rememberedKeys = persistentState.mockedCalls, persistentState.rememberedKeys
+	}
+	results.OnRevertHookFuncs.Push(func() {
+		t.mockedCalls, t.rememberedKeys = originalMockedCalls, originalRememberedKeys
+	})
+}
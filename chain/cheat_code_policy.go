@@ -0,0 +1,104 @@
+package chain
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/crytic/medusa/chain/config"
+	"github.com/crytic/medusa/logging"
+	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/slices"
+)
+
+// cheatCodeFFICommand describes a command which the FFI cheat code may execute, as described by the cheat code
+// configuration, with its argument patterns compiled.
+type cheatCodeFFICommand struct {
+	// executable describes the executable which may be run.
+	executable string
+
+	// arguments describes the patterns which the arguments provided to the executable must match, in order. Each is
+	// anchored, so it must match its entire argument.
+	arguments []*regexp.Regexp
+}
+
+// compileFFICommands compiles the argument patterns of the provided allowed FFI commands, so they do not need to be
+// compiled each time the FFI cheat code is called.
+// Returns the compiled commands, or an error if a pattern could not be compiled.
+func compileFFICommands(commands []config.FFICommandConfig) ([]*cheatCodeFFICommand, error) {
+	compiledCommands := make([]*cheatCodeFFICommand, 0, len(commands))
+	for _, command := range commands {
+		compiledCommand := &cheatCodeFFICommand{
+			executable: command.Executable,
+			arguments:  make([]*regexp.Regexp, 0, len(command.Arguments)),
+		}
+		for _, pattern := range command.Arguments {
+			// Anchor our pattern so it must match the entire argument, rather than a substring of it.
+			re, err := regexp.Compile("^(?:" + pattern + ")$")
+			if err != nil {
+				return nil, fmt.Errorf("could not compile argument pattern for allowed FFI command %s: %v", command.Executable, err)
+			}
+			compiledCommand.arguments = append(compiledCommand.arguments, re)
+		}
+		compiledCommands = append(compiledCommands, compiledCommand)
+	}
+	return compiledCommands, nil
+}
+
+// cheatCodeCaller obtains the address of the account calling the cheat code contract being executed.
+func (t *cheatCodeTracer) cheatCodeCaller() common.Address {
+	// If the cheat code contract was called directly by a transaction, its caller is the transaction's sender. We do
+	// not use the transaction origin, as it may be changed by a prank.
+	callerFrame := t.PreviousCallFrame()
+	if callerFrame == nil || callerFrame.vmScope == nil {
+		return t.txSender
+	}
+	return callerFrame.vmScope.Address()
+}
+
+// isCheatCodeCallerAllowed indicates whether the provided account may call cheat codes, as described by the allowed
+// callers in the cheat code configuration.
+func (t *cheatCodeTracer) isCheatCodeCallerAllowed(caller common.Address) bool {
+	allowedCallers := t.chain.testChainConfig.CheatCodeConfig.AllowedCallers
+	return len(allowedCallers) == 0 || slices.Contains(allowedCallers, caller)
+}
+
+// isFFICommandAllowed indicates whether the provided command and arguments may be executed by the ffi cheat code, as
+// described by the allowed FFI commands in the cheat code configuration.
+func (t *cheatCodeTracer) isFFICommandAllowed(cmdAndInputs []string) bool {
+	if len(t.allowedFFICommands) == 0 {
+		return true
+	}
+
+	for _, allowedCommand := range t.allowedFFICommands {
+		// The executable must match exactly, and each argument must match the pattern in the same position.
+		if allowedCommand.executable != cmdAndInputs[0] || len(allowedCommand.arguments) != len(cmdAndInputs)-1 {
+			continue
+		}
+		argumentsMatch := true
+		for i, re := range allowedCommand.arguments {
+			if !re.MatchString(cmdAndInputs[i+1]) {
+				argumentsMatch = false
+				break
+			}
+		}
+		if argumentsMatch {
+			return true
+		}
+	}
+	return false
+}
+
+// denyCheatCodeCall logs a warning describing a cheat code call denied by the cheat code configuration, and creates
+// the raw return data to revert the call with. A warning is only logged the first time a given reason is encountered
+// by the tracer, so repeated calls made while fuzzing do not flood the logs.
+// Returns the raw return data to revert the call with.
+func (t *cheatCodeTracer) denyCheatCodeCall(reason string) *cheatCodeRawReturnData {
+	if _, reported := t.reportedDenials[reason]; !reported {
+		if t.reportedDenials == nil {
+			t.reportedDenials = make(map[string]struct{})
+		}
+		t.reportedDenials[reason] = struct{}{}
+		logging.GlobalLogger.Warn(fmt.Sprintf("Denied cheat code call: %v", reason))
+	}
+	return cheatCodeRevertData([]byte(reason))
+}
@@ -0,0 +1,51 @@
+package chain
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// cheatCodeCallerMode describes how the callers of the calls made by a call frame are modified by cheat codes, as
+// reported by the readCallers cheat code.
+type cheatCodeCallerMode uint8
+
+const (
+	// cheatCodeCallerModeNone indicates the callers of calls are not modified.
+	cheatCodeCallerModeNone cheatCodeCallerMode = 0
+	// cheatCodeCallerModePrank indicates the caller of the next call is modified by the prank cheat code.
+	cheatCodeCallerModePrank cheatCodeCallerMode = 3
+	// cheatCodeCallerModeRecurrentPrank indicates the callers of all calls are modified by the startPrank cheat code.
+	cheatCodeCallerModeRecurrentPrank cheatCodeCallerMode = 4
+)
+
+// cheatCodePrank describes a modification of the callers of calls made by a call frame, set by the prank cheat codes.
+type cheatCodePrank struct {
+	// sender describes the address to use as msg.sender for the pranked calls.
+	sender common.Address
+
+	// origin describes the address to use as tx.origin for the pranked calls. If nil, tx.origin is not modified.
+	origin *common.Address
+}
+
+// applyActivePrank applies the prank started by a parent call frame to the provided call frame, once it has begun
+// executing, restoring the original msg.sender and tx.origin when it exits.
+func (t *cheatCodeTracer) applyActivePrank(prank *cheatCodePrank, callFrame *cheatCodeTracerCallFrame) {
+	callFrame.onNextOpcodeHooks.Push(func() {
+		// We can cast OpContext to ScopeContext because that is the type passed to OnOpcode.
+		scopeContext := callFrame.vmScope.(*vm.ScopeContext)
+		originalSender := scopeContext.Contract.CallerAddress
+		scopeContext.Contract.CallerAddress = prank.sender
+		callFrame.onFrameExitRestoreHooks.Push(func() {
+			scopeContext.Contract.CallerAddress = originalSender
+		})
+
+		// If an origin was provided, patch it until the call frame exits.
+		if prank.origin != nil {
+			originalOrigin := t.chain.pendingTxContext.Origin
+			t.chain.pendingTxContext.Origin = *prank.origin
+			callFrame.onFrameExitRestoreHooks.Push(func() {
+				t.chain.pendingTxContext.Origin = originalOrigin
+			})
+		}
+	})
+}
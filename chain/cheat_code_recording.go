@@ -0,0 +1,232 @@
+package chain
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"golang.org/x/exp/slices"
+)
+
+// cheatCodeStorageAccesses describes the storage slots of an account which were read and written while storage
+// accesses were recorded by the record cheat code.
+type cheatCodeStorageAccesses struct {
+	// reads describes the slots which were read, in the order they were accessed. Writes are also recorded as reads.
+	reads [][32]byte
+
+	// writes describes the slots which were written, in the order they were accessed.
+	writes [][32]byte
+}
+
+// cheatCodeAccountAccessKind describes the kind of access made to an account while state diffs are recorded. The
+// values match the AccountAccessKind enum used by Foundry's cheat codes.
+type cheatCodeAccountAccessKind uint8
+
+const (
+	// cheatCodeAccountAccessKindCall describes an account which was called.
+	cheatCodeAccountAccessKindCall cheatCodeAccountAccessKind = 0
+	// cheatCodeAccountAccessKindDelegateCall describes an account which was delegate called.
+	cheatCodeAccountAccessKindDelegateCall cheatCodeAccountAccessKind = 1
+	// cheatCodeAccountAccessKindCallCode describes an account which was called with CALLCODE.
+	cheatCodeAccountAccessKindCallCode cheatCodeAccountAccessKind = 2
+	// cheatCodeAccountAccessKindStaticCall describes an account which was static called.
+	cheatCodeAccountAccessKindStaticCall cheatCodeAccountAccessKind = 3
+	// cheatCodeAccountAccessKindCreate describes an account which was created.
+	cheatCodeAccountAccessKindCreate cheatCodeAccountAccessKind = 4
+	// cheatCodeAccountAccessKindResume describes an account whose execution was resumed while state diffs were being
+	// recorded, having been entered before recording began.
+	cheatCodeAccountAccessKindResume cheatCodeAccountAccessKind = 6
+)
+
+// cheatCodeChainInfo describes the chain an account access occurred on, as returned by the stopAndReturnStateDiff
+// cheat code.
+type cheatCodeChainInfo struct {
+	ForkId  *big.Int
+	ChainId *big.Int
+}
+
+// cheatCodeStorageAccess describes a storage slot access recorded while state diffs are recorded, as returned by the
+// stopAndReturnStateDiff cheat code.
+type cheatCodeStorageAccess struct {
+	Account       common.Address
+	Slot          [32]byte
+	IsWrite       bool
+	PreviousValue [32]byte
+	NewValue      [32]byte
+	Reverted      bool
+}
+
+// cheatCodeAccountAccess describes an account access recorded while state diffs are recorded, as returned by the
+// stopAndReturnStateDiff cheat code.
+type cheatCodeAccountAccess struct {
+	ChainInfo       cheatCodeChainInfo
+	Kind            uint8
+	Account         common.Address
+	Accessor        common.Address
+	Initialized     bool
+	OldBalance      *big.Int
+	NewBalance      *big.Int
+	DeployedCode    []byte
+	Value           *big.Int
+	Data            []byte
+	Reverted        bool
+	StorageAccesses []cheatCodeStorageAccess
+	Depth           uint64
+}
+
+// recordStorageAccess records the storage slot accessed by the SLOAD or SSTORE instruction the provided call frame is
+// about to execute, if storage accesses or state diffs are being recorded.
+func (t *cheatCodeTracer) recordStorageAccess(callFrame *cheatCodeTracerCallFrame, op vm.OpCode) {
+	// We can cast OpContext to ScopeContext because that is the type passed to OnOpcode.
+	scopeContext := callFrame.vmScope.(*vm.ScopeContext)
+	account := scopeContext.Address()
+	slot := common.Hash(scopeContext.Stack.Back(0).Bytes32())
+	isWrite := op == vm.SSTORE
+
+	// Record the access for the record cheat code. Writes are recorded as reads too.
+	if t.storageAccesses != nil {
+		accesses, ok := t.storageAccesses[account]
+		if !ok {
+			accesses = &cheatCodeStorageAccesses{}
+			t.storageAccesses[account] = accesses
+		}
+		accesses.reads = append(accesses.reads, slot)
+		if isWrite {
+			accesses.writes = append(accesses.writes, slot)
+		}
+	}
+
+	// Record the access for the state diff cheat codes, under the account access for this call frame.
+	if t.accountAccesses != nil {
+		// If this call frame was entered before recording began, record that its execution was resumed.
+		if callFrame.accountAccess == nil {
+			callFrame.accountAccess = t.newAccountAccess(cheatCodeAccountAccessKindResume, account, scopeContext.Caller(), nil, nil, uint64(t.callDepth))
+			callFrame.accountAccessIndex = len(t.accountAccesses) - 1
+		}
+
+		previousValue := t.evmContext.StateDB.GetState(account, slot)
+		newValue := previousValue
+		if isWrite {
+			newValue = scopeContext.Stack.Back(1).Bytes32()
+		}
+		callFrame.accountAccess.StorageAccesses = append(callFrame.accountAccess.StorageAccesses, cheatCodeStorageAccess{
+			Account:       account,
+			Slot:          slot,
+			IsWrite:       isWrite,
+			PreviousValue: previousValue,
+			NewValue:      newValue,
+			Reverted:      false,
+		})
+	}
+}
+
+// newAccountAccess creates an account access with the provided information and adds it to the recorded state diff.
+// Returns the account access.
+func (t *cheatCodeTracer) newAccountAccess(kind cheatCodeAccountAccessKind, account common.Address, accessor common.Address, value *big.Int, data []byte, depth uint64) *cheatCodeAccountAccess {
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	accountAccess := &cheatCodeAccountAccess{
+		ChainInfo: cheatCodeChainInfo{
+			ForkId:  big.NewInt(0),
+			ChainId: new(big.Int).Set(t.chain.pendingBlockChainConfig.ChainID),
+		},
+		Kind:            uint8(kind),
+		Account:         account,
+		Accessor:        accessor,
+		Initialized:     t.evmContext.StateDB.Exist(account),
+		OldBalance:      t.evmContext.StateDB.GetBalance(account).ToBig(),
+		NewBalance:      t.evmContext.StateDB.GetBalance(account).ToBig(),
+		DeployedCode:    nil,
+		Value:           new(big.Int).Set(value),
+		Data:            slices.Clone(data),
+		Reverted:        false,
+		StorageAccesses: make([]cheatCodeStorageAccess, 0),
+		Depth:           depth,
+	}
+	t.accountAccesses = append(t.accountAccesses, accountAccess)
+	return accountAccess
+}
+
+// recordAccountAccessEnter records an account access for the provided call frame which was just entered, if state
+// diffs are being recorded.
+func (t *cheatCodeTracer) recordAccountAccessEnter(callFrame *cheatCodeTracerCallFrame, typ byte, from common.Address, to common.Address, input []byte, value *big.Int) {
+	// Calls to cheat code contracts are not recorded.
+	if t.accountAccesses == nil || isCheatCodeContractAddress(to) {
+		return
+	}
+
+	// Determine the kind of access.
+	var kind cheatCodeAccountAccessKind
+	switch vm.OpCode(typ) {
+	case vm.DELEGATECALL:
+		kind = cheatCodeAccountAccessKindDelegateCall
+	case vm.CALLCODE:
+		kind = cheatCodeAccountAccessKindCallCode
+	case vm.STATICCALL:
+		kind = cheatCodeAccountAccessKindStaticCall
+	case vm.CREATE, vm.CREATE2:
+		kind = cheatCodeAccountAccessKindCreate
+	default:
+		kind = cheatCodeAccountAccessKindCall
+	}
+	callFrame.accountAccess = t.newAccountAccess(kind, to, from, value, input, uint64(t.callDepth))
+	callFrame.accountAccessIndex = len(t.accountAccesses) - 1
+}
+
+// recordAccountAccessExit updates the account access recorded for the provided call frame which is exiting, if any.
+// If the call frame exited with an error, its access and any accesses made within it are marked as reverted.
+func (t *cheatCodeTracer) recordAccountAccessExit(callFrame *cheatCodeTracerCallFrame, err error) {
+	// If state diffs are not being recorded, or this call frame did not record an access, there is nothing to update.
+	accountAccess := callFrame.accountAccess
+	if t.accountAccesses == nil || accountAccess == nil {
+		return
+	}
+
+	// Record the balance, and any code deployed.
+	accountAccess.NewBalance = t.evmContext.StateDB.GetBalance(accountAccess.Account).ToBig()
+	if accountAccess.Kind == uint8(cheatCodeAccountAccessKindCreate) && err == nil {
+		accountAccess.DeployedCode = slices.Clone(t.evmContext.StateDB.GetCode(accountAccess.Account))
+	}
+
+	// If the call frame reverted, mark every access since it was entered as reverted.
+	if err != nil && callFrame.accountAccessIndex < len(t.accountAccesses) {
+		for _, revertedAccess := range t.accountAccesses[callFrame.accountAccessIndex:] {
+			revertedAccess.Reverted = true
+			for i := range revertedAccess.StorageAccesses {
+				revertedAccess.StorageAccesses[i].Reverted = true
+			}
+		}
+	}
+}
+
+// cheatCodeLog describes a log recorded while logs are recorded, as returned by the getRecordedLogs cheat code.
+type cheatCodeLog struct {
+	Topics  [][32]byte
+	Data    []byte
+	Emitter common.Address
+}
+
+// takeRecordedLogs obtains the logs recorded by each call frame which has not exited since the recordLogs cheat code
+// was called, and clears them so they are not returned again.
+// Returns the recorded logs, in the order they were emitted.
+func (t *cheatCodeTracer) takeRecordedLogs() []cheatCodeLog {
+	// Each call frame's logs were emitted before the call frame above it was entered, so collecting them from the
+	// outermost call frame inwards preserves the order they were emitted in.
+	logs := make([]cheatCodeLog, 0)
+	for _, callFrame := range t.callFrames {
+		for _, log := range callFrame.recordedLogs {
+			topics := make([][32]byte, len(log.Topics))
+			for i, topic := range log.Topics {
+				topics[i] = topic
+			}
+			logs = append(logs, cheatCodeLog{
+				Topics:  topics,
+				Data:    slices.Clone(log.Data),
+				Emitter: log.Address,
+			})
+		}
+		callFrame.recordedLogs = nil
+	}
+	return logs
+}
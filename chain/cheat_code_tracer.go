@@ -1,14 +1,19 @@
 package chain
 
 import (
+	"crypto/ecdsa"
+	"encoding/json"
 	"math/big"
 
 	"github.com/crytic/medusa/chain/types"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	coretypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/eth/tracers"
+	"golang.org/x/exp/slices"
 )
 
 // cheatCodeTracer represents an EVM.Logger which tracks and patches EVM execution state to enable extended
@@ -24,12 +29,61 @@ type cheatCodeTracer struct {
 	// evm refers to the EVM instance last captured.
 	evmContext *tracing.VMContext
 
+	// txSender describes the sender of the transaction currently being executed.
+	txSender common.Address
+
 	// callFrames represents per-call-frame data deployment information being captured by the tracer.
 	callFrames []*cheatCodeTracerCallFrame
 
 	// results stores the tracer output after a transaction has concluded.
 	results *cheatCodeTracerResults
 
+	// mockedCalls describes calls whose results are mocked, set by the mockCall cheat codes. Unlike other tracer
+	// state, mocks persist across transactions until they are cleared or the chain reverts the transaction which
+	// added them.
+	mockedCalls []*cheatCodeMockedCall
+
+	// rememberedKeys describes the private keys remembered by the rememberKey cheat code, by their address. Like
+	// mocked calls, remembered keys persist across transactions until the chain reverts the transaction which
+	// remembered them.
+	rememberedKeys map[common.Address]*ecdsa.PrivateKey
+
+	// storageAccesses describes the storage slots read and written by each account since the record cheat code was
+	// called in the current transaction. It is nil if storage accesses are not being recorded.
+	storageAccesses map[common.Address]*cheatCodeStorageAccesses
+
+	// accountAccesses describes the accounts accessed since the startStateDiffRecording cheat code was called in the
+	// current transaction, in the order they were accessed. It is nil if state diffs are not being recorded.
+	accountAccesses []*cheatCodeAccountAccess
+
+	// serializedJsonObjects describes the JSON objects built by the serialize cheat codes in the current transaction,
+	// by their object key.
+	serializedJsonObjects map[string]map[string]json.RawMessage
+
+	// recordingLogs indicates whether the recordLogs cheat code was called in the current transaction, in which case
+	// the logs emitted by each call frame are recorded until they are returned by the getRecordedLogs cheat code.
+	recordingLogs bool
+
+	// recordingExpectations indicates whether an expectEmit or expectCall cheat code was used in the current
+	// transaction, in which case the logs emitted and calls made by each call frame are recorded to verify them.
+	recordingExpectations bool
+
+	// allowedFFICommands describes the commands which the FFI cheat code may execute, compiled from the cheat code
+	// configuration when the chain is created. If empty, any command may be executed.
+	allowedFFICommands []*cheatCodeFFICommand
+
+	// externalInputsRead indicates whether a cheat code which reads inputs from outside the chain (files, environment
+	// variables or command outputs) was executed on the chain.
+	externalInputsRead bool
+
+	// reportedDenials describes the reasons for which cheat code calls were denied by the cheat code configuration
+	// which have already been logged, so each is only reported once.
+	reportedDenials map[string]struct{}
+
+	// gasMeteringPaused indicates whether the pauseGasMetering cheat code was called in the current transaction without
+	// a subsequent call to resumeGasMetering, in which case call frames do not consume gas.
+	gasMeteringPaused bool
+
 	// nativeTracer is the underlying tracer interface that the cheatcode tracer follows
 	nativeTracer *TestChainTracer
 }
@@ -59,10 +113,91 @@ type cheatCodeTracerCallFrame struct {
 	// The hooks are executed as a stack (to support revert operations).
 	onChainRevertRestoreHooks types.GenericHookFuncs
 
+	// onNextOpcodeHooks describes hooks which are executed the next time this call frame executes an instruction, such
+	// as the first instruction executed after a call it made has exited.
+	// The hooks are executed as a queue.
+	onNextOpcodeHooks types.GenericHookFuncs
+
+	// address describes the address of the account the call frame is executing.
+	address common.Address
+
+	// nextCallPrank describes the caller of the next call made by this call frame, set by the prank cheat code. It is
+	// cleared once the call begins executing.
+	nextCallPrank *cheatCodePrank
+
+	// activePrank describes the caller of all calls made by this call frame, set by the startPrank cheat codes until
+	// stopPrank is called or this call frame exits.
+	activePrank *cheatCodePrank
+
+	// nextCallExpectedRevert describes a revert expected of the next call made by this call frame, set by the
+	// expectRevert cheat codes. Once the call is entered, the expectation is moved to the call frame of that call.
+	nextCallExpectedRevert *cheatCodeExpectedRevert
+
+	// expectedRevert describes a revert expected of this call frame, which is verified when it is exited.
+	expectedRevert *cheatCodeExpectedRevert
+
+	// nextCallReturnMemory describes the memory the next call made by this call frame copies its return data to, as
+	// it was prior to the call. It is only recorded while the next call is expected to revert, so the memory can be
+	// restored if the call satisfies the expectation.
+	nextCallReturnMemory *cheatCodeReturnMemory
+
+	// returnDataCleared indicates whether the return data of the last call made by this call frame was cleared, as the
+	// call satisfied an expected revert. If so, the return data is observed as empty until the next call is made.
+	returnDataCleared bool
+
+	// expectedEmits describes events expected to be emitted before this call frame exits, set by the expectEmit
+	// cheat codes.
+	expectedEmits []*cheatCodeExpectedEmit
+
+	// expectedCalls describes calls expected to be made before this call frame exits, set by the expectCall cheat
+	// codes.
+	expectedCalls []*cheatCodeExpectedCall
+
+	// logs describes the logs emitted by this call frame and any child call frames which exited without error, in the
+	// order they were emitted. This is only recorded while expectations are being recorded.
+	logs []*coretypes.Log
+
+	// calls describes the calls made by this call frame and any child call frames which exited without error, in the
+	// order they were made. This is only recorded while expectations are being recorded.
+	calls []*cheatCodeTracedCall
+
+	// recordedLogs describes the logs emitted by this call frame and any child call frames which exited without error,
+	// in the order they were emitted, which have not yet been returned by the getRecordedLogs cheat code. This is only
+	// recorded while logs are being recorded.
+	recordedLogs []*coretypes.Log
+
+	// assumptionViolated indicates whether this call frame is failing because an assumption made with the assume cheat
+	// code was not met, within it or within a child call frame which failed.
+	assumptionViolated bool
+
+	// milestones describes the names of the milestones reached by this call frame with the cover cheat code, including
+	// those reached by child call frames which exited without error.
+	milestones []string
+
+	// pausedGas describes the gas this call frame is held at while gas metering is paused, or nil if it is not held.
+	pausedGas *uint64
+
+	// activeGasSnapshot describes the gas snapshot started in this call frame by the startSnapshotGas cheat code, or nil
+	// if none is in progress.
+	activeGasSnapshot *cheatCodeActiveGasSnapshot
+
+	// gasSnapshots describes the gas snapshots taken by this call frame and any child call frames which exited without
+	// error, in the order they were stopped.
+	gasSnapshots []CheatCodeGasSnapshot
+
+	// accountAccess describes the account access recorded for this call frame while state diffs are recorded, or nil
+	// if none was recorded.
+	accountAccess *cheatCodeAccountAccess
+
+	// accountAccessIndex describes the index of accountAccess in the tracer's recorded account accesses.
+	accountAccessIndex int
+
 	// vmPc describes the current call frame's program counter.
 	vmPc uint64
 	// vmOp describes the current call frame's last instruction executed.
 	vmOp vm.OpCode
+	// vmGas describes the current call frame's gas remaining prior to its last instruction executed.
+	vmGas uint64
 	// vmScope describes the current call frame's scope context.
 	vmScope tracing.OpContext
 	// vmReturnData describes the current call frame's return data (set on exit).
@@ -75,6 +210,163 @@ type cheatCodeTracerCallFrame struct {
 type cheatCodeTracerResults struct {
 	// onChainRevertHooks describes hooks which are to be executed when the chain reverts.
 	onChainRevertHooks types.GenericHookFuncs
+
+	// expectationFailures describes messages for each expectation set by a cheat code which was not met during the
+	// transaction (e.g. a call which was expected to revert did not).
+	expectationFailures []string
+
+	// assumptionViolated indicates whether the transaction failed because an assumption made with the assume cheat
+	// code was not met.
+	assumptionViolated bool
+
+	// dictionaryValues describes the values added to the fuzzer's value dictionary by the addToDictionary cheat codes
+	// during the transaction.
+	dictionaryValues cheatCodeDictionaryValues
+
+	// milestones describes the names of the milestones reached with the cover cheat code during the transaction, in the
+	// order they were reached. Milestones reached by call frames which reverted are not included.
+	milestones []string
+
+	// gasSnapshots describes the gas snapshots taken with the startSnapshotGas and stopSnapshotGas cheat codes during
+	// the transaction, in the order they were stopped. Snapshots taken by call frames which reverted are not included.
+	gasSnapshots []CheatCodeGasSnapshot
+}
+
+// cheatCodeExpectationFailuresKey describes the key to use when storing cheat code expectation failures in call
+// message results, or when querying them.
+const cheatCodeExpectationFailuresKey = "CheatCodeExpectationFailures"
+
+// GetCheatCodeExpectationFailures obtains messages describing each expectation set by a cheat code (e.g. expectRevert)
+// which was not met during the execution of a message, from its results.
+// Returns the messages, or nil if every expectation was met.
+func GetCheatCodeExpectationFailures(messageResults *types.MessageResults) []string {
+	if genericResult, ok := messageResults.AdditionalResults[cheatCodeExpectationFailuresKey]; ok {
+		if castedResult, ok := genericResult.([]string); ok {
+			return castedResult
+		}
+	}
+	return nil
+}
+
+// cheatCodeAssumptionViolatedKey describes the key to use when storing whether a message failed because an assumption
+// made with the assume cheat code was not met in call message results, or when querying it.
+const cheatCodeAssumptionViolatedKey = "CheatCodeAssumptionViolated"
+
+// GetCheatCodeAssumptionViolated indicates whether a message failed because an assumption made with the assume cheat
+// code was not met, from its results. Such messages describe inputs which should be discarded.
+// Returns a boolean indicating whether an assumption was violated.
+func GetCheatCodeAssumptionViolated(messageResults *types.MessageResults) bool {
+	if genericResult, ok := messageResults.AdditionalResults[cheatCodeAssumptionViolatedKey]; ok {
+		if castedResult, ok := genericResult.(bool); ok {
+			return castedResult
+		}
+	}
+	return false
+}
+
+// cheatCodeDictionaryValuesKey describes the key to use when storing values added to the fuzzer's value dictionary by
+// cheat codes in call message results, or when querying them.
+const cheatCodeDictionaryValuesKey = "CheatCodeDictionaryValues"
+
+// GetCheatCodeDictionaryValues obtains the values added to the fuzzer's value dictionary by the addToDictionary cheat
+// codes during a message, from its results. Each value is a *big.Int, common.Address, []byte or string.
+// Returns the values, or nil if none were added.
+func GetCheatCodeDictionaryValues(messageResults *types.MessageResults) []any {
+	if genericResult, ok := messageResults.AdditionalResults[cheatCodeDictionaryValuesKey]; ok {
+		if castedResult, ok := genericResult.(cheatCodeDictionaryValues); ok {
+			return castedResult
+		}
+	}
+	return nil
+}
+
+// cheatCodeDictionaryValues describes the values added to the fuzzer's value dictionary by the addToDictionary cheat
+// codes. Each value is a *big.Int, common.Address, []byte or string.
+type cheatCodeDictionaryValues []any
+
+// exportedCheatCodeDictionaryValue describes a value in cheatCodeDictionaryValues in a serializable form, where only
+// the field matching the type of the value is set.
+type exportedCheatCodeDictionaryValue struct {
+	Integer *big.Int        `json:"integer,omitempty"`
+	Address *common.Address `json:"address,omitempty"`
+	Bytes   hexutil.Bytes   `json:"bytes,omitempty"`
+	String  *string         `json:"string,omitempty"`
+}
+
+// MarshalJSON provides JSON marshalling for cheatCodeDictionaryValues, so they can be stored in a TestChainExport.
+// Returns the JSON marshalled data, or an error if one occurs.
+func (v cheatCodeDictionaryValues) MarshalJSON() ([]byte, error) {
+	exported := make([]exportedCheatCodeDictionaryValue, 0, len(v))
+	for _, value := range v {
+		switch value := value.(type) {
+		case *big.Int:
+			exported = append(exported, exportedCheatCodeDictionaryValue{Integer: value})
+		case common.Address:
+			exported = append(exported, exportedCheatCodeDictionaryValue{Address: &value})
+		case []byte:
+			exported = append(exported, exportedCheatCodeDictionaryValue{Bytes: value})
+		case string:
+			exported = append(exported, exportedCheatCodeDictionaryValue{String: &value})
+		}
+	}
+	return json.Marshal(exported)
+}
+
+// UnmarshalJSON provides JSON unmarshalling for cheatCodeDictionaryValues, so they can be restored from a
+// TestChainExport.
+// Returns an error if one occurs.
+func (v *cheatCodeDictionaryValues) UnmarshalJSON(b []byte) error {
+	var exported []exportedCheatCodeDictionaryValue
+	err := json.Unmarshal(b, &exported)
+	if err != nil {
+		return err
+	}
+
+	*v = make(cheatCodeDictionaryValues, 0, len(exported))
+	for _, value := range exported {
+		if value.Integer != nil {
+			*v = append(*v, value.Integer)
+		} else if value.Address != nil {
+			*v = append(*v, *value.Address)
+		} else if value.Bytes != nil {
+			*v = append(*v, []byte(value.Bytes))
+		} else if value.String != nil {
+			*v = append(*v, *value.String)
+		}
+	}
+	return nil
+}
+
+// cheatCodeMilestonesKey describes the key to use when storing milestones reached with the cover cheat code in call
+// message results, or when querying them.
+const cheatCodeMilestonesKey = "CheatCodeMilestones"
+
+// GetCheatCodeMilestones obtains the names of the milestones reached with the cover cheat code during a message, from
+// its results, in the order they were reached.
+// Returns the milestone names, or nil if none were reached.
+func GetCheatCodeMilestones(messageResults *types.MessageResults) []string {
+	if genericResult, ok := messageResults.AdditionalResults[cheatCodeMilestonesKey]; ok {
+		if castedResult, ok := genericResult.([]string); ok {
+			return castedResult
+		}
+	}
+	return nil
+}
+
+// cheatCodeGasSnapshotsKey describes the key to use when storing gas snapshots taken with cheat codes in call message
+// results, or when querying them.
+const cheatCodeGasSnapshotsKey = "CheatCodeGasSnapshots"
+
+// GetCheatCodeGasSnapshots obtains the gas snapshots taken with the startSnapshotGas and stopSnapshotGas cheat codes
+// during a message, from its results, in the order they were stopped.
+// Returns the gas snapshots, or nil if none were taken.
+func GetCheatCodeGasSnapshots(messageResults *types.MessageResults) []CheatCodeGasSnapshot {
+	if genericResult, ok := messageResults.AdditionalResults[cheatCodeGasSnapshotsKey]; ok {
+		if castedResult, ok := genericResult.([]CheatCodeGasSnapshot); ok {
+			return castedResult
+		}
+	}
+	return nil
 }
 
 // newCheatCodeTracer creates a cheatCodeTracer and returns it.
@@ -128,10 +420,22 @@ func (t *cheatCodeTracer) OnTxStart(vm *tracing.VMContext, tx *coretypes.Transac
 	t.callDepth = 0
 	t.callFrames = make([]*cheatCodeTracerCallFrame, 0)
 	t.results = &cheatCodeTracerResults{
-		onChainRevertHooks: nil,
+		onChainRevertHooks:  nil,
+		expectationFailures: nil,
+		assumptionViolated:  false,
+		dictionaryValues:    nil,
+		milestones:          nil,
+		gasSnapshots:        nil,
 	}
-	// Store our evm reference
+	t.recordingExpectations = false
+	t.gasMeteringPaused = false
+	t.recordingLogs = false
+	t.serializedJsonObjects = nil
+	t.storageAccesses = nil
+	t.accountAccesses = nil
+	// Store our evm reference and transaction sender
 	t.evmContext = vm
+	t.txSender = from
 }
 
 // OnTxEnd is called upon the end of transaction execution, as defined by tracers.Tracer
@@ -146,7 +450,9 @@ func (t *cheatCodeTracer) OnEnter(depth int, typ byte, from common.Address, to c
 	var callFrameData *cheatCodeTracerCallFrame
 	if isTopLevelFrame {
 		// Create our call frame struct to track data for this initial entry call frame.
-		callFrameData = &cheatCodeTracerCallFrame{}
+		callFrameData = &cheatCodeTracerCallFrame{
+			address: to,
+		}
 	} else {
 		// We haven't updated our call depth yet, so obtain the "previous" call frame (current for now)
 		previousCallFrame := t.CurrentCallFrame()
@@ -155,9 +461,32 @@ func (t *cheatCodeTracer) OnEnter(depth int, typ byte, from common.Address, to c
 		// We forward our "next frame hooks" to this frame, then clear them from the previous frame.
 		callFrameData = &cheatCodeTracerCallFrame{
 			onFrameExitRestoreHooks: previousCallFrame.onNextFrameExitRestoreHooks,
+			address:                 to,
 		}
 		previousCallFrame.onNextFrameExitRestoreHooks = nil
 
+		// If the previous call frame expects its next call to revert, we move the expectation to this call frame.
+		// Calls to cheat code contracts and contract creations do not satisfy expectations.
+		isCreation := vm.OpCode(typ) == vm.CREATE || vm.OpCode(typ) == vm.CREATE2
+		if previousCallFrame.nextCallExpectedRevert != nil && !isCreation && !isCheatCodeContractAddress(to) {
+			callFrameData.expectedRevert = previousCallFrame.nextCallExpectedRevert
+			previousCallFrame.nextCallExpectedRevert = nil
+		}
+
+		// If the previous call frame started a prank, apply it to this call frame.
+		if previousCallFrame.activePrank != nil && !isCheatCodeContractAddress(to) {
+			t.applyActivePrank(previousCallFrame.activePrank, callFrameData)
+		}
+
+		// If we are recording expectations, record the call in the previous call frame.
+		if t.recordingExpectations && !isCreation && !isCheatCodeContractAddress(to) {
+			previousCallFrame.calls = append(previousCallFrame.calls, &cheatCodeTracedCall{
+				to:    to,
+				input: slices.Clone(input),
+				value: value,
+			})
+		}
+
 		// Increase our call depth now that we're entering a new call frame.
 		t.callDepth++
 	}
@@ -165,6 +494,9 @@ func (t *cheatCodeTracer) OnEnter(depth int, typ byte, from common.Address, to c
 	// Append our new call frame
 	t.callFrames = append(t.callFrames, callFrameData)
 
+	// If we are recording state diffs, record the access this call frame makes to its account.
+	t.recordAccountAccessEnter(callFrameData, typ, from, to, input, value)
+
 	// Note: We do not execute events for "next frame enter" here, as we do not yet have scope information.
 	// Those events are executed when the first EVM instruction is executed in the new scope.
 }
@@ -175,6 +507,9 @@ func (t *cheatCodeTracer) OnExit(depth int, output []byte, gasUsed uint64, err e
 	exitingCallFrame := t.callFrames[t.callDepth]
 	exitingCallFrame.onFrameExitRestoreHooks.Execute(false, true)
 
+	// If we are recording state diffs, update the access this call frame made with its result.
+	t.recordAccountAccessExit(exitingCallFrame, err)
+
 	var parentCallFrame *cheatCodeTracerCallFrame
 	if depth == 0 {
 		// If this is the top-level call frame, execute all of its exit hooks
@@ -182,18 +517,55 @@ func (t *cheatCodeTracer) OnExit(depth int, output []byte, gasUsed uint64, err e
 	} else {
 		// If not, retrieve the parent call frame
 		parentCallFrame = t.callFrames[t.callDepth-1]
+
+		// If this call exited without error, verify the events and calls it expected, failing it if they were not met.
+		if err == nil {
+			t.checkExpectedEmitsAndCalls(exitingCallFrame, parentCallFrame)
+		}
+
+		// If this call was expected to revert, verify it did, and report it to the parent call frame accordingly.
+		if exitingCallFrame.expectedRevert != nil {
+			t.checkExpectedRevert(exitingCallFrame.expectedRevert, parentCallFrame, output, err)
+		}
+	}
+
+	// If this call frame expected its next call to revert but never made one, the expectation was not met.
+	if exitingCallFrame.nextCallExpectedRevert != nil {
+		t.addExpectationFailure("expectRevert: no call was made after a revert was expected")
+	}
+
+	// If this is the top-level call frame, verify the events and calls it expected.
+	if depth == 0 && err == nil {
+		t.checkExpectedEmitsAndCalls(exitingCallFrame, nil)
 	}
 
 	// We're exiting the current frame, so remove our frame data.
 	t.callFrames = t.callFrames[:t.callDepth]
 
+	// If this call frame failed because an assumption was not met, report it to the parent call frame, or in the tracer
+	// results if this is the top-level call frame.
+	if err != nil && exitingCallFrame.assumptionViolated {
+		if depth == 0 {
+			t.results.assumptionViolated = true
+		} else {
+			parentCallFrame.assumptionViolated = true
+		}
+	}
+
 	// If we didn't encounter an error in this call frame, we push our upward propagating restore events up one frame.
 	if err == nil && depth == 0 {
 		// Since this is the top call frame, we add the revert events to the results of the tracer and return early
 		t.results.onChainRevertHooks = append(t.results.onChainRevertHooks, exitingCallFrame.onChainRevertRestoreHooks...)
+		t.results.milestones = exitingCallFrame.milestones
+		t.results.gasSnapshots = exitingCallFrame.gasSnapshots
 		return
 	} else if err == nil {
 		// Propagate hooks up to the parent call frame
+		parentCallFrame.logs = append(parentCallFrame.logs, exitingCallFrame.logs...)
+		parentCallFrame.recordedLogs = append(parentCallFrame.recordedLogs, exitingCallFrame.recordedLogs...)
+		parentCallFrame.calls = append(parentCallFrame.calls, exitingCallFrame.calls...)
+		parentCallFrame.milestones = append(parentCallFrame.milestones, exitingCallFrame.milestones...)
+		parentCallFrame.gasSnapshots = append(parentCallFrame.gasSnapshots, exitingCallFrame.gasSnapshots...)
 		parentCallFrame.onTopFrameExitRestoreHooks = append(parentCallFrame.onTopFrameExitRestoreHooks, exitingCallFrame.onTopFrameExitRestoreHooks...)
 		parentCallFrame.onChainRevertRestoreHooks = append(parentCallFrame.onChainRevertRestoreHooks, exitingCallFrame.onChainRevertRestoreHooks...)
 	} else {
@@ -211,10 +583,59 @@ func (t *cheatCodeTracer) OnOpcode(pc uint64, op byte, gas, cost uint64, scope t
 	currentCallFrame := t.CurrentCallFrame()
 	currentCallFrame.vmPc = pc
 	currentCallFrame.vmOp = vm.OpCode(op)
+	currentCallFrame.vmGas = gas
 	currentCallFrame.vmScope = scope
 	currentCallFrame.vmReturnData = rData
 	currentCallFrame.vmErr = err
 
+	// If gas metering is paused, or was paused for this call frame, apply it before anything observes our gas.
+	if err == nil && (t.gasMeteringPaused || currentCallFrame.pausedGas != nil) {
+		t.meterGas(currentCallFrame, gas, cost)
+	}
+
+	// Execute any hooks awaiting the next instruction in this call frame.
+	currentCallFrame.onNextOpcodeHooks.Execute(true, true)
+
+	// If storage is about to be accessed, record the access if we are recording storage accesses or state diffs.
+	opCode := vm.OpCode(op)
+	if (t.storageAccesses != nil || t.accountAccesses != nil) && err == nil && (opCode == vm.SLOAD || opCode == vm.SSTORE) {
+		t.recordStorageAccess(currentCallFrame, opCode)
+	}
+
+	// If the return data of the last call was cleared, or the next call is expected to revert, update the return data
+	// observed by this call frame.
+	if err == nil && (currentCallFrame.returnDataCleared || currentCallFrame.nextCallExpectedRevert != nil) {
+		t.applyClearedReturnData(currentCallFrame, opCode)
+	}
+
+	// If a call is about to be made, mock its result if a mock applies to it.
+	if len(t.mockedCalls) > 0 && err == nil && (opCode == vm.CALL || opCode == vm.CALLCODE || opCode == vm.STATICCALL || opCode == vm.DELEGATECALL) {
+		t.applyMockedCall(currentCallFrame, opCode)
+	}
+
+	// If we are recording expectations or logs and a log is being emitted, record it once it has been committed, prior
+	// to the next instruction.
+	if (t.recordingExpectations || t.recordingLogs) && vm.OpCode(op) >= vm.LOG0 && vm.OpCode(op) <= vm.LOG4 {
+		currentCallFrame.onNextOpcodeHooks.Push(func() {
+			// Logs are not returned in the order they were emitted, so we find the last one emitted by its index.
+			var lastLog *coretypes.Log
+			for _, log := range t.evmContext.StateDB.(*state.StateDB).Logs() {
+				if lastLog == nil || log.Index > lastLog.Index {
+					lastLog = log
+				}
+			}
+			if lastLog == nil {
+				return
+			}
+			if t.recordingExpectations {
+				t.recordLog(currentCallFrame, lastLog)
+			}
+			if t.recordingLogs {
+				currentCallFrame.recordedLogs = append(currentCallFrame.recordedLogs, lastLog)
+			}
+		})
+	}
+
 	// We execute our entered next frame hooks here (from our previous call frame), as we now have scope information.
 	if t.callDepth > 0 {
 		t.callFrames[t.callDepth-1].onNextFrameEnterHooks.Execute(true, true)
@@ -227,4 +648,38 @@ func (t *cheatCodeTracer) OnOpcode(pc uint64, op byte, gas, cost uint64, scope t
 func (t *cheatCodeTracer) CaptureTxEndSetAdditionalResults(results *types.MessageResults) {
 	// Add our revert operations we collected for this transaction.
 	results.OnRevertHookFuncs = append(results.OnRevertHookFuncs, t.results.onChainRevertHooks...)
+
+	// Store any cheat code expectations which were not met.
+	if len(t.results.expectationFailures) > 0 {
+		results.AdditionalResults[cheatCodeExpectationFailuresKey] = t.results.expectationFailures
+	}
+
+	// Store whether the transaction failed because an assumption was not met.
+	if t.results.assumptionViolated {
+		results.AdditionalResults[cheatCodeAssumptionViolatedKey] = true
+	}
+
+	// Store any values added to the fuzzer's value dictionary.
+	if len(t.results.dictionaryValues) > 0 {
+		results.AdditionalResults[cheatCodeDictionaryValuesKey] = t.results.dictionaryValues
+	}
+
+	// Store any milestones reached.
+	if len(t.results.milestones) > 0 {
+		results.AdditionalResults[cheatCodeMilestonesKey] = t.results.milestones
+	}
+
+	// Store any gas snapshots taken.
+	if len(t.results.gasSnapshots) > 0 {
+		results.AdditionalResults[cheatCodeGasSnapshotsKey] = t.results.gasSnapshots
+	}
+
+	// Store our state which persists across transactions, so it can be restored if the block is restored onto another
+	// chain.
+	if len(t.mockedCalls) > 0 || len(t.rememberedKeys) > 0 {
+		results.AdditionalResults[cheatCodePersistentStateKey] = &cheatCodePersistentState{
+			mockedCalls:    t.mockedCalls,
+			rememberedKeys: t.rememberedKeys,
+		}
+	}
 }
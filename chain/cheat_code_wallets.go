@@ -0,0 +1,171 @@
+package chain
+
+import (
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/tyler-smith/go-bip39"
+	"golang.org/x/exp/maps"
+)
+
+// cheatCodeDefaultDerivationPath describes the derivation path used by the deriveKey cheat codes when none is provided.
+// The index of the key to derive is appended to it.
+const cheatCodeDefaultDerivationPath = "m/44'/60'/0'/0/"
+
+// cheatCodeWallet describes a wallet returned by the createWallet cheat codes.
+type cheatCodeWallet struct {
+	// Addr describes the address of the wallet.
+	Addr common.Address
+
+	// PublicKeyX describes the x coordinate of the wallet's public key.
+	PublicKeyX *big.Int
+
+	// PublicKeyY describes the y coordinate of the wallet's public key.
+	PublicKeyY *big.Int
+
+	// PrivateKey describes the wallet's private key.
+	PrivateKey *big.Int
+}
+
+// newCheatCodeWallet creates a cheatCodeWallet describing the provided private key.
+func newCheatCodeWallet(privateKey *ecdsa.PrivateKey) cheatCodeWallet {
+	return cheatCodeWallet{
+		Addr:       crypto.PubkeyToAddress(privateKey.PublicKey),
+		PublicKeyX: new(big.Int).Set(privateKey.PublicKey.X),
+		PublicKeyY: new(big.Int).Set(privateKey.PublicKey.Y),
+		PrivateKey: new(big.Int).Set(privateKey.D),
+	}
+}
+
+// deriveMnemonicKey derives the private key at the provided BIP-32 derivation path from the seed of the provided
+// BIP-39 mnemonic. The words of the mnemonic are not validated against a wordlist.
+// Returns the derived private key, or an error if one occurs.
+func deriveMnemonicKey(mnemonic string, derivationPath string) (*ecdsa.PrivateKey, error) {
+	path, err := accounts.ParseDerivationPath(derivationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive the seed from our mnemonic, as described by BIP-39, with an empty passphrase.
+	seed := bip39.NewSeed(strings.Join(strings.Fields(mnemonic), " "), "")
+
+	// Derive the master key from the seed, then each child key in our path, as described by BIP-32.
+	curveOrder := crypto.S256().Params().N
+	key, chainCode := hmacSha512([]byte("Bitcoin seed"), seed)
+	privateKey := new(big.Int).SetBytes(key)
+	if privateKey.Sign() == 0 || privateKey.Cmp(curveOrder) >= 0 {
+		return nil, errors.New("mnemonic derives an invalid master key")
+	}
+	for _, index := range path {
+		// Hardened keys are derived from the parent private key, while other keys are derived from the parent public
+		// key.
+		data := make([]byte, 0, 37)
+		if index >= 0x80000000 {
+			data = append(data, 0)
+			data = append(data, math.PaddedBigBytes(privateKey, 32)...)
+		} else {
+			parentKey, err := crypto.ToECDSA(math.PaddedBigBytes(privateKey, 32))
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, crypto.CompressPubkey(&parentKey.PublicKey)...)
+		}
+		data = binary.BigEndian.AppendUint32(data, index)
+
+		key, chainCode = hmacSha512(chainCode, data)
+		tweak := new(big.Int).SetBytes(key)
+		if tweak.Cmp(curveOrder) >= 0 {
+			return nil, fmt.Errorf("derivation path %v derives an invalid key", derivationPath)
+		}
+		privateKey = tweak.Add(tweak, privateKey).Mod(tweak, curveOrder)
+		if privateKey.Sign() == 0 {
+			return nil, fmt.Errorf("derivation path %v derives an invalid key", derivationPath)
+		}
+	}
+	return crypto.ToECDSA(math.PaddedBigBytes(privateKey, 32))
+}
+
+// hmacSha512 computes the HMAC-SHA512 of the provided data with the provided key.
+// Returns the left and right halves of the result, which describe a key and chain code during BIP-32 derivation.
+func hmacSha512(key []byte, data []byte) ([]byte, []byte) {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	sum := mac.Sum(nil)
+	return sum[:32], sum[32:]
+}
+
+// signDigest signs the provided digest with the provided private key.
+// Returns the recovery id of the signature (offset by 27, so it can be used with ecrecover), its r and s values, or an
+// error if one occurs.
+func signDigest(privateKey *ecdsa.PrivateKey, digest [32]byte) (uint8, [32]byte, [32]byte, error) {
+	var r, s [32]byte
+	sig, err := crypto.Sign(digest[:], privateKey)
+	if err != nil {
+		return 0, r, s, err
+	}
+	copy(r[:], sig[:32])
+	copy(s[:], sig[32:64])
+	return sig[64] + 27, r, s, nil
+}
+
+// signDigestCompact signs the provided digest with the provided private key, producing a compact signature as
+// described by EIP-2098.
+// Returns the r and vs values of the signature, or an error if one occurs.
+func signDigestCompact(privateKey *ecdsa.PrivateKey, digest [32]byte) ([32]byte, [32]byte, error) {
+	v, r, s, err := signDigest(privateKey, digest)
+	if err != nil {
+		return r, s, err
+	}
+
+	// The recovery id is encoded in the highest bit of s, which is always unset as signatures are canonical.
+	vs := s
+	if v == 28 {
+		vs[0] |= 0x80
+	}
+	return r, vs, nil
+}
+
+// hashTypedData computes the EIP-712 digest of the provided typed data, described in the JSON format accepted by
+// eth_signTypedData_v4.
+// Returns the digest, or an error if one occurs.
+func hashTypedData(jsonData string) ([32]byte, error) {
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal([]byte(jsonData), &typedData); err != nil {
+		return [32]byte{}, err
+	}
+	digest, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return [32]byte(digest), nil
+}
+
+// rememberKey remembers the provided private key, so digests can be signed with it by its address. The key is forgotten
+// if the provided call frame reverts, or the chain reverts the transaction which remembered it.
+// Returns the address of the key.
+func (t *cheatCodeTracer) rememberKey(callFrame *cheatCodeTracerCallFrame, privateKey *ecdsa.PrivateKey) common.Address {
+	// Create a new map of keys rather than modifying the existing one, so it can be restored on revert.
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	original := t.rememberedKeys
+	t.rememberedKeys = maps.Clone(original)
+	if t.rememberedKeys == nil {
+		t.rememberedKeys = make(map[common.Address]*ecdsa.PrivateKey)
+	}
+	t.rememberedKeys[address] = privateKey
+	callFrame.onChainRevertRestoreHooks.Push(func() {
+		t.rememberedKeys = original
+	})
+	return address
+}
@@ -1,12 +1,42 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"math/big"
+	"regexp"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"golang.org/x/exp/slices"
 )
 
+// SupportedEVMVersions describes the EVM versions (hard forks) which a chain can be configured to simulate, ordered from
+// oldest to newest.
+var SupportedEVMVersions = []string{"london", "paris", "shanghai", "cancun", "prague"}
+
+// compilerEVMVersions describes the EVM versions (hard forks) which contracts can be compiled for, using the naming
+// convention of the solc `--evm-version` flag, ordered from oldest to newest.
+var compilerEVMVersions = []string{
+	"homestead", "tangerineWhistle", "spuriousDragon", "byzantium", "constantinople", "petersburg", "istanbul",
+	"berlin", "london", "paris", "shanghai", "cancun", "prague", "osaka",
+}
+
+// DefaultEVMVersion describes the EVM version (hard fork) which is simulated when none is provided.
+const DefaultEVMVersion = "cancun"
+
 // TestChainConfig represents the chain configuration.
 type TestChainConfig struct {
+	// EVMVersion describes the EVM version (hard fork) the chain should simulate, using the naming convention of the
+	// solc `--evm-version` flag (e.g. "paris", "shanghai", "cancun"). All forks up to and including it are activated
+	// at genesis. If empty, DefaultEVMVersion is used.
+	EVMVersion string `json:"evmVersion"`
+
+	// ChainID describes the chain ID reported by the chain (e.g. by the CHAINID opcode). If zero, a chain ID of 1 is
+	// used.
+	ChainID uint64 `json:"chainId"`
+
 	// CodeSizeCheckDisabled indicates whether code size checks should be disabled in the EVM. This allows for code
 	// size to be disabled without disabling the entire EIP it was introduced.
 	CodeSizeCheckDisabled bool `json:"codeSizeCheckDisabled"`
@@ -19,6 +49,14 @@ type TestChainConfig struct {
 
 	// ContractAddressOverrides describes contracts that are going to be deployed at deterministic addresses
 	ContractAddressOverrides map[common.Hash]common.Address `json:"contractAddressOverrides,omitempty"`
+
+	// ForkConfig indicates the configuration for forking a remote chain's state.
+	ForkConfig ForkConfig `json:"forkConfig"`
+
+	// Precompiles describes additional pre-compiled contracts which should be installed on the chain, so contracts
+	// which depend on precompiles or system contracts that do not exist on mainnet (e.g. those of L2 chains) can be
+	// tested.
+	Precompiles []PrecompileConfig `json:"precompiles"`
 }
 
 // CheatCodeConfig describes any configuration options related to the use of vm extensions (a.k.a. cheat codes)
@@ -29,8 +67,97 @@ type CheatCodeConfig struct {
 	// EnableFFI describes whether the FFI cheat code should be enabled. Enablement allows for arbitrary code execution
 	// on the tester's machine
 	EnableFFI bool `json:"enableFFI"`
+
+	// ReadablePaths describes the files and directories which file system cheat codes (e.g. readFile) can read from.
+	// Relative paths are resolved against the project root. Any path which is writable is also readable.
+	ReadablePaths []string `json:"readablePaths"`
+
+	// WritablePaths describes the files and directories which file system cheat codes (e.g. writeFile) can write to.
+	// Relative paths are resolved against the project root.
+	WritablePaths []string `json:"writablePaths"`
+
+	// AllowedCallers describes the addresses of the accounts which may call cheat codes. Calls from any other account
+	// are reverted. If empty, any account may call cheat codes.
+	AllowedCallers []common.Address `json:"allowedCallers"`
+
+	// AllowedFFICommands describes the commands which the FFI cheat code may execute when it is enabled. Any other
+	// command is reverted without being executed. If empty, any command may be executed.
+	AllowedFFICommands []FFICommandConfig `json:"allowedFFICommands"`
+}
+
+// FFICommandConfig describes a command which the FFI cheat code may execute.
+type FFICommandConfig struct {
+	// Executable describes the executable which may be run. It must exactly match the first element provided to the
+	// FFI cheat code.
+	Executable string `json:"executable"`
+
+	// Arguments describes regular expressions which the arguments provided to the executable must match, in order. A
+	// command must provide exactly one argument per expression, and each expression must match its entire argument.
+	Arguments []string `json:"arguments"`
+}
+
+// Validate validates that the CheatCodeConfig meets certain requirements.
+// Returns an error if one occurs.
+func (c *CheatCodeConfig) Validate() error {
+	for _, command := range c.AllowedFFICommands {
+		if command.Executable == "" {
+			return errors.New("cheat code configuration must specify an executable for each allowed FFI command")
+		}
+		for _, pattern := range command.Arguments {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("cheat code configuration must specify valid argument patterns for allowed FFI command %s: %v", command.Executable, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ForkConfig describes any configuration options related to forking the state of a remote chain. When fork mode is
+// enabled, any accounts, code, or storage which are not present in the local chain state are lazily fetched from the
+// provided JSON-RPC endpoint at a pinned block number.
+type ForkConfig struct {
+	// ForkModeEnabled indicates whether the chain's state should be backed by the state of a remote chain.
+	ForkModeEnabled bool `json:"forkModeEnabled"`
+
+	// RpcUrl describes the JSON-RPC endpoint which remote state should be fetched from.
+	RpcUrl string `json:"rpcUrl"`
+
+	// RpcBlock describes the block number of the remote chain to fork from. If zero, the latest block number reported
+	// by the endpoint at startup is used, so all chains fork from the same block.
+	RpcBlock uint64 `json:"rpcBlock"`
+
+	// CacheDirectory describes the directory in which fetched remote state should be cached, so it can be reused
+	// across runs. If empty, remote state is only cached in memory.
+	CacheDirectory string `json:"cacheDirectory"`
 }
 
+// PrecompileConfig describes a pre-compiled contract to install on the chain. A precompile is either implemented by
+// one of the built-in implementations shipped with medusa, or by a compiled contract whose runtime bytecode and storage
+// are placed at the precompile address at genesis, once it has been constructed. Exactly one of Builtin or ContractName
+// should be provided.
+type PrecompileConfig struct {
+	// Address describes the address the precompile should be installed at. If empty, built-in precompiles are
+	// installed at their default address.
+	Address common.Address `json:"address"`
+
+	// Builtin describes the name of the built-in precompile implementation to install (e.g. BuiltinPrecompileP256Verify).
+	Builtin string `json:"builtin,omitempty"`
+
+	// ContractName describes the name of a compiled contract whose runtime bytecode should be executed when the
+	// precompile address is called.
+	ContractName string `json:"contractName,omitempty"`
+}
+
+const (
+	// BuiltinPrecompileP256Verify describes the built-in precompile which verifies secp256r1 (P-256) signatures, as
+	// defined by RIP-7212.
+	BuiltinPrecompileP256Verify = "p256Verify"
+
+	// BuiltinPrecompileArbSys describes the built-in precompile which stubs the ArbSys system contract found on
+	// Arbitrum chains.
+	BuiltinPrecompileArbSys = "arbSys"
+)
+
 // GetVMConfigExtensions derives a vm.ConfigExtensions from the provided TestChainConfig.
 func (t *TestChainConfig) GetVMConfigExtensions() *vm.ConfigExtensions {
 	// Create a copy of the contract address overrides that can be ephemerally updated by medusa-geth
@@ -46,3 +173,77 @@ func (t *TestChainConfig) GetVMConfigExtensions() *vm.ConfigExtensions {
 		ContractAddressOverrides: contractAddressOverrides,
 	}
 }
+
+// SupportsCompilerEVMVersion indicates whether contracts compiled for the provided EVM version (as declared in their
+// compiler metadata, e.g. "shanghai") can be executed by a chain simulating the configured EVM version. Contracts
+// compiled for an EVM version newer than the configured one may use opcodes the chain does not support.
+// Returns a boolean indicating whether the EVM version is supported. Unknown EVM versions are not supported.
+func (t *TestChainConfig) SupportsCompilerEVMVersion(compilerEVMVersion string) bool {
+	evmVersion := t.EVMVersion
+	if evmVersion == "" {
+		evmVersion = DefaultEVMVersion
+	}
+	compilerVersionIndex := slices.Index(compilerEVMVersions, compilerEVMVersion)
+	return compilerVersionIndex != -1 && compilerVersionIndex <= slices.Index(compilerEVMVersions, evmVersion)
+}
+
+// GetChainConfig derives a params.ChainConfig from the provided TestChainConfig, activating all forks up to and
+// including the configured EVM version at genesis. A new instance is returned on each call, so it is not shared
+// across chains.
+// Returns the chain config, or an error if the EVM version is not supported.
+func (t *TestChainConfig) GetChainConfig() (*params.ChainConfig, error) {
+	// Determine the index of our EVM version in our ordered list of versions.
+	evmVersion := t.EVMVersion
+	if evmVersion == "" {
+		evmVersion = DefaultEVMVersion
+	}
+	versionIndex := slices.Index(SupportedEVMVersions, evmVersion)
+	if versionIndex == -1 {
+		return nil, fmt.Errorf("unsupported EVM version '%s', expected one of %v", evmVersion, SupportedEVMVersions)
+	}
+	isActive := func(version string) bool {
+		return versionIndex >= slices.Index(SupportedEVMVersions, version)
+	}
+
+	// Determine our chain ID.
+	chainID := t.ChainID
+	if chainID == 0 {
+		chainID = 1
+	}
+
+	// Create a chain config with all block-number-based forks (up to London) activated at genesis.
+	chainConfig := &params.ChainConfig{
+		ChainID:             new(big.Int).SetUint64(chainID),
+		HomesteadBlock:      big.NewInt(0),
+		EIP150Block:         big.NewInt(0),
+		EIP155Block:         big.NewInt(0),
+		EIP158Block:         big.NewInt(0),
+		ByzantiumBlock:      big.NewInt(0),
+		ConstantinopleBlock: big.NewInt(0),
+		PetersburgBlock:     big.NewInt(0),
+		IstanbulBlock:       big.NewInt(0),
+		MuirGlacierBlock:    big.NewInt(0),
+		BerlinBlock:         big.NewInt(0),
+		LondonBlock:         big.NewInt(0),
+		ArrowGlacierBlock:   big.NewInt(0),
+		GrayGlacierBlock:    big.NewInt(0),
+		Ethash:              new(params.EthashConfig),
+	}
+
+	// Activate the merge and any timestamp-based forks which follow it.
+	if isActive("paris") {
+		chainConfig.MergeNetsplitBlock = big.NewInt(0)
+		chainConfig.TerminalTotalDifficulty = big.NewInt(0)
+	}
+	genesisTime := uint64(0)
+	if isActive("shanghai") {
+		chainConfig.ShanghaiTime = &genesisTime
+	}
+	if isActive("cancun") {
+		chainConfig.CancunTime = &genesisTime
+	}
+	if isActive("prague") {
+		chainConfig.PragueTime = &genesisTime
+	}
+	return chainConfig, nil
+}
@@ -1,16 +1,33 @@
 package config
 
+import (
+	"github.com/ethereum/go-ethereum/common"
+)
+
 // DefaultTestChainConfig obtains a default configuration for a chain.TestChain.
 // Returns a TestChainConfig populated with default values.
 func DefaultTestChainConfig() (*TestChainConfig, error) {
 	// Create a default config and return it.
 	config := &TestChainConfig{
+		EVMVersion:            DefaultEVMVersion,
+		ChainID:               1,
 		CodeSizeCheckDisabled: true,
 		CheatCodeConfig: CheatCodeConfig{
-			CheatCodesEnabled: true,
-			EnableFFI:         false,
+			CheatCodesEnabled:  true,
+			EnableFFI:          false,
+			ReadablePaths:      []string{},
+			WritablePaths:      []string{},
+			AllowedCallers:     []common.Address{},
+			AllowedFFICommands: []FFICommandConfig{},
 		},
 		SkipAccountChecks: true,
+		ForkConfig: ForkConfig{
+			ForkModeEnabled: false,
+			RpcUrl:          "",
+			RpcBlock:        0,
+			CacheDirectory:  "",
+		},
+		Precompiles: []PrecompileConfig{},
 	}
 
 	// Return the generated configuration.
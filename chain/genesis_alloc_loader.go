@@ -0,0 +1,132 @@
+package chain
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// anvilStateDump describes the subset of an anvil state dump (as produced by `anvil --dump-state`) which is needed to
+// reconstruct the accounts it describes.
+type anvilStateDump struct {
+	// Accounts describes all accounts in the dumped state, keyed by address.
+	Accounts map[common.Address]anvilAccount `json:"accounts"`
+}
+
+// anvilAccount describes a single account in an anvil state dump.
+type anvilAccount struct {
+	// Nonce describes the account nonce. anvil serializes it as a JSON number, but quantity strings are accepted too.
+	Nonce json.RawMessage `json:"nonce"`
+
+	// Balance describes the account balance as a quantity string.
+	Balance string `json:"balance"`
+
+	// Code describes the account code.
+	Code hexutil.Bytes `json:"code"`
+
+	// Storage describes the account storage, where keys and values are quantity strings which may not be zero-padded.
+	Storage map[string]string `json:"storage"`
+}
+
+// LoadGenesisAllocFromFile loads a types.GenesisAlloc from a file describing the initial state of a chain. The file
+// may either be a go-ethereum genesis file, the "alloc" section of one on its own, or an anvil state dump (as produced
+// by `anvil --dump-state`).
+// Returns the genesis allocations, or an error if one occurred.
+func LoadGenesisAllocFromFile(path string) (types.GenesisAlloc, error) {
+	// Read our file and determine its format based on its top level keys.
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read state file: %v", err)
+	}
+	var topLevel map[string]json.RawMessage
+	err = json.Unmarshal(b, &topLevel)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse state file %s: %v", path, err)
+	}
+
+	// Parse our file according to its format.
+	if _, ok := topLevel["accounts"]; ok {
+		genesisAlloc, err := parseAnvilStateDump(b)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse anvil state dump %s: %v", path, err)
+		}
+		return genesisAlloc, nil
+	}
+	if alloc, ok := topLevel["alloc"]; ok {
+		b = alloc
+	}
+	var genesisAlloc types.GenesisAlloc
+	err = json.Unmarshal(b, &genesisAlloc)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse genesis allocations in %s: %v", path, err)
+	}
+	return genesisAlloc, nil
+}
+
+// parseAnvilStateDump parses the provided anvil state dump data into a types.GenesisAlloc.
+// Returns the genesis allocations, or an error if one occurred.
+func parseAnvilStateDump(b []byte) (types.GenesisAlloc, error) {
+	var stateDump anvilStateDump
+	err := json.Unmarshal(b, &stateDump)
+	if err != nil {
+		return nil, err
+	}
+
+	genesisAlloc := make(types.GenesisAlloc, len(stateDump.Accounts))
+	for address, anvilAccount := range stateDump.Accounts {
+		// Parse our nonce, which may be a number or a quantity string.
+		var nonce uint64
+		if len(anvilAccount.Nonce) > 0 {
+			if err = json.Unmarshal(anvilAccount.Nonce, &nonce); err != nil {
+				var nonceStr string
+				if err = json.Unmarshal(anvilAccount.Nonce, &nonceStr); err != nil {
+					return nil, fmt.Errorf("invalid nonce for account %s", address.String())
+				}
+				var ok bool
+				if nonce, ok = math.ParseUint64(nonceStr); !ok {
+					return nil, fmt.Errorf("invalid nonce for account %s", address.String())
+				}
+			}
+		}
+
+		// Parse our balance.
+		balance := big.NewInt(0)
+		if anvilAccount.Balance != "" {
+			var ok bool
+			if balance, ok = math.ParseBig256(anvilAccount.Balance); !ok {
+				return nil, fmt.Errorf("invalid balance for account %s", address.String())
+			}
+		}
+
+		// Parse our storage, whose keys and values may not be zero-padded.
+		var storage map[common.Hash]common.Hash
+		if len(anvilAccount.Storage) > 0 {
+			storage = make(map[common.Hash]common.Hash, len(anvilAccount.Storage))
+			for key, value := range anvilAccount.Storage {
+				parsedKey, ok := math.ParseBig256(key)
+				if !ok {
+					return nil, fmt.Errorf("invalid storage key %s for account %s", key, address.String())
+				}
+				parsedValue, ok := math.ParseBig256(value)
+				if !ok {
+					return nil, fmt.Errorf("invalid storage value %s for account %s", value, address.String())
+				}
+				storage[common.BigToHash(parsedKey)] = common.BigToHash(parsedValue)
+			}
+		}
+
+		genesisAlloc[address] = types.Account{
+			Code:    anvilAccount.Code,
+			Storage: storage,
+			Balance: balance,
+			Nonce:   nonce,
+		}
+	}
+	return genesisAlloc, nil
+}
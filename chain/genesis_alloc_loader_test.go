@@ -0,0 +1,76 @@
+package chain
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLoadGenesisAllocFromFile ensures that go-ethereum genesis files, their standalone allocations, and anvil state
+// dumps can all be loaded into equivalent genesis allocations.
+func TestLoadGenesisAllocFromFile(t *testing.T) {
+	// Define the same state in each of our supported formats.
+	stateFiles := map[string]string{
+		"genesis.json": `{
+			"config": {"chainId": 1},
+			"alloc": {
+				"0x0000000000000000000000000000000000001234": {
+					"balance": "0x3e8",
+					"nonce": "0x1",
+					"code": "0x60005460005260206000f3",
+					"storage": {"0x0000000000000000000000000000000000000000000000000000000000000000": "0x000000000000000000000000000000000000000000000000000000000000002a"}
+				}
+			}
+		}`,
+		"alloc.json": `{
+			"0x0000000000000000000000000000000000001234": {
+				"balance": "1000",
+				"nonce": "0x1",
+				"code": "0x60005460005260206000f3",
+				"storage": {"0x0000000000000000000000000000000000000000000000000000000000000000": "0x000000000000000000000000000000000000000000000000000000000000002a"}
+			}
+		}`,
+		"anvil.json": `{
+			"block": {"number": "0x5", "timestamp": "0x65"},
+			"accounts": {
+				"0x0000000000000000000000000000000000001234": {
+					"nonce": 1,
+					"balance": "0x3e8",
+					"code": "0x60005460005260206000f3",
+					"storage": {"0x0": "0x2a"}
+				}
+			},
+			"best_block_number": "0x5",
+			"blocks": [],
+			"transactions": []
+		}`,
+	}
+
+	// Load each file and verify it describes our expected state.
+	address := common.HexToAddress("0x1234")
+	directory := t.TempDir()
+	for fileName, contents := range stateFiles {
+		path := filepath.Join(directory, fileName)
+		err := os.WriteFile(path, []byte(contents), 0644)
+		assert.NoError(t, err)
+
+		genesisAlloc, err := LoadGenesisAllocFromFile(path)
+		assert.NoError(t, err, "could not load %s", fileName)
+		assert.Len(t, genesisAlloc, 1)
+		account := genesisAlloc[address]
+		assert.EqualValues(t, 1000, account.Balance.Uint64(), "unexpected balance in %s", fileName)
+		assert.EqualValues(t, 1, account.Nonce, "unexpected nonce in %s", fileName)
+		assert.EqualValues(t, common.FromHex("0x60005460005260206000f3"), account.Code, "unexpected code in %s", fileName)
+		assert.EqualValues(t, common.HexToHash("0x2a"), account.Storage[common.Hash{}], "unexpected storage in %s", fileName)
+	}
+
+	// Invalid files should return an error.
+	path := filepath.Join(directory, "invalid.json")
+	err := os.WriteFile(path, []byte("[]"), 0644)
+	assert.NoError(t, err)
+	_, err = LoadGenesisAllocFromFile(path)
+	assert.Error(t, err)
+}
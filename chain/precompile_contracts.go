@@ -0,0 +1,125 @@
+package chain
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"fmt"
+	"math/big"
+
+	"github.com/crytic/medusa/chain/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// P256VerifyPrecompileAddress is the default address for the P256VERIFY precompile, as defined by RIP-7212.
+var P256VerifyPrecompileAddress = common.HexToAddress("0x0000000000000000000000000000000000000100")
+
+// ArbSysPrecompileAddress is the default address for the ArbSys precompile, as found on Arbitrum chains.
+var ArbSysPrecompileAddress = common.HexToAddress("0x0000000000000000000000000000000000000064")
+
+// builtinPrecompile describes a built-in precompile implementation which can be installed on a TestChain through its
+// config.
+type builtinPrecompile struct {
+	// defaultAddress describes the address the precompile is installed at if no address is provided.
+	defaultAddress common.Address
+
+	// newPrecompile creates the precompile at the provided address, using the provided cheatCodeTracer for execution
+	// context.
+	newPrecompile func(tracer *cheatCodeTracer, address common.Address) (vm.PrecompiledContract, error)
+}
+
+// builtinPrecompiles describes a table of built-in precompile names to their implementations.
+var builtinPrecompiles = map[string]builtinPrecompile{
+	config.BuiltinPrecompileP256Verify: {
+		defaultAddress: P256VerifyPrecompileAddress,
+		newPrecompile: func(tracer *cheatCodeTracer, address common.Address) (vm.PrecompiledContract, error) {
+			return &p256VerifyPrecompile{}, nil
+		},
+	},
+	config.BuiltinPrecompileArbSys: {
+		defaultAddress: ArbSysPrecompileAddress,
+		newPrecompile: func(tracer *cheatCodeTracer, address common.Address) (vm.PrecompiledContract, error) {
+			return getArbSysPrecompileContract(tracer, address)
+		},
+	},
+}
+
+// getConfiguredPrecompiles obtains the built-in precompiles described by the provided precompile configs, using the
+// provided cheatCodeTracer for execution context. Precompiles which are implemented by a contract are expected to have
+// their code present in the provided genesis allocations already, and are only verified.
+// Returns a mapping of addresses to the precompiles to install there, or an error if one occurred.
+func getConfiguredPrecompiles(tracer *cheatCodeTracer, precompileConfigs []config.PrecompileConfig, genesisAlloc types.GenesisAlloc) (map[common.Address]vm.PrecompiledContract, error) {
+	precompiles := make(map[common.Address]vm.PrecompiledContract)
+	for _, precompileConfig := range precompileConfigs {
+		// Verify exactly one implementation was provided for this precompile.
+		if (precompileConfig.Builtin == "") == (precompileConfig.ContractName == "") {
+			return nil, fmt.Errorf("precompile at address %s must specify exactly one of a built-in precompile or a contract name", precompileConfig.Address.String())
+		}
+
+		// If this precompile is implemented by a contract, its code should have been provided at genesis.
+		if precompileConfig.ContractName != "" {
+			if account, ok := genesisAlloc[precompileConfig.Address]; !ok || len(account.Code) == 0 {
+				return nil, fmt.Errorf("precompile contract %s has no code at address %s at genesis", precompileConfig.ContractName, precompileConfig.Address.String())
+			}
+			continue
+		}
+
+		// Otherwise, resolve our built-in precompile and create it at its address.
+		builtin, ok := builtinPrecompiles[precompileConfig.Builtin]
+		if !ok {
+			return nil, fmt.Errorf("unsupported built-in precompile '%s'", precompileConfig.Builtin)
+		}
+		address := precompileConfig.Address
+		if address == (common.Address{}) {
+			address = builtin.defaultAddress
+		}
+		if _, exists := precompiles[address]; exists {
+			return nil, fmt.Errorf("multiple precompiles were configured at address %s", address.String())
+		}
+		precompile, err := builtin.newPrecompile(tracer, address)
+		if err != nil {
+			return nil, err
+		}
+		precompiles[address] = precompile
+	}
+	return precompiles, nil
+}
+
+// p256VerifyPrecompile implements the P256VERIFY precompile defined by RIP-7212, which verifies signatures over the
+// secp256r1 (P-256) curve.
+type p256VerifyPrecompile struct{}
+
+// p256VerifyGas describes the gas cost of the P256VERIFY precompile, as defined by RIP-7212.
+const p256VerifyGas = 3450
+
+// RequiredGas determines the amount of gas necessary to execute the pre-compile with the given input data.
+// Returns the gas cost.
+func (p *p256VerifyPrecompile) RequiredGas(input []byte) uint64 {
+	return p256VerifyGas
+}
+
+// Run executes the given pre-compile with the provided input data. The input is expected to be the 32-byte message
+// hash, followed by the 32-byte r and s signature values, and the 32-byte x and y public key coordinates.
+// Returns 1 as a 32-byte word if the signature is valid, or empty output otherwise.
+func (p *p256VerifyPrecompile) Run(input []byte) ([]byte, error) {
+	// Verify our input has the expected length.
+	if len(input) != 160 {
+		return nil, nil
+	}
+
+	// Parse our input values and verify our public key is on the curve.
+	hash := input[0:32]
+	r, s := new(big.Int).SetBytes(input[32:64]), new(big.Int).SetBytes(input[64:96])
+	x, y := new(big.Int).SetBytes(input[96:128]), new(big.Int).SetBytes(input[128:160])
+	curve := elliptic.P256()
+	if !curve.IsOnCurve(x, y) {
+		return nil, nil
+	}
+
+	// Verify our signature.
+	if !ecdsa.Verify(&ecdsa.PublicKey{Curve: curve, X: x, Y: y}, hash, r, s) {
+		return nil, nil
+	}
+	return common.LeftPadBytes([]byte{1}, 32), nil
+}
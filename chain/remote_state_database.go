@@ -0,0 +1,249 @@
+package chain
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// remoteStateDatabase implements state.Database, wrapping the state database of a TestChain which was created in fork
+// mode. Accounts, code and storage slots which do not exist in the local tries are read from a remoteStateProvider, so
+// the state.StateDB treats remote state as committed state. Remote state is never written to the local tries by
+// reading it. It only ends up in them once a transaction changes it, as with any other committed state.
+//
+// Accounts which exist in the local genesis state, as well as precompiled contracts, are never read from remote.
+// As a missing key in a local trie indicates that remote state should be read, deletions of remote state are recorded
+// in the local tries, so they are not shadowed by remote state again. See remoteAccountTrie and remoteStorageTrie.
+type remoteStateDatabase struct {
+	// Database is the underlying state database which holds the local state of the chain.
+	state.Database
+
+	// provider describes the remote state provider used to fetch remote state.
+	provider *remoteStateProvider
+
+	// localAccounts describes the accounts which are never read from remote.
+	localAccounts map[common.Address]struct{}
+}
+
+// newRemoteStateDatabase creates a remoteStateDatabase which wraps the provided state.Database, reading any state
+// missing from it (except for the provided local accounts) from the provided remoteStateProvider.
+func newRemoteStateDatabase(db state.Database, provider *remoteStateProvider, localAccounts map[common.Address]struct{}) *remoteStateDatabase {
+	return &remoteStateDatabase{
+		Database:      db,
+		provider:      provider,
+		localAccounts: localAccounts,
+	}
+}
+
+// isRemote determines whether the provided address may be read from remote.
+func (d *remoteStateDatabase) isRemote(address common.Address) bool {
+	_, isLocal := d.localAccounts[address]
+	return !isLocal
+}
+
+// getRemoteAccount obtains the provided account from remote, if it may be read from remote.
+// Returns the remote account, or nil if it may not be read from remote or is empty remotely. If an error occurs while
+// fetching the account, it is returned.
+func (d *remoteStateDatabase) getRemoteAccount(address common.Address) (*remoteAccount, error) {
+	if !d.isRemote(address) {
+		return nil, nil
+	}
+	account, err := d.provider.GetAccount(address)
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch remote account %s: %v", address.String(), err)
+	}
+	if account.Nonce == 0 && len(account.Code) == 0 && (account.Balance == nil || account.Balance.ToInt().Sign() == 0) {
+		return nil, nil
+	}
+	return account, nil
+}
+
+// OpenTrie opens the main account trie, wrapping it so missing accounts are read from remote.
+func (d *remoteStateDatabase) OpenTrie(root common.Hash) (state.Trie, error) {
+	tr, err := d.Database.OpenTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	return &remoteAccountTrie{Trie: tr, db: d}, nil
+}
+
+// OpenStorageTrie opens the storage trie of an account, wrapping it so missing storage slots are read from remote if
+// the account exists remotely and was not deleted locally.
+func (d *remoteStateDatabase) OpenStorageTrie(stateRoot common.Hash, address common.Address, root common.Hash, trie state.Trie) (state.Trie, error) {
+	// Unwrap our account trie before handing it to the underlying database.
+	accountTrie, ok := trie.(*remoteAccountTrie)
+	if ok {
+		trie = accountTrie.Trie
+	}
+	tr, err := d.Database.OpenStorageTrie(stateRoot, address, root, trie)
+	if err != nil {
+		return nil, err
+	}
+
+	// Determine whether this account's storage should be read from remote. Accounts are deleted locally before their
+	// storage could be accessed again, so this only needs to be determined once.
+	remote := false
+	if ok {
+		deleted, err := accountTrie.isDeleted(address)
+		if err != nil {
+			return nil, err
+		}
+		if !deleted {
+			remoteAccount, err := d.getRemoteAccount(address)
+			if err != nil {
+				return nil, err
+			}
+			remote = remoteAccount != nil
+		}
+	}
+	return &remoteStorageTrie{Trie: tr, db: d, remote: remote}, nil
+}
+
+// CopyTrie returns an independent copy of the given trie.
+func (d *remoteStateDatabase) CopyTrie(trie state.Trie) state.Trie {
+	switch t := trie.(type) {
+	case *remoteAccountTrie:
+		return &remoteAccountTrie{Trie: d.Database.CopyTrie(t.Trie), db: d}
+	case *remoteStorageTrie:
+		return &remoteStorageTrie{Trie: d.Database.CopyTrie(t.Trie), db: d, remote: t.remote}
+	default:
+		return d.Database.CopyTrie(trie)
+	}
+}
+
+// ContractCode retrieves a particular contract's code, reading it from remote if it does not exist locally.
+func (d *remoteStateDatabase) ContractCode(address common.Address, codeHash common.Hash) ([]byte, error) {
+	code, err := d.Database.ContractCode(address, codeHash)
+	if err == nil {
+		return code, nil
+	}
+
+	// Remote code is never written locally, unless it is replaced, so it is read from remote if the hash matches.
+	remoteAccount, remoteErr := d.getRemoteAccount(address)
+	if remoteErr != nil {
+		return nil, remoteErr
+	}
+	if remoteAccount != nil && crypto.Keccak256Hash(remoteAccount.Code) == codeHash {
+		return remoteAccount.Code, nil
+	}
+	return nil, err
+}
+
+// ContractCodeSize retrieves a particular contract's code size, reading it from remote if it does not exist locally.
+func (d *remoteStateDatabase) ContractCodeSize(address common.Address, codeHash common.Hash) (int, error) {
+	code, err := d.ContractCode(address, codeHash)
+	if err != nil {
+		return 0, err
+	}
+	return len(code), nil
+}
+
+// remoteAccountTrie wraps the account trie of a remoteStateDatabase, reading accounts which do not exist locally from
+// remote. When an account which exists remotely is deleted, a marker account is stored locally at an address derived
+// from it (see remoteAccountDeletionMarker), so it is not read from remote again.
+type remoteAccountTrie struct {
+	// Trie is the underlying local account trie.
+	state.Trie
+
+	// db describes the database which opened this trie.
+	db *remoteStateDatabase
+}
+
+// remoteAccountDeletionMarker derives the address of the marker account which indicates that the provided remote
+// account was deleted locally. The marker address is a hash, so it can not be accessed by any contract.
+func remoteAccountDeletionMarker(address common.Address) common.Address {
+	return common.BytesToAddress(crypto.Keccak256([]byte("medusa.remote.deleted"), address.Bytes()))
+}
+
+// isDeleted determines whether the provided remote account was deleted locally.
+func (t *remoteAccountTrie) isDeleted(address common.Address) (bool, error) {
+	marker, err := t.Trie.GetAccount(remoteAccountDeletionMarker(address))
+	return marker != nil, err
+}
+
+// GetAccount returns the account with the provided address, reading it from remote if it does not exist locally and
+// was not deleted locally.
+func (t *remoteAccountTrie) GetAccount(address common.Address) (*types.StateAccount, error) {
+	// If the account exists locally, it takes precedence.
+	account, err := t.Trie.GetAccount(address)
+	if err != nil || account != nil {
+		return account, err
+	}
+
+	// Otherwise, read it from remote, unless it was deleted locally.
+	deleted, err := t.isDeleted(address)
+	if err != nil || deleted {
+		return nil, err
+	}
+	remoteAccount, err := t.db.getRemoteAccount(address)
+	if err != nil || remoteAccount == nil {
+		return nil, err
+	}
+	account = types.NewEmptyStateAccount()
+	account.Nonce = uint64(remoteAccount.Nonce)
+	if remoteAccount.Balance != nil {
+		account.Balance = uint256.MustFromBig(remoteAccount.Balance.ToInt())
+	}
+	if len(remoteAccount.Code) > 0 {
+		account.CodeHash = crypto.Keccak256(remoteAccount.Code)
+	}
+	return account, nil
+}
+
+// DeleteAccount removes the account with the provided address from the trie, storing a marker account if it exists
+// remotely, so it is not read from remote again.
+func (t *remoteAccountTrie) DeleteAccount(address common.Address) error {
+	err := t.Trie.DeleteAccount(address)
+	if err != nil {
+		return err
+	}
+	remoteAccount, err := t.db.getRemoteAccount(address)
+	if err != nil || remoteAccount == nil {
+		return err
+	}
+	marker := types.NewEmptyStateAccount()
+	marker.Nonce = 1
+	return t.Trie.UpdateAccount(remoteAccountDeletionMarker(address), marker)
+}
+
+// remoteStorageTrie wraps the storage trie of an account in a remoteStateDatabase, reading storage slots which do
+// not exist locally from remote, if the account exists remotely. When a storage slot of such an account is cleared,
+// an empty value is stored for it locally, so it is not read from remote again.
+type remoteStorageTrie struct {
+	// Trie is the underlying local storage trie.
+	state.Trie
+
+	// db describes the database which opened this trie.
+	db *remoteStateDatabase
+
+	// remote indicates whether missing storage slots are read from remote.
+	remote bool
+}
+
+// GetStorage returns the value of the provided storage slot, reading it from remote if it does not exist locally.
+func (t *remoteStorageTrie) GetStorage(address common.Address, key []byte) ([]byte, error) {
+	// The underlying trie returns a nil value only if the slot does not exist. Slots cleared locally hold an empty
+	// value.
+	value, err := t.Trie.GetStorage(address, key)
+	if err != nil || value != nil || !t.remote {
+		return value, err
+	}
+	remoteValue, err := t.db.provider.GetStorageAt(address, common.BytesToHash(key))
+	if err != nil {
+		return nil, fmt.Errorf("could not fetch remote storage slot %s of account %s: %v", common.BytesToHash(key).String(), address.String(), err)
+	}
+	return common.TrimLeftZeroes(remoteValue[:]), nil
+}
+
+// DeleteStorage removes the provided storage slot from the trie. If the account exists remotely, an empty value is
+// stored instead, so the slot is not read from remote again.
+func (t *remoteStorageTrie) DeleteStorage(address common.Address, key []byte) error {
+	if !t.remote {
+		return t.Trie.DeleteStorage(address, key)
+	}
+	return t.Trie.UpdateStorage(address, key, nil)
+}
@@ -0,0 +1,255 @@
+package chain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/crytic/medusa/chain/config"
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// remoteStateProvider fetches account and storage data from a remote JSON-RPC endpoint at a pinned block number.
+// All fetched data is cached in memory and, if a cache directory is provided, on disk. A single provider is shared
+// between a TestChain and all of its clones, so each piece of remote state is only fetched once.
+type remoteStateProvider struct {
+	// client is the JSON-RPC client used to query the remote endpoint.
+	client *rpc.Client
+
+	// cache stores all remote state fetched so far.
+	cache *remoteStateCache
+
+	// cacheFilePath describes the path of the file the cache is persisted to. If empty, the cache is not persisted.
+	cacheFilePath string
+
+	// cacheDirty indicates whether the cache holds data which has not yet been persisted to disk.
+	cacheDirty bool
+
+	// cacheLock is used to synchronize access to the cache across chains which share this provider.
+	cacheLock sync.Mutex
+}
+
+// remoteStateCache describes the remote state which was fetched for a given block number. It is serialized to disk
+// as-is when the cache is persisted.
+type remoteStateCache struct {
+	// BlockNumber describes the remote block number which all state was fetched at.
+	BlockNumber uint64 `json:"blockNumber"`
+
+	// BlockTimestamp describes the timestamp of the remote block which all state was fetched at.
+	BlockTimestamp uint64 `json:"blockTimestamp"`
+
+	// Accounts describes the remote accounts which were fetched, and any of their storage slots which were fetched.
+	Accounts map[common.Address]*remoteAccount `json:"accounts"`
+}
+
+// remoteAccount describes an account fetched from the remote endpoint.
+type remoteAccount struct {
+	// Balance describes the balance of the account.
+	Balance *hexutil.Big `json:"balance"`
+
+	// Nonce describes the nonce of the account.
+	Nonce hexutil.Uint64 `json:"nonce"`
+
+	// Code describes the code of the account.
+	Code hexutil.Bytes `json:"code"`
+
+	// Storage describes the storage slots of the account which were fetched so far.
+	Storage map[common.Hash]common.Hash `json:"storage"`
+}
+
+// newRemoteStateProvider creates a remoteStateProvider using the provided fork configuration. If the configuration
+// does not specify a block number, the latest block number reported by the remote endpoint is pinned.
+// Returns the provider, or an error if one occurred.
+func newRemoteStateProvider(forkConfig config.ForkConfig) (*remoteStateProvider, error) {
+	// Verify we were provided an endpoint
+	if forkConfig.RpcUrl == "" {
+		return nil, errors.New("could not create remote state provider, fork mode is enabled but no RPC URL was provided")
+	}
+
+	// Connect to our endpoint
+	client, err := rpc.DialContext(context.Background(), forkConfig.RpcUrl)
+	if err != nil {
+		return nil, fmt.Errorf("could not connect to fork RPC endpoint: %v", err)
+	}
+	provider := &remoteStateProvider{
+		client: client,
+	}
+
+	// If we were not provided a block number, pin the latest one.
+	blockNumber := forkConfig.RpcBlock
+	if blockNumber == 0 {
+		var latestBlockNumber hexutil.Uint64
+		err = provider.call(&latestBlockNumber, "eth_blockNumber")
+		if err != nil {
+			return nil, err
+		}
+		blockNumber = uint64(latestBlockNumber)
+	}
+
+	// If we have a cache directory, determine our cache file path, which is unique to the endpoint and block number,
+	// and try to load any existing cache from it.
+	if forkConfig.CacheDirectory != "" {
+		endpointHash := crypto.Keccak256Hash([]byte(forkConfig.RpcUrl))
+		provider.cacheFilePath = filepath.Join(forkConfig.CacheDirectory, fmt.Sprintf("%d-%x.json", blockNumber, endpointHash[:8]))
+
+		b, err := os.ReadFile(provider.cacheFilePath)
+		if err == nil {
+			err = json.Unmarshal(b, &provider.cache)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse fork cache file %s: %v", provider.cacheFilePath, err)
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+
+	// If we did not load a cache, create a new one, fetching the timestamp of our pinned block.
+	if provider.cache == nil || provider.cache.BlockNumber != blockNumber {
+		var block struct {
+			Timestamp hexutil.Uint64 `json:"timestamp"`
+		}
+		err = provider.call(&block, "eth_getBlockByNumber", hexutil.EncodeUint64(blockNumber), false)
+		if err != nil {
+			return nil, err
+		}
+		provider.cache = &remoteStateCache{
+			BlockNumber:    blockNumber,
+			BlockTimestamp: uint64(block.Timestamp),
+			Accounts:       make(map[common.Address]*remoteAccount),
+		}
+		provider.cacheDirty = true
+	}
+	return provider, nil
+}
+
+// call performs a JSON-RPC call against the remote endpoint, storing the result in the provided value.
+// Returns an error if one occurred.
+func (p *remoteStateProvider) call(result any, method string, args ...any) error {
+	err := p.client.CallContext(context.Background(), result, method, args...)
+	if err != nil {
+		return fmt.Errorf("fork RPC request %s failed: %v", method, err)
+	}
+	return nil
+}
+
+// BlockNumber returns the remote block number which state is fetched at.
+func (p *remoteStateProvider) BlockNumber() uint64 {
+	return p.cache.BlockNumber
+}
+
+// BlockTimestamp returns the timestamp of the remote block which state is fetched at.
+func (p *remoteStateProvider) BlockTimestamp() uint64 {
+	return p.cache.BlockTimestamp
+}
+
+// GetAccount obtains the balance, nonce and code of an account at the pinned block number, fetching it from the
+// remote endpoint if it was not previously cached.
+// Returns the account, or an error if one occurred.
+func (p *remoteStateProvider) GetAccount(address common.Address) (*remoteAccount, error) {
+	// If we already cached this account, return it.
+	p.cacheLock.Lock()
+	account, ok := p.cache.Accounts[address]
+	p.cacheLock.Unlock()
+	if ok {
+		return account, nil
+	}
+
+	// Otherwise fetch it from our remote endpoint.
+	blockTag := hexutil.EncodeUint64(p.cache.BlockNumber)
+	account = &remoteAccount{
+		Storage: make(map[common.Hash]common.Hash),
+	}
+	err := p.call(&account.Balance, "eth_getBalance", address, blockTag)
+	if err != nil {
+		return nil, err
+	}
+	err = p.call(&account.Nonce, "eth_getTransactionCount", address, blockTag)
+	if err != nil {
+		return nil, err
+	}
+	err = p.call(&account.Code, "eth_getCode", address, blockTag)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store it in our cache. If another chain fetched it in the meantime, we prefer the existing entry, as it may
+	// already hold storage slots.
+	p.cacheLock.Lock()
+	defer p.cacheLock.Unlock()
+	if existingAccount, ok := p.cache.Accounts[address]; ok {
+		return existingAccount, nil
+	}
+	p.cache.Accounts[address] = account
+	p.cacheDirty = true
+	return account, nil
+}
+
+// GetStorageAt obtains the value of an account's storage slot at the pinned block number, fetching it from the
+// remote endpoint if it was not previously cached.
+// Returns the storage slot value, or an error if one occurred.
+func (p *remoteStateProvider) GetStorageAt(address common.Address, slot common.Hash) (common.Hash, error) {
+	// Obtain our account first, so we have somewhere to cache our slot.
+	account, err := p.GetAccount(address)
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	// If we already cached this slot, return it.
+	p.cacheLock.Lock()
+	value, ok := account.Storage[slot]
+	p.cacheLock.Unlock()
+	if ok {
+		return value, nil
+	}
+
+	// Otherwise fetch it from our remote endpoint.
+	var result hexutil.Bytes
+	err = p.call(&result, "eth_getStorageAt", address, slot, hexutil.EncodeUint64(p.cache.BlockNumber))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	value = common.BytesToHash(result)
+
+	// Store it in our cache.
+	p.cacheLock.Lock()
+	defer p.cacheLock.Unlock()
+	account.Storage[slot] = value
+	p.cacheDirty = true
+	return value, nil
+}
+
+// Flush persists any cached remote state to disk, if a cache directory was provided.
+// Returns an error if one occurred.
+func (p *remoteStateProvider) Flush() error {
+	p.cacheLock.Lock()
+	defer p.cacheLock.Unlock()
+
+	// If we have nothing to write, or nowhere to write it, stop.
+	if !p.cacheDirty || p.cacheFilePath == "" {
+		return nil
+	}
+
+	// Serialize our cache and write it to disk.
+	b, err := json.Marshal(p.cache)
+	if err != nil {
+		return err
+	}
+	err = utils.MakeDirectory(filepath.Dir(p.cacheFilePath))
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(p.cacheFilePath, b, 0644)
+	if err != nil {
+		return err
+	}
+	p.cacheDirty = false
+	return nil
+}
@@ -3,19 +3,29 @@ package chain
 import (
 	"crypto/ecdsa"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"math/big"
+	"os"
 	"os/exec"
+	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 
 	"github.com/crytic/medusa/utils"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/tracing"
 	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/holiman/uint256"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 )
 
 // StandardCheatcodeContractAddress is the address for the standard cheatcode contract
@@ -30,6 +40,9 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 	// Create a new precompile to add methods to.
 	contract := newCheatCodeContract(tracer, StandardCheatcodeContractAddress, "StdCheats")
 
+	// Calls to the standard cheat codes are restricted to the allowed callers in the cheat code configuration.
+	contract.callersRestricted = true
+
 	// Define some basic ABI argument types
 	typeAddress, err := abi.NewType("address", "", nil)
 	if err != nil {
@@ -39,6 +52,10 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 	if err != nil {
 		return nil, err
 	}
+	typeBytes4, err := abi.NewType("bytes4", "", nil)
+	if err != nil {
+		return nil, err
+	}
 	typeBytes32, err := abi.NewType("bytes32", "", nil)
 	if err != nil {
 		return nil, err
@@ -47,6 +64,10 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 	if err != nil {
 		return nil, err
 	}
+	typeUint32, err := abi.NewType("uint32", "", nil)
+	if err != nil {
+		return nil, err
+	}
 	typeUint64, err := abi.NewType("uint64", "", nil)
 	if err != nil {
 		return nil, err
@@ -59,6 +80,10 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 	if err != nil {
 		return nil, err
 	}
+	typeBytes32Slice, err := abi.NewType("bytes32[]", "", nil)
+	if err != nil {
+		return nil, err
+	}
 	typeStringSlice, err := abi.NewType("string[]", "", nil)
 	if err != nil {
 		return nil, err
@@ -71,6 +96,71 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 	if err != nil {
 		return nil, err
 	}
+	typeUint256Slice, err := abi.NewType("uint256[]", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	typeInt256Slice, err := abi.NewType("int256[]", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	typeAddressSlice, err := abi.NewType("address[]", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	typeBoolSlice, err := abi.NewType("bool[]", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	typeBytesSlice, err := abi.NewType("bytes[]", "", nil)
+	if err != nil {
+		return nil, err
+	}
+	typeAccountAccessSlice, err := abi.NewType("tuple[]", "AccountAccess[]", []abi.ArgumentMarshaling{
+		{Name: "chainInfo", Type: "tuple", InternalType: "ChainInfo", Components: []abi.ArgumentMarshaling{
+			{Name: "forkId", Type: "uint256"},
+			{Name: "chainId", Type: "uint256"},
+		}},
+		{Name: "kind", Type: "uint8"},
+		{Name: "account", Type: "address"},
+		{Name: "accessor", Type: "address"},
+		{Name: "initialized", Type: "bool"},
+		{Name: "oldBalance", Type: "uint256"},
+		{Name: "newBalance", Type: "uint256"},
+		{Name: "deployedCode", Type: "bytes"},
+		{Name: "value", Type: "uint256"},
+		{Name: "data", Type: "bytes"},
+		{Name: "reverted", Type: "bool"},
+		{Name: "storageAccesses", Type: "tuple[]", InternalType: "StorageAccess[]", Components: []abi.ArgumentMarshaling{
+			{Name: "account", Type: "address"},
+			{Name: "slot", Type: "bytes32"},
+			{Name: "isWrite", Type: "bool"},
+			{Name: "previousValue", Type: "bytes32"},
+			{Name: "newValue", Type: "bytes32"},
+			{Name: "reverted", Type: "bool"},
+		}},
+		{Name: "depth", Type: "uint64"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	typeLogSlice, err := abi.NewType("tuple[]", "Log[]", []abi.ArgumentMarshaling{
+		{Name: "topics", Type: "bytes32[]"},
+		{Name: "data", Type: "bytes"},
+		{Name: "emitter", Type: "address"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	typeWallet, err := abi.NewType("tuple", "Wallet", []abi.ArgumentMarshaling{
+		{Name: "addr", Type: "address"},
+		{Name: "publicKeyX", Type: "uint256"},
+		{Name: "publicKeyY", Type: "uint256"},
+		{Name: "privateKey", Type: "uint256"},
+	})
+	if err != nil {
+		return nil, err
+	}
 
 	// Warp: Sets VM timestamp
 	contract.addMethod(
@@ -141,6 +231,49 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 		},
 	)
 
+	// BlobHashes: Sets the blob versioned hashes of the current transaction
+	contract.addMethod(
+		"blobhashes", abi.Arguments{{Type: typeBytes32Slice}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			// Verify the chain supports blobs.
+			if !tracer.chain.pendingBlockChainConfig.IsCancun(tracer.chain.pendingBlockContext.BlockNumber, tracer.chain.pendingBlockContext.Time) {
+				return nil, cheatCodeRevertData([]byte("blobhashes: blobs are not supported prior to the cancun evm version"))
+			}
+
+			// Maintain our changes until the transaction exits.
+			hashes := inputs[0].([][32]byte)
+			blobHashes := make([]common.Hash, len(hashes))
+			for i, hash := range hashes {
+				blobHashes[i] = hash
+			}
+			original := tracer.chain.pendingTxContext.BlobHashes
+			tracer.chain.pendingTxContext.BlobHashes = blobHashes
+			tracer.CurrentCallFrame().onTopFrameExitRestoreHooks.Push(func() {
+				tracer.chain.pendingTxContext.BlobHashes = original
+			})
+			return nil, nil
+		},
+	)
+
+	// BlobBaseFee: Updates blob base fee
+	contract.addMethod(
+		"blobBaseFee", abi.Arguments{{Type: typeUint256}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			// Verify the chain supports blobs.
+			if !tracer.chain.pendingBlockChainConfig.IsCancun(tracer.chain.pendingBlockContext.BlockNumber, tracer.chain.pendingBlockContext.Time) {
+				return nil, cheatCodeRevertData([]byte("blobBaseFee: blobs are not supported prior to the cancun evm version"))
+			}
+
+			// Maintain our changes until the transaction exits.
+			original := tracer.chain.pendingBlockContext.BlobBaseFee
+			tracer.chain.pendingBlockContext.BlobBaseFee = new(big.Int).Set(inputs[0].(*big.Int))
+			tracer.CurrentCallFrame().onTopFrameExitRestoreHooks.Push(func() {
+				tracer.chain.pendingBlockContext.BlobBaseFee = original
+			})
+			return nil, nil
+		},
+	)
+
 	// ChainId: Sets VM chain ID
 	contract.addMethod(
 		"chainId", abi.Arguments{{Type: typeUint256}}, abi.Arguments{},
@@ -245,9 +378,11 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 			// Obtain the caller frame. This is a pre-compile, so we want to add an event to the frame which called us,
 			// so when it enters the next frame in its scope, we trigger the prank.
 			cheatCodeCallerFrame := tracer.PreviousCallFrame()
+			cheatCodeCallerFrame.nextCallPrank = &cheatCodePrank{sender: inputs[0].(common.Address)}
 			cheatCodeCallerFrame.onNextFrameEnterHooks.Push(func() {
 				// We entered the scope we want to prank, store the original value, patch, and add a hook to restore it
 				// when this frame is exited.
+				cheatCodeCallerFrame.nextCallPrank = nil
 				prankCallFrame := tracer.CurrentCallFrame()
 				// We can cast OpContext to ScopeContext because that is the type passed to OnOpcode.
 				scopeContext := prankCallFrame.vmScope.(*vm.ScopeContext)
@@ -281,6 +416,453 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 		},
 	)
 
+	// startPrank sets the msg.sender, and optionally the tx.origin, of all calls made by the caller until stopPrank is
+	// called or the caller exits.
+	startPrank := func(tracer *cheatCodeTracer, sender common.Address, origin *common.Address) *cheatCodeRawReturnData {
+		// Obtain the caller frame. This is a pre-compile, so we want to start the prank in the frame which called us.
+		cheatCodeCallerFrame := tracer.PreviousCallFrame()
+		if cheatCodeCallerFrame.activePrank != nil {
+			return cheatCodeRevertData([]byte("startPrank: a prank is already active, stopPrank must be called first"))
+		}
+		cheatCodeCallerFrame.activePrank = &cheatCodePrank{
+			sender: sender,
+			origin: origin,
+		}
+		return nil
+	}
+
+	// StartPrank: Sets the msg.sender within all EVM call scopes created by the caller, until stopped.
+	contract.addMethod(
+		"startPrank", abi.Arguments{{Type: typeAddress}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return nil, startPrank(tracer, inputs[0].(common.Address), nil)
+		},
+	)
+
+	// StartPrank: Sets the msg.sender and tx.origin within all EVM call scopes created by the caller, until stopped.
+	contract.addMethod(
+		"startPrank", abi.Arguments{{Type: typeAddress}, {Type: typeAddress}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			origin := inputs[1].(common.Address)
+			return nil, startPrank(tracer, inputs[0].(common.Address), &origin)
+		},
+	)
+
+	// StopPrank: Stops the prank started by the caller.
+	contract.addMethod(
+		"stopPrank", abi.Arguments{}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			cheatCodeCallerFrame := tracer.PreviousCallFrame()
+			if cheatCodeCallerFrame.activePrank == nil {
+				return nil, cheatCodeRevertData([]byte("stopPrank: no prank is active"))
+			}
+			cheatCodeCallerFrame.activePrank = nil
+			return nil, nil
+		},
+	)
+
+	// ReadCallers: Returns the caller mode, msg.sender and tx.origin which will be used for the next call made by the
+	// caller.
+	contract.addMethod(
+		"readCallers", abi.Arguments{}, abi.Arguments{{Type: typeUint8}, {Type: typeAddress}, {Type: typeAddress}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			// Without any prank, calls made by the caller are sent by its address, with the current tx.origin.
+			cheatCodeCallerFrame := tracer.PreviousCallFrame()
+			mode := cheatCodeCallerModeNone
+			sender := cheatCodeCallerFrame.vmScope.Address()
+			origin := tracer.chain.pendingTxContext.Origin
+
+			// A prank of the next call takes precedence over a started prank.
+			if cheatCodeCallerFrame.nextCallPrank != nil {
+				mode = cheatCodeCallerModePrank
+				sender = cheatCodeCallerFrame.nextCallPrank.sender
+			} else if cheatCodeCallerFrame.activePrank != nil {
+				mode = cheatCodeCallerModeRecurrentPrank
+				sender = cheatCodeCallerFrame.activePrank.sender
+				if cheatCodeCallerFrame.activePrank.origin != nil {
+					origin = *cheatCodeCallerFrame.activePrank.origin
+				}
+			}
+			return []any{uint8(mode), sender, origin}, nil
+		},
+	)
+
+	// expectRevert sets an expectation that the next call made by the caller reverts with data matching the provided
+	// data. Calls to cheat code contracts are not considered.
+	expectRevert := func(tracer *cheatCodeTracer, revertData []byte, prefixMatch bool) *cheatCodeRawReturnData {
+		// Obtain the caller frame. This is a pre-compile, so we want to set the expectation on the frame which called us.
+		cheatCodeCallerFrame := tracer.PreviousCallFrame()
+		if cheatCodeCallerFrame.nextCallExpectedRevert != nil {
+			return cheatCodeRevertData([]byte("expectRevert: a revert is already expected of the next call"))
+		}
+		cheatCodeCallerFrame.nextCallExpectedRevert = &cheatCodeExpectedRevert{
+			revertData:  revertData,
+			prefixMatch: prefixMatch,
+		}
+		return nil
+	}
+
+	// ExpectRevert: Expects the next call to revert
+	contract.addMethod(
+		"expectRevert", abi.Arguments{}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return nil, expectRevert(tracer, nil, false)
+		},
+	)
+
+	// ExpectRevert: Expects the next call to revert with data beginning with the provided selector
+	contract.addMethod(
+		"expectRevert", abi.Arguments{{Type: typeBytes4}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			selector := inputs[0].([4]byte)
+			return nil, expectRevert(tracer, selector[:], true)
+		},
+	)
+
+	// ExpectRevert: Expects the next call to revert with the provided data
+	contract.addMethod(
+		"expectRevert", abi.Arguments{{Type: typeBytes}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return nil, expectRevert(tracer, inputs[0].([]byte), false)
+		},
+	)
+
+	// expectEmit sets an expectation that the caller emits an event matching the next event it emits itself, before
+	// it exits.
+	expectEmit := func(tracer *cheatCodeTracer, inputs []any, emitter *common.Address) {
+		// Obtain the caller frame. This is a pre-compile, so we want to set the expectation on the frame which called us.
+		cheatCodeCallerFrame := tracer.PreviousCallFrame()
+		cheatCodeCallerFrame.expectedEmits = append(cheatCodeCallerFrame.expectedEmits, &cheatCodeExpectedEmit{
+			checkTopics: [3]bool{inputs[0].(bool), inputs[1].(bool), inputs[2].(bool)},
+			checkData:   inputs[3].(bool),
+			emitter:     emitter,
+		})
+		tracer.recordingExpectations = true
+	}
+
+	// ExpectEmit: Expects the next event emitted by the caller to be emitted before it exits
+	contract.addMethod(
+		"expectEmit", abi.Arguments{{Type: typeBool}, {Type: typeBool}, {Type: typeBool}, {Type: typeBool}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			expectEmit(tracer, inputs, nil)
+			return nil, nil
+		},
+	)
+
+	// ExpectEmit: Expects the next event emitted by the caller to be emitted by the provided address before it exits
+	contract.addMethod(
+		"expectEmit", abi.Arguments{{Type: typeBool}, {Type: typeBool}, {Type: typeBool}, {Type: typeBool}, {Type: typeAddress}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			emitter := inputs[4].(common.Address)
+			expectEmit(tracer, inputs, &emitter)
+			return nil, nil
+		},
+	)
+
+	// expectCall sets an expectation that the caller makes a call to the provided address with call data beginning
+	// with the provided data, before it exits.
+	expectCall := func(tracer *cheatCodeTracer, callee common.Address, data []byte, value *big.Int) {
+		// Obtain the caller frame. This is a pre-compile, so we want to set the expectation on the frame which called us.
+		cheatCodeCallerFrame := tracer.PreviousCallFrame()
+		cheatCodeCallerFrame.expectedCalls = append(cheatCodeCallerFrame.expectedCalls, &cheatCodeExpectedCall{
+			callee:    callee,
+			data:      data,
+			value:     value,
+			callIndex: len(cheatCodeCallerFrame.calls),
+		})
+		tracer.recordingExpectations = true
+	}
+
+	// ExpectCall: Expects the caller to call the provided address with the provided call data before it exits
+	contract.addMethod(
+		"expectCall", abi.Arguments{{Type: typeAddress}, {Type: typeBytes}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			expectCall(tracer, inputs[0].(common.Address), inputs[1].([]byte), nil)
+			return nil, nil
+		},
+	)
+
+	// ExpectCall: Expects the caller to call the provided address with the provided call data and value before it
+	// exits
+	contract.addMethod(
+		"expectCall", abi.Arguments{{Type: typeAddress}, {Type: typeBytes}, {Type: typeUint256}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			expectCall(tracer, inputs[0].(common.Address), inputs[1].([]byte), inputs[2].(*big.Int))
+			return nil, nil
+		},
+	)
+
+	// mockCall mocks the result of calls to the provided address whose call data begins with the provided data, and
+	// are made with the provided value, if any.
+	mockCall := func(tracer *cheatCodeTracer, callee common.Address, data []byte, value *big.Int, returnData []byte, revert bool) *cheatCodeRawReturnData {
+		// Precompiles do not execute the code at their address, so calls to them cannot be mocked.
+		if tracer.isPrecompile(callee) {
+			name := "mockCall"
+			if revert {
+				name = "mockCallRevert"
+			}
+			return cheatCodeRevertData([]byte(fmt.Sprintf("%v: cannot mock calls to precompile %v", name, callee.String())))
+		}
+
+		// Solidity verifies code exists at the callee prior to external calls, so we add code to accounts which have
+		// none, as they may not have been deployed.
+		if len(tracer.chain.State().GetCode(callee)) == 0 {
+			tracer.chain.State().SetCode(callee, []byte{byte(vm.STOP)})
+		}
+
+		// Maintain our mock unless this code path reverts or the whole transaction is reverted in the chain.
+		tracer.addMockedCall(tracer.CurrentCallFrame(), &cheatCodeMockedCall{
+			callee:     callee,
+			data:       data,
+			value:      value,
+			returnData: returnData,
+			revert:     revert,
+		})
+		return nil
+	}
+
+	// MockCall: Mocks the data returned by calls to an address with call data beginning with the provided data.
+	contract.addMethod(
+		"mockCall", abi.Arguments{{Type: typeAddress}, {Type: typeBytes}, {Type: typeBytes}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return nil, mockCall(tracer, inputs[0].(common.Address), inputs[1].([]byte), nil, inputs[2].([]byte), false)
+		},
+	)
+
+	// MockCall: Mocks the data returned by calls to an address with the provided value and call data beginning with
+	// the provided data.
+	contract.addMethod(
+		"mockCall", abi.Arguments{{Type: typeAddress}, {Type: typeUint256}, {Type: typeBytes}, {Type: typeBytes}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return nil, mockCall(tracer, inputs[0].(common.Address), inputs[2].([]byte), inputs[1].(*big.Int), inputs[3].([]byte), false)
+		},
+	)
+
+	// MockCallRevert: Mocks the data reverted with by calls to an address with call data beginning with the provided
+	// data.
+	contract.addMethod(
+		"mockCallRevert", abi.Arguments{{Type: typeAddress}, {Type: typeBytes}, {Type: typeBytes}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return nil, mockCall(tracer, inputs[0].(common.Address), inputs[1].([]byte), nil, inputs[2].([]byte), true)
+		},
+	)
+
+	// MockCallRevert: Mocks the data reverted with by calls to an address with the provided value and call data
+	// beginning with the provided data.
+	contract.addMethod(
+		"mockCallRevert", abi.Arguments{{Type: typeAddress}, {Type: typeUint256}, {Type: typeBytes}, {Type: typeBytes}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return nil, mockCall(tracer, inputs[0].(common.Address), inputs[2].([]byte), inputs[1].(*big.Int), inputs[3].([]byte), true)
+		},
+	)
+
+	// ClearMockedCalls: Removes all mocked calls.
+	contract.addMethod(
+		"clearMockedCalls", abi.Arguments{}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			// Maintain our changes unless this code path reverts or the whole transaction is reverted in the chain.
+			tracer.clearMockedCalls(tracer.CurrentCallFrame())
+			return nil, nil
+		},
+	)
+
+	// Assume: Discards the current input if a condition is not met, by reverting the transaction.
+	contract.addMethod(
+		"assume", abi.Arguments{{Type: typeBool}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			if inputs[0].(bool) {
+				return nil, nil
+			}
+
+			// Mark our call frame as failing due to the assumption, so the fuzzer can discard the transaction if the
+			// failure causes it to revert.
+			tracer.CurrentCallFrame().assumptionViolated = true
+			return nil, cheatCodeRevertData([]byte("assume: assumption was not met"))
+		},
+	)
+
+	// cover: Records that a named milestone was reached, which the fuzzer treats as coverage. Milestones reached by
+	// calls which revert are discarded.
+	contract.addMethod(
+		"cover", abi.Arguments{{Type: typeString}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			cheatCodeCallFrame := tracer.CurrentCallFrame()
+			cheatCodeCallFrame.milestones = append(cheatCodeCallFrame.milestones, inputs[0].(string))
+			return nil, nil
+		},
+	)
+
+	// PauseGasMetering: Pauses gas metering, so call frames do not consume gas until resumeGasMetering is called.
+	contract.addMethod(
+		"pauseGasMetering", abi.Arguments{}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			tracer.gasMeteringPaused = true
+			return nil, nil
+		},
+	)
+
+	// ResumeGasMetering: Resumes gas metering paused by pauseGasMetering.
+	contract.addMethod(
+		"resumeGasMetering", abi.Arguments{}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			tracer.gasMeteringPaused = false
+			return nil, nil
+		},
+	)
+
+	// StartSnapshotGas: Starts measuring the gas used by the caller, to be reported under the provided label.
+	contract.addMethod(
+		"startSnapshotGas", abi.Arguments{{Type: typeString}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			if !tracer.startGasSnapshot(tracer.PreviousCallFrame(), inputs[0].(string)) {
+				return nil, cheatCodeRevertData([]byte("startSnapshotGas: a gas snapshot is already in progress, stopSnapshotGas must be called first"))
+			}
+			return nil, nil
+		},
+	)
+
+	// StopSnapshotGas: Stops measuring the gas used by the caller, returning the gas used since startSnapshotGas.
+	contract.addMethod(
+		"stopSnapshotGas", abi.Arguments{}, abi.Arguments{{Type: typeUint256}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			gasUsed, ok := tracer.stopGasSnapshot(tracer.PreviousCallFrame())
+			if !ok {
+				return nil, cheatCodeRevertData([]byte("stopSnapshotGas: no gas snapshot is in progress"))
+			}
+			return []any{new(big.Int).SetUint64(gasUsed)}, nil
+		},
+	)
+
+	// addToDictionary: Adds values to the fuzzer's value dictionary, so they can be used as inputs in later calls. Values
+	// are added even if the call which added them reverts, as they remain of interest to the fuzzer.
+	for _, dictionaryType := range []abi.Type{typeUint256, typeInt256, typeAddress, typeBytes32, typeBytes, typeString} {
+		contract.addMethod(
+			"addToDictionary", abi.Arguments{{Type: dictionaryType}}, abi.Arguments{},
+			func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+				value := inputs[0]
+				if b, ok := value.([32]byte); ok {
+					value = b[:]
+				}
+				tracer.results.dictionaryValues = append(tracer.results.dictionaryValues, value)
+				return nil, nil
+			},
+		)
+	}
+
+	// Record: Starts recording the storage slots read and written by each account.
+	contract.addMethod(
+		"record", abi.Arguments{}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			tracer.storageAccesses = make(map[common.Address]*cheatCodeStorageAccesses)
+			return nil, nil
+		},
+	)
+
+	// Accesses: Returns the storage slots read and written by an account since recording started.
+	contract.addMethod(
+		"accesses", abi.Arguments{{Type: typeAddress}}, abi.Arguments{{Type: typeBytes32Slice}, {Type: typeBytes32Slice}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			reads, writes := make([][32]byte, 0), make([][32]byte, 0)
+			if accesses, ok := tracer.storageAccesses[inputs[0].(common.Address)]; ok {
+				reads = append(reads, accesses.reads...)
+				writes = append(writes, accesses.writes...)
+			}
+			return []any{reads, writes}, nil
+		},
+	)
+
+	// StartStateDiffRecording: Starts recording the accounts and storage slots accessed.
+	contract.addMethod(
+		"startStateDiffRecording", abi.Arguments{}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			// Discard any accesses recorded for the current call frames by a previous recording.
+			for _, callFrame := range tracer.callFrames {
+				callFrame.accountAccess = nil
+			}
+			tracer.accountAccesses = make([]*cheatCodeAccountAccess, 0)
+			return nil, nil
+		},
+	)
+
+	// StopAndReturnStateDiff: Stops recording the accounts and storage slots accessed, and returns them.
+	contract.addMethod(
+		"stopAndReturnStateDiff", abi.Arguments{}, abi.Arguments{{Type: typeAccountAccessSlice}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			accountAccesses := make([]cheatCodeAccountAccess, len(tracer.accountAccesses))
+			for i, accountAccess := range tracer.accountAccesses {
+				accountAccesses[i] = *accountAccess
+			}
+			tracer.accountAccesses = nil
+			return []any{accountAccesses}, nil
+		},
+	)
+
+	// RecordLogs: Starts recording the logs emitted, discarding any which were previously recorded.
+	contract.addMethod(
+		"recordLogs", abi.Arguments{}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			tracer.takeRecordedLogs()
+			tracer.recordingLogs = true
+			return nil, nil
+		},
+	)
+
+	// GetRecordedLogs: Returns the logs emitted since recording started, or since they were last returned.
+	contract.addMethod(
+		"getRecordedLogs", abi.Arguments{}, abi.Arguments{{Type: typeLogSlice}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return []any{tracer.takeRecordedLogs()}, nil
+		},
+	)
+
+	// GetCode: Returns the init bytecode of a contract from the compilation artifacts.
+	contract.addMethod(
+		"getCode", abi.Arguments{{Type: typeString}}, abi.Arguments{{Type: typeBytes}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			artifact, err := tracer.chain.ContractArtifact(inputs[0].(string))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("getCode: " + err.Error()))
+			}
+			return []any{artifact.CompiledContract.InitBytecode}, nil
+		},
+	)
+
+	// GetDeployedCode: Returns the runtime bytecode of a contract from the compilation artifacts.
+	contract.addMethod(
+		"getDeployedCode", abi.Arguments{{Type: typeString}}, abi.Arguments{{Type: typeBytes}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			artifact, err := tracer.chain.ContractArtifact(inputs[0].(string))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("getDeployedCode: " + err.Error()))
+			}
+			return []any{artifact.CompiledContract.RuntimeBytecode}, nil
+		},
+	)
+
+	// DeployCode: Deploys a contract from the compilation artifacts.
+	contract.addMethod(
+		"deployCode", abi.Arguments{{Type: typeString}}, abi.Arguments{{Type: typeAddress}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			address, err := tracer.deployContractArtifact(tracer.PreviousCallFrame(), inputs[0].(string), nil)
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("deployCode: " + err.Error()))
+			}
+			return []any{address}, nil
+		},
+	)
+
+	// DeployCode: Deploys a contract from the compilation artifacts with the provided ABI-encoded constructor arguments.
+	contract.addMethod(
+		"deployCode", abi.Arguments{{Type: typeString}, {Type: typeBytes}}, abi.Arguments{{Type: typeAddress}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			address, err := tracer.deployContractArtifact(tracer.PreviousCallFrame(), inputs[0].(string), inputs[1].([]byte))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("deployCode: " + err.Error()))
+			}
+			return []any{address}, nil
+		},
+	)
+
 	// snapshot: Takes a snapshot of the current state of the evm and returns the id associated with the snapshot
 	contract.addMethod(
 		"snapshot", abi.Arguments{}, abi.Arguments{{Type: typeUint256}},
@@ -330,7 +912,13 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 				args = cmdAndInputs[1:]
 			}
 
+			// Ensure the command is permitted by the cheat code configuration before executing it.
+			if !tracer.isFFICommandAllowed(cmdAndInputs) {
+				return nil, tracer.denyCheatCodeCall(fmt.Sprintf("ffi: command %v is not permitted by the cheat code configuration", command))
+			}
+
 			// Create our command
+			tracer.externalInputsRead = true
 			cmd := exec.Command(command, args...)
 
 			// Execute it and grab the output
@@ -352,6 +940,133 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 		},
 	)
 
+	// ProjectRoot: Returns the root directory of the project.
+	contract.addMethod(
+		"projectRoot", abi.Arguments{}, abi.Arguments{{Type: typeString}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			tracer.externalInputsRead = true
+			projectRoot, err := os.Getwd()
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("projectRoot: %v", err)))
+			}
+			return []any{projectRoot}, nil
+		},
+	)
+
+	// ReadFile: Returns the contents of a file as a string.
+	contract.addMethod(
+		"readFile", abi.Arguments{{Type: typeString}}, abi.Arguments{{Type: typeString}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			tracer.externalInputsRead = true
+			path, err := tracer.resolveCheatCodePath(inputs[0].(string), false)
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("readFile: %v", err)))
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("readFile: %v", err)))
+			}
+			return []any{string(data)}, nil
+		},
+	)
+
+	// ReadFileBinary: Returns the contents of a file as bytes.
+	contract.addMethod(
+		"readFileBinary", abi.Arguments{{Type: typeString}}, abi.Arguments{{Type: typeBytes}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			tracer.externalInputsRead = true
+			path, err := tracer.resolveCheatCodePath(inputs[0].(string), false)
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("readFileBinary: %v", err)))
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("readFileBinary: %v", err)))
+			}
+			return []any{data}, nil
+		},
+	)
+
+	// WriteFile: Writes a string to a file, creating it and its parent directories if they do not exist.
+	contract.addMethod(
+		"writeFile", abi.Arguments{{Type: typeString}, {Type: typeString}}, abi.Arguments{},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			path, err := tracer.resolveCheatCodePath(inputs[0].(string), true)
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("writeFile: %v", err)))
+			}
+			err = os.MkdirAll(filepath.Dir(path), 0755)
+			if err == nil {
+				err = os.WriteFile(path, []byte(inputs[1].(string)), 0644)
+			}
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("writeFile: %v", err)))
+			}
+			return nil, nil
+		},
+	)
+
+	// Exists: Returns whether a file or directory exists.
+	contract.addMethod(
+		"exists", abi.Arguments{{Type: typeString}}, abi.Arguments{{Type: typeBool}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			tracer.externalInputsRead = true
+			path, err := tracer.resolveCheatCodePath(inputs[0].(string), false)
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("exists: %v", err)))
+			}
+			_, err = os.Stat(path)
+			return []any{err == nil}, nil
+		},
+	)
+
+	// Env*: Returns the value of an environment variable, parsed as a given type. EnvOr returns a default value instead
+	// if the environment variable is not set.
+	envTypes := []struct {
+		name string
+		typ  abi.Type
+	}{
+		{"envUint", typeUint256},
+		{"envInt", typeInt256},
+		{"envAddress", typeAddress},
+		{"envBool", typeBool},
+		{"envBytes32", typeBytes32},
+		{"envString", typeString},
+	}
+	for _, envType := range envTypes {
+		name, typ := envType.name, envType.typ
+		contract.addMethod(
+			name, abi.Arguments{{Type: typeString}}, abi.Arguments{{Type: typ}},
+			func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+				tracer.externalInputsRead = true
+				value, ok := os.LookupEnv(inputs[0].(string))
+				if !ok {
+					return nil, cheatCodeRevertData([]byte(fmt.Sprintf("%s: environment variable '%s' not found", name, inputs[0].(string))))
+				}
+				parsedValue, err := parseCheatCodeStringValue(value, typ)
+				if err != nil {
+					return nil, cheatCodeRevertData([]byte(fmt.Sprintf("%s: failed to parse environment variable '%s': %v", name, inputs[0].(string), err)))
+				}
+				return []any{parsedValue}, nil
+			},
+		)
+		contract.addMethod(
+			"envOr", abi.Arguments{{Type: typeString}, {Type: typ}}, abi.Arguments{{Type: typ}},
+			func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+				tracer.externalInputsRead = true
+				value, ok := os.LookupEnv(inputs[0].(string))
+				if !ok {
+					return []any{inputs[1]}, nil
+				}
+				parsedValue, err := parseCheatCodeStringValue(value, typ)
+				if err != nil {
+					return nil, cheatCodeRevertData([]byte(fmt.Sprintf("envOr: failed to parse environment variable '%s': %v", inputs[0].(string), err)))
+				}
+				return []any{parsedValue}, nil
+			},
+		)
+	}
+
 	// addr: Compute the address for a given private key
 	contract.addMethod("addr", abi.Arguments{{Type: typeUint256}}, abi.Arguments{{Type: typeAddress}},
 		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
@@ -384,22 +1099,129 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 			}
 
 			// Sign digest
-			digest := inputs[1].([32]byte)
-			sig, err := crypto.Sign(digest[:], privateKey)
+			v, r, s, err := signDigest(privateKey, inputs[1].([32]byte))
 			if err != nil {
 				return nil, cheatCodeRevertData([]byte("sign: malformed input to signature algorithm"))
 			}
+			return []any{v, r, s}, nil
+		},
+	)
 
-			// `r` and `s` have to be [32]byte arrays
-			var r [32]byte
-			var s [32]byte
-			copy(r[:], sig[:32])
-			copy(s[:], sig[32:64])
+	// sign: Sign a digest with a key remembered by the rememberKey cheat code
+	contract.addMethod("sign", abi.Arguments{{Type: typeAddress}, {Type: typeBytes32}},
+		abi.Arguments{{Type: typeUint8}, {Type: typeBytes32}, {Type: typeBytes32}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			privateKey, ok := tracer.rememberedKeys[inputs[0].(common.Address)]
+			if !ok {
+				return nil, cheatCodeRevertData([]byte("sign: no key was remembered for the signer address"))
+			}
+			v, r, s, err := signDigest(privateKey, inputs[1].([32]byte))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("sign: malformed input to signature algorithm"))
+			}
+			return []any{v, r, s}, nil
+		},
+	)
 
-			// Need to add 27 to the `v` value for ecrecover to work
-			v := sig[64] + 27
+	// signCompact: Sign a digest given some private key, returning an EIP-2098 compact signature
+	contract.addMethod("signCompact", abi.Arguments{{Type: typeUint256}, {Type: typeBytes32}},
+		abi.Arguments{{Type: typeBytes32}, {Type: typeBytes32}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			privateKey, err := utils.GetPrivateKey(inputs[0].(*big.Int).Bytes())
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("signCompact: " + err.Error()))
+			}
+			r, vs, err := signDigestCompact(privateKey, inputs[1].([32]byte))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("signCompact: malformed input to signature algorithm"))
+			}
+			return []any{r, vs}, nil
+		},
+	)
 
-			return []any{v, r, s}, nil
+	// signCompact: Sign a digest with a key remembered by the rememberKey cheat code, returning an EIP-2098 compact
+	// signature
+	contract.addMethod("signCompact", abi.Arguments{{Type: typeAddress}, {Type: typeBytes32}},
+		abi.Arguments{{Type: typeBytes32}, {Type: typeBytes32}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			privateKey, ok := tracer.rememberedKeys[inputs[0].(common.Address)]
+			if !ok {
+				return nil, cheatCodeRevertData([]byte("signCompact: no key was remembered for the signer address"))
+			}
+			r, vs, err := signDigestCompact(privateKey, inputs[1].([32]byte))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("signCompact: malformed input to signature algorithm"))
+			}
+			return []any{r, vs}, nil
+		},
+	)
+
+	// deriveKey: Derive a private key from a mnemonic, at the given index of the default derivation path
+	contract.addMethod("deriveKey", abi.Arguments{{Type: typeString}, {Type: typeUint32}}, abi.Arguments{{Type: typeUint256}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			derivationPath := fmt.Sprintf("%s%d", cheatCodeDefaultDerivationPath, inputs[1].(uint32))
+			privateKey, err := deriveMnemonicKey(inputs[0].(string), derivationPath)
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("deriveKey: " + err.Error()))
+			}
+			return []any{privateKey.D}, nil
+		},
+	)
+
+	// deriveKey: Derive a private key from a mnemonic, at the given index of the provided derivation path
+	contract.addMethod("deriveKey", abi.Arguments{{Type: typeString}, {Type: typeString}, {Type: typeUint32}},
+		abi.Arguments{{Type: typeUint256}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			derivationPath := fmt.Sprintf("%s/%d", strings.TrimSuffix(inputs[1].(string), "/"), inputs[2].(uint32))
+			privateKey, err := deriveMnemonicKey(inputs[0].(string), derivationPath)
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("deriveKey: " + err.Error()))
+			}
+			return []any{privateKey.D}, nil
+		},
+	)
+
+	// rememberKey: Remember a private key, so digests can be signed with it by its address
+	contract.addMethod("rememberKey", abi.Arguments{{Type: typeUint256}}, abi.Arguments{{Type: typeAddress}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			privateKey, err := utils.GetPrivateKey(inputs[0].(*big.Int).Bytes())
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("rememberKey: " + err.Error()))
+			}
+			return []any{tracer.rememberKey(tracer.CurrentCallFrame(), privateKey)}, nil
+		},
+	)
+
+	// createWallet: Create a wallet whose private key is derived from the hash of a label
+	contract.addMethod("createWallet", abi.Arguments{{Type: typeString}}, abi.Arguments{{Type: typeWallet}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			privateKey, err := crypto.ToECDSA(crypto.Keccak256([]byte(inputs[0].(string))))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("createWallet: " + err.Error()))
+			}
+			return []any{newCheatCodeWallet(privateKey)}, nil
+		},
+	)
+
+	// createWallet: Create a wallet from a private key
+	contract.addMethod("createWallet", abi.Arguments{{Type: typeUint256}}, abi.Arguments{{Type: typeWallet}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			privateKey, err := utils.GetPrivateKey(inputs[0].(*big.Int).Bytes())
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("createWallet: " + err.Error()))
+			}
+			return []any{newCheatCodeWallet(privateKey)}, nil
+		},
+	)
+
+	// eip712HashTypedData: Compute the EIP-712 digest of typed data described in JSON
+	contract.addMethod("eip712HashTypedData", abi.Arguments{{Type: typeString}}, abi.Arguments{{Type: typeBytes32}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			digest, err := hashTypedData(inputs[0].(string))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte("eip712HashTypedData: " + err.Error()))
+			}
+			return []any{digest}, nil
 		},
 	)
 
@@ -525,6 +1347,487 @@ func getStandardCheatCodeContract(tracer *cheatCodeTracer) (*CheatCodeContract,
 		},
 	)
 
+	// parseJson: Returns the ABI-encoded value of a JSON string, or of the value at a key within it, inferring its type.
+	parseJson := func(tracer *cheatCodeTracer, data string, key string) ([]any, *cheatCodeRawReturnData) {
+		value, err := selectJsonValue(data, key)
+		if err != nil {
+			return nil, cheatCodeRevertData([]byte(fmt.Sprintf("parseJson: %v", err)))
+		}
+		encodedValue, err := encodeJsonValue(value)
+		if err != nil {
+			return nil, cheatCodeRevertData([]byte(fmt.Sprintf("parseJson: %v", err)))
+		}
+		return []any{encodedValue}, nil
+	}
+	contract.addMethod("parseJson", abi.Arguments{{Type: typeString}}, abi.Arguments{{Type: typeBytes}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return parseJson(tracer, inputs[0].(string), "$")
+		},
+	)
+	contract.addMethod("parseJson", abi.Arguments{{Type: typeString}, {Type: typeString}}, abi.Arguments{{Type: typeBytes}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			return parseJson(tracer, inputs[0].(string), inputs[1].(string))
+		},
+	)
+
+	// keyExists: Returns whether a key exists within a JSON string.
+	contract.addMethod("keyExists", abi.Arguments{{Type: typeString}, {Type: typeString}}, abi.Arguments{{Type: typeBool}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			root, err := decodeJson(inputs[0].(string))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("keyExists: %v", err)))
+			}
+			values, _, err := selectJsonValues(root, inputs[1].(string))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("keyExists: %v", err)))
+			}
+			return []any{len(values) > 0}, nil
+		},
+	)
+
+	// parseJson* and serialize*: Parse the value at a key within a JSON string as a given type, or add a value of a
+	// given type to a JSON object, returning the serialized object.
+	jsonTypes := []struct {
+		name      string
+		typ       abi.Type
+		arrayType abi.Type
+	}{
+		{"Uint", typeUint256, typeUint256Slice},
+		{"Int", typeInt256, typeInt256Slice},
+		{"Address", typeAddress, typeAddressSlice},
+		{"Bool", typeBool, typeBoolSlice},
+		{"Bytes32", typeBytes32, typeBytes32Slice},
+		{"Bytes", typeBytes, typeBytesSlice},
+		{"String", typeString, typeStringSlice},
+	}
+	for _, jsonType := range jsonTypes {
+		parseName, serializeName, typ, arrayType := "parseJson"+jsonType.name, "serialize"+jsonType.name, jsonType.typ, jsonType.arrayType
+		contract.addMethod(parseName, abi.Arguments{{Type: typeString}, {Type: typeString}}, abi.Arguments{{Type: typ}},
+			func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+				value, err := selectJsonValue(inputs[0].(string), inputs[1].(string))
+				if err == nil {
+					value, err = parseJsonValue(value, typ)
+				}
+				if err != nil {
+					return nil, cheatCodeRevertData([]byte(fmt.Sprintf("%s: %v", parseName, err)))
+				}
+				return []any{value}, nil
+			},
+		)
+		contract.addMethod(parseName+"Array", abi.Arguments{{Type: typeString}, {Type: typeString}}, abi.Arguments{{Type: arrayType}},
+			func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+				value, err := selectJsonValue(inputs[0].(string), inputs[1].(string))
+				if err == nil {
+					value, err = parseJsonValue(value, arrayType)
+				}
+				if err != nil {
+					return nil, cheatCodeRevertData([]byte(fmt.Sprintf("%sArray: %v", parseName, err)))
+				}
+				return []any{value}, nil
+			},
+		)
+		for _, valueType := range []abi.Type{typ, arrayType} {
+			contract.addMethod(serializeName, abi.Arguments{{Type: typeString}, {Type: typeString}, {Type: valueType}}, abi.Arguments{{Type: typeString}},
+				func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+					serializedObject, err := tracer.serializeJsonValue(inputs[0].(string), inputs[1].(string), inputs[2])
+					if err != nil {
+						return nil, cheatCodeRevertData([]byte(fmt.Sprintf("%s: %v", serializeName, err)))
+					}
+					return []any{serializedObject}, nil
+				},
+			)
+		}
+	}
+
+	// serializeJson: Replaces a JSON object with the provided JSON object string, returning the serialized object.
+	contract.addMethod("serializeJson", abi.Arguments{{Type: typeString}, {Type: typeString}}, abi.Arguments{{Type: typeString}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			serializedObject, err := tracer.serializeJsonObject(inputs[0].(string), inputs[1].(string))
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("serializeJson: %v", err)))
+			}
+			return []any{serializedObject}, nil
+		},
+	)
+
+	// crossChainCall: Calls a contract on a chain linked to the current one, returning whether the call succeeded and
+	// its return data. Any state changes made by the call are discarded.
+	contract.addMethod("crossChainCall", abi.Arguments{{Type: typeString}, {Type: typeAddress}, {Type: typeBytes}}, abi.Arguments{{Type: typeBool}, {Type: typeBytes}},
+		func(tracer *cheatCodeTracer, inputs []any) ([]any, *cheatCodeRawReturnData) {
+			// Resolve the chain to call, which must be a different chain linked to our own.
+			linkedChain := tracer.chain.LinkedChain(inputs[0].(string))
+			if linkedChain == nil || linkedChain == tracer.chain {
+				return nil, cheatCodeRevertData([]byte("crossChainCall: unknown chain"))
+			}
+
+			// Call the target from the contract which called us, with no value or gas fees.
+			sender := tracer.PreviousCallFrame().vmScope.Address()
+			target := inputs[1].(common.Address)
+			msg := &core.Message{
+				From:              sender,
+				To:                &target,
+				Nonce:             linkedChain.State().GetNonce(sender),
+				Value:             big.NewInt(0),
+				GasLimit:          linkedChain.BlockGasLimit,
+				GasPrice:          big.NewInt(0),
+				GasFeeCap:         big.NewInt(0),
+				GasTipCap:         big.NewInt(0),
+				Data:              inputs[2].([]byte),
+				SkipAccountChecks: true,
+			}
+			result, err := linkedChain.CallContract(msg, nil)
+			if err != nil {
+				return nil, cheatCodeRevertData([]byte(fmt.Sprintf("crossChainCall: %v", err)))
+			}
+			return []any{!result.Failed(), result.ReturnData}, nil
+		},
+	)
+
 	// Return our precompile contract information.
 	return contract, nil
 }
+
+// decodeJson decodes the provided JSON string, preserving the precision of any numbers in it as json.Number values.
+// Returns the decoded value, or an error if the string is not valid JSON.
+func decodeJson(data string) (any, error) {
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %v", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, errors.New("invalid JSON: unexpected data after top-level value")
+	}
+	return value, nil
+}
+
+// selectJsonValues selects the values within the provided decoded JSON value at the provided JSONPath-style key. Keys
+// begin at the root ("$" or "."), followed by any number of object members (".name" or "['name']"), array elements
+// ("[0]"), or wildcards matching every member or element (".*" or "[*]").
+// Returns the values selected, a boolean indicating whether the key contained wildcards, or an error if the key is
+// invalid.
+func selectJsonValues(root any, key string) ([]any, bool, error) {
+	values := []any{root}
+	wildcard := false
+	path := strings.TrimPrefix(key, "$")
+	for path != "" {
+		// Parse the next selector of our key.
+		var member string
+		index := -1
+		all := false
+		if strings.HasPrefix(path, ".") {
+			end := strings.IndexAny(path[1:], ".[") + 1
+			if end == 0 {
+				end = len(path)
+			}
+			member, path = path[1:end], path[end:]
+			if member == "" && path != "" {
+				return nil, false, fmt.Errorf("invalid key '%s'", key)
+			} else if member == "" {
+				break
+			}
+			all = member == "*"
+		} else if strings.HasPrefix(path, "[") {
+			end := strings.Index(path, "]")
+			if end < 0 {
+				return nil, false, fmt.Errorf("invalid key '%s'", key)
+			}
+			selector := path[1:end]
+			path = path[end+1:]
+			if selector == "*" {
+				all = true
+			} else if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				member = selector[1 : len(selector)-1]
+			} else if parsedIndex, err := strconv.Atoi(selector); err == nil && parsedIndex >= 0 {
+				index = parsedIndex
+			} else {
+				return nil, false, fmt.Errorf("invalid key '%s'", key)
+			}
+		} else {
+			return nil, false, fmt.Errorf("invalid key '%s'", key)
+		}
+		wildcard = wildcard || all
+
+		// Select the values matching our selector within each of our current values.
+		selectedValues := make([]any, 0)
+		for _, value := range values {
+			switch v := value.(type) {
+			case map[string]any:
+				if all {
+					for _, k := range sortedJsonKeys(v) {
+						selectedValues = append(selectedValues, v[k])
+					}
+				} else if memberValue, ok := v[member]; ok && index < 0 {
+					selectedValues = append(selectedValues, memberValue)
+				}
+			case []any:
+				if all {
+					selectedValues = append(selectedValues, v...)
+				} else if index >= 0 && index < len(v) {
+					selectedValues = append(selectedValues, v[index])
+				}
+			}
+		}
+		values = selectedValues
+	}
+	return values, wildcard, nil
+}
+
+// selectJsonValue selects the value within the provided JSON string at the provided key, as described by
+// selectJsonValues. If the key contains wildcards, the values selected are returned as an array.
+// Returns the value selected, or an error if the JSON string or key is invalid, or no value exists at the key.
+func selectJsonValue(data string, key string) (any, error) {
+	root, err := decodeJson(data)
+	if err != nil {
+		return nil, err
+	}
+	values, wildcard, err := selectJsonValues(root, key)
+	if err != nil {
+		return nil, err
+	}
+	if wildcard {
+		return values, nil
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("key '%s' was not found", key)
+	}
+	return values[0], nil
+}
+
+// sortedJsonKeys obtains the keys of the provided JSON object, sorted alphabetically.
+func sortedJsonKeys(object map[string]any) []string {
+	keys := maps.Keys(object)
+	slices.Sort(keys)
+	return keys
+}
+
+// parseJsonValue converts the provided decoded JSON value to a value of the provided ABI type. Strings are parsed as
+// described by parseCheatCodeStringValue, arrays are converted element-wise, and objects are converted to tuples with
+// their members sorted alphabetically by key.
+// Returns the converted value, or an error if the value could not be converted to the provided type.
+func parseJsonValue(value any, typ abi.Type) (any, error) {
+	switch typ.T {
+	case abi.SliceTy:
+		elements, ok := value.([]any)
+		if !ok {
+			return nil, fmt.Errorf("%v cannot be parsed as type %s", value, typ)
+		}
+		result := reflect.MakeSlice(typ.GetType(), len(elements), len(elements))
+		for i, element := range elements {
+			parsedElement, err := parseJsonValue(element, *typ.Elem)
+			if err != nil {
+				return nil, err
+			}
+			result.Index(i).Set(reflect.ValueOf(parsedElement))
+		}
+		return result.Interface(), nil
+	case abi.TupleTy:
+		object, ok := value.(map[string]any)
+		if !ok || len(object) != len(typ.TupleElems) {
+			return nil, fmt.Errorf("%v cannot be parsed as type %s", value, typ)
+		}
+		result := reflect.New(typ.GetType()).Elem()
+		for i, key := range sortedJsonKeys(object) {
+			parsedMember, err := parseJsonValue(object[key], *typ.TupleElems[i])
+			if err != nil {
+				return nil, err
+			}
+			result.Field(i).Set(reflect.ValueOf(parsedMember))
+		}
+		return result.Interface(), nil
+	}
+
+	switch v := value.(type) {
+	case string:
+		return parseCheatCodeStringValue(v, typ)
+	case json.Number:
+		if typ.T == abi.UintTy || typ.T == abi.IntTy {
+			n, err := parseJsonInteger(v)
+			if err != nil {
+				return nil, err
+			}
+			return parseCheatCodeStringValue(n.String(), typ)
+		}
+	case bool:
+		if typ.T == abi.BoolTy {
+			return v, nil
+		}
+	}
+	return nil, fmt.Errorf("%v cannot be parsed as type %s", value, typ)
+}
+
+// parseJsonInteger parses the provided JSON number as an integer, including numbers in scientific notation (e.g. 1e18).
+// Returns the integer, or an error if the number is not an integer.
+func parseJsonInteger(number json.Number) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(number.String())
+	if !ok || !r.IsInt() {
+		return nil, fmt.Errorf("%v is not an integer", number)
+	}
+	return r.Num(), nil
+}
+
+// inferJsonAbiType infers the ABI type which the provided decoded JSON value is encoded as by the parseJson cheat code.
+// Numbers are encoded as uint256 (or int256 if negative), and hex strings as address, bytes32 or bytes, depending on
+// their length. Arrays are encoded as dynamic arrays of the type of their elements, which must all have the same type,
+// and objects as tuples with their members sorted alphabetically by key.
+// Returns the ABI type, or an error if the value cannot be encoded.
+func inferJsonAbiType(value any) (abi.ArgumentMarshaling, error) {
+	switch v := value.(type) {
+	case bool:
+		return abi.ArgumentMarshaling{Type: "bool"}, nil
+	case json.Number:
+		n, err := parseJsonInteger(v)
+		if err != nil {
+			return abi.ArgumentMarshaling{}, err
+		}
+		if n.Sign() < 0 {
+			return abi.ArgumentMarshaling{Type: "int256"}, nil
+		}
+		return abi.ArgumentMarshaling{Type: "uint256"}, nil
+	case string:
+		if b, err := hexutil.Decode(v); err == nil {
+			switch len(b) {
+			case common.AddressLength:
+				return abi.ArgumentMarshaling{Type: "address"}, nil
+			case common.HashLength:
+				return abi.ArgumentMarshaling{Type: "bytes32"}, nil
+			default:
+				return abi.ArgumentMarshaling{Type: "bytes"}, nil
+			}
+		}
+		return abi.ArgumentMarshaling{Type: "string"}, nil
+	case []any:
+		// The encoding of an empty array does not depend on the type of its elements.
+		if len(v) == 0 {
+			return abi.ArgumentMarshaling{Type: "uint256[]"}, nil
+		}
+		elementType, err := inferJsonAbiType(v[0])
+		if err != nil {
+			return abi.ArgumentMarshaling{}, err
+		}
+		for _, element := range v[1:] {
+			otherElementType, err := inferJsonAbiType(element)
+			if err != nil {
+				return abi.ArgumentMarshaling{}, err
+			}
+			if !reflect.DeepEqual(elementType, otherElementType) {
+				return abi.ArgumentMarshaling{}, errors.New("array elements must all have the same type")
+			}
+		}
+		return abi.ArgumentMarshaling{Type: elementType.Type + "[]", Components: elementType.Components}, nil
+	case map[string]any:
+		components := make([]abi.ArgumentMarshaling, 0, len(v))
+		for i, key := range sortedJsonKeys(v) {
+			memberType, err := inferJsonAbiType(v[key])
+			if err != nil {
+				return abi.ArgumentMarshaling{}, err
+			}
+			memberType.Name = fmt.Sprintf("member%d", i)
+			components = append(components, memberType)
+		}
+		return abi.ArgumentMarshaling{Type: "tuple", Components: components}, nil
+	default:
+		return abi.ArgumentMarshaling{}, fmt.Errorf("%v cannot be encoded", value)
+	}
+}
+
+// encodeJsonValue ABI-encodes the provided decoded JSON value, as the type inferred for it by inferJsonAbiType.
+// Returns the ABI-encoded value, or an error if it could not be encoded.
+func encodeJsonValue(value any) ([]byte, error) {
+	marshaling, err := inferJsonAbiType(value)
+	if err != nil {
+		return nil, err
+	}
+	typ, err := abi.NewType(marshaling.Type, "", marshaling.Components)
+	if err != nil {
+		return nil, err
+	}
+	parsedValue, err := parseJsonValue(value, typ)
+	if err != nil {
+		return nil, err
+	}
+	return abi.Arguments{{Type: typ}}.Pack(parsedValue)
+}
+
+// marshalJsonValue converts a value provided to a serialize cheat code to JSON. Integers are serialized as numbers,
+// addresses and bytes as hex strings, and arrays element-wise. Strings which contain a JSON object are embedded as an
+// object, so objects can be nested.
+// Returns the JSON value, or an error if the value could not be serialized.
+func marshalJsonValue(value any) (json.RawMessage, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return json.RawMessage(v.String()), nil
+	case common.Address:
+		return json.Marshal(v.Hex())
+	case [32]byte:
+		return json.Marshal(hexutil.Encode(v[:]))
+	case []byte:
+		return json.Marshal(hexutil.Encode(v))
+	case string:
+		if strings.HasPrefix(strings.TrimSpace(v), "{") && json.Valid([]byte(v)) {
+			return json.RawMessage(v), nil
+		}
+		return json.Marshal(v)
+	case bool:
+		return json.Marshal(v)
+	}
+
+	// Serialize any arrays element-wise.
+	reflectedValue := reflect.ValueOf(value)
+	if reflectedValue.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("values of type %T cannot be serialized", value)
+	}
+	elements := make([]json.RawMessage, reflectedValue.Len())
+	for i := range elements {
+		element, err := marshalJsonValue(reflectedValue.Index(i).Interface())
+		if err != nil {
+			return nil, err
+		}
+		elements[i] = element
+	}
+	return json.Marshal(elements)
+}
+
+// serializeJsonValue adds the provided value to the JSON object with the provided object key under the provided value
+// key, creating the object if it does not exist. Objects are retained until the end of the transaction.
+// Returns the serialized object, or an error if the value could not be serialized.
+func (t *cheatCodeTracer) serializeJsonValue(objectKey string, valueKey string, value any) (string, error) {
+	jsonValue, err := marshalJsonValue(value)
+	if err != nil {
+		return "", err
+	}
+	if t.serializedJsonObjects == nil {
+		t.serializedJsonObjects = make(map[string]map[string]json.RawMessage)
+	}
+	if t.serializedJsonObjects[objectKey] == nil {
+		t.serializedJsonObjects[objectKey] = make(map[string]json.RawMessage)
+	}
+	t.serializedJsonObjects[objectKey][valueKey] = jsonValue
+
+	// Serialize our object. Its members are sorted by key.
+	serializedObject, err := json.Marshal(t.serializedJsonObjects[objectKey])
+	if err != nil {
+		return "", err
+	}
+	return string(serializedObject), nil
+}
+
+// serializeJsonObject replaces the JSON object with the provided object key with the provided JSON object string.
+// Returns the serialized object, or an error if the string is not a valid JSON object.
+func (t *cheatCodeTracer) serializeJsonObject(objectKey string, data string) (string, error) {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(data), &object); err != nil || object == nil {
+		return "", errors.New("value is not a valid JSON object")
+	}
+	if t.serializedJsonObjects == nil {
+		t.serializedJsonObjects = make(map[string]map[string]json.RawMessage)
+	}
+	t.serializedJsonObjects[objectKey] = object
+
+	serializedObject, err := json.Marshal(object)
+	if err != nil {
+		return "", err
+	}
+	return string(serializedObject), nil
+}
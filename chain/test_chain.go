@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/big"
 	"sort"
+	"sync"
 
 	"github.com/crytic/medusa/chain/config"
 	"github.com/ethereum/go-ethereum/core/rawdb"
@@ -13,6 +14,7 @@ import (
 	"github.com/ethereum/go-ethereum/triedb/hashdb"
 	"github.com/holiman/uint256"
 	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
 
 	chainTypes "github.com/crytic/medusa/chain/types"
 	"github.com/crytic/medusa/chain/vendored"
@@ -42,10 +44,20 @@ type TestChain struct {
 	// interpreter's behavior. This should be set when a new EVM is created by the test chain e.g. using vm.NewEVM.
 	pendingBlockContext *vm.BlockContext
 
+	// pendingTxContext is the vm.TxContext for the message currently being executed. This is used by cheatcodes to
+	// override the EVM interpreter's behavior. This should be set when a new EVM is created by the test chain e.g.
+	// using vm.NewEVM.
+	pendingTxContext *vm.TxContext
+
 	// pendingBlockChainConfig is params.ChainConfig for the current pending block. This is used by cheatcodes to override
 	// the chain ID. This should be set when a new EVM is created by the test chain e.g. using vm.NewEVM.
 	pendingBlockChainConfig *params.ChainConfig
 
+	// pendingEVM is the vm.EVM executing the message currently being executed. This is used by cheatcodes to execute
+	// calls within the same EVM, so they are traced alongside the message. This should be set when a new EVM is created
+	// by the test chain e.g. using vm.NewEVM.
+	pendingEVM *vm.EVM
+
 	// BlockGasLimit defines the maximum amount of gas that can be consumed by transactions in a block.
 	// Transactions which push the block gas usage beyond this limit will not be added to a block without error.
 	BlockGasLimit uint64
@@ -63,6 +75,14 @@ type TestChain struct {
 	// genesisDefinition represents the Genesis information used to generate the chain's initial state.
 	genesisDefinition *core.Genesis
 
+	// genesisContracts represents contracts which exist in the genesis state and were registered with the chain via
+	// RegisterGenesisContract, so they are announced as deployed contracts.
+	genesisContracts []*chainTypes.DeployedContractBytecode
+
+	// contractArtifacts represents compiled contracts which were registered with the chain via
+	// RegisterContractArtifact, so cheat codes can refer to them by name.
+	contractArtifacts []*ContractArtifact
+
 	// state represents the current Ethereum world state.StateDB. It tracks all state across the chain and dummyChain
 	// and is the subject of state changes when executing new transactions. This does not track the current block
 	// head or anything of that nature and simply tracks accounts, balances, code, storage, etc.
@@ -75,6 +95,10 @@ type TestChain struct {
 	// This is constructed over the kvstore.
 	db ethdb.Database
 
+	// dbLock provides thread synchronization when the state of all committed blocks is flushed to db and read from it,
+	// as occurs when the chain is cloned (possibly concurrently) or exported.
+	dbLock sync.Mutex
+
 	// callTracerRouter forwards tracers.Tracer and TestChainTracer calls to any instances added to it. This
 	// router is used for non-state changing calls.
 	callTracerRouter *TestChainTracerRouter
@@ -83,6 +107,24 @@ type TestChain struct {
 	// router is used for transaction execution when constructing blocks.
 	transactionTracerRouter *TestChainTracerRouter
 
+	// remoteStateProvider fetches remote state when the chain was created in fork mode. It is shared with any clones
+	// of this chain. This is nil if fork mode is disabled.
+	remoteStateProvider *remoteStateProvider
+
+	// cheatCodeTracer executes cheat codes and built-in precompiles. This is nil if neither are enabled.
+	cheatCodeTracer *cheatCodeTracer
+
+	// name describes the name this chain was linked to its primary chain under. This is empty if the chain is not
+	// linked to another chain.
+	name string
+
+	// primaryChain refers to the chain this chain was linked to with LinkChain. This is nil if the chain is not linked
+	// to another chain.
+	primaryChain *TestChain
+
+	// linkedChains describes a mapping of names to chains linked to this chain with LinkChain.
+	linkedChains map[string]*TestChain
+
 	// Events defines the event system for the TestChain.
 	Events TestChainEvents
 }
@@ -91,21 +133,29 @@ type TestChain struct {
 // This creates a test chain with a test chain configuration and the provided genesis allocation and config.
 // If a nil config is provided, a default one is used.
 func NewTestChain(genesisAlloc types.GenesisAlloc, testChainConfig *config.TestChainConfig) (*TestChain, error) {
-	// Copy our chain config, so it is not shared across chains.
-	chainConfig, err := utils.CopyChainConfig(params.TestChainConfig)
+	return newTestChain(genesisAlloc, testChainConfig, nil)
+}
+
+// newTestChain creates a simulated Ethereum backend used for testing, or returns an error if one occurred.
+// If the provided config enables fork mode, the provided remoteStateProvider is used to fetch remote state. If it is
+// nil, a new one is created from the config.
+func newTestChain(genesisAlloc types.GenesisAlloc, testChainConfig *config.TestChainConfig, provider *remoteStateProvider) (*TestChain, error) {
+	// Use a default config if we were not provided one
+	var err error
+	if testChainConfig == nil {
+		testChainConfig, err = config.DefaultTestChainConfig()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Create our chain config with the forks selected by our test chain config. A new instance is created, so it is
+	// not shared across chains.
+	chainConfig, err := testChainConfig.GetChainConfig()
 	if err != nil {
 		return nil, err
 	}
 
-	// TODO: go-ethereum doesn't set cancun start time for THEIR test `ChainConfig` struct.
-	//   Note: We have our own `TestChainConfig` definition that is different (second argument in this function).
-	//  We should allow the user to provide a go-ethereum `ChainConfig` to do custom fork selection, inside of our
-	//  `TestChainConfig` definition. Or we should wrap it in our own struct to simplify the options and not pollute
-	//  our overall medusa project config.
-	cancunTime := uint64(0)
-	chainConfig.ShanghaiTime = &cancunTime
-	chainConfig.CancunTime = &cancunTime
-
 	// Create our genesis definition with our default chain config.
 	genesisDefinition := &core.Genesis{
 		Config:    chainConfig,
@@ -125,12 +175,18 @@ func NewTestChain(genesisAlloc types.GenesisAlloc, testChainConfig *config.TestC
 		BaseFee:    big.NewInt(0),
 	}
 
-	// Use a default config if we were not provided one
-	if testChainConfig == nil {
-		testChainConfig, err = config.DefaultTestChainConfig()
-		if err != nil {
-			return nil, err
+	// If fork mode is enabled, create our remote state provider if we were not provided one, and start our chain from
+	// the timestamp of the block we forked from.
+	if testChainConfig.ForkConfig.ForkModeEnabled {
+		if provider == nil {
+			provider, err = newRemoteStateProvider(testChainConfig.ForkConfig)
+			if err != nil {
+				return nil, err
+			}
 		}
+		genesisDefinition.Timestamp = provider.BlockTimestamp()
+	} else {
+		provider = nil
 	}
 
 	// Obtain our VM extensions from our config
@@ -148,6 +204,10 @@ func NewTestChain(genesisAlloc types.GenesisAlloc, testChainConfig *config.TestC
 		if err != nil {
 			return nil, err
 		}
+		cheatTracer.allowedFFICommands, err = compileFFICommands(testChainConfig.CheatCodeConfig.AllowedFFICommands)
+		if err != nil {
+			return nil, err
+		}
 		for _, cheatContract := range cheatContracts {
 			genesisDefinition.Alloc[cheatContract.address] = types.Account{
 				Balance: big.NewInt(0),
@@ -157,6 +217,29 @@ func NewTestChain(genesisAlloc types.GenesisAlloc, testChainConfig *config.TestC
 		}
 	}
 
+	// Add any precompiles provided by our config. Built-in precompiles share the cheat code tracer for execution
+	// context, so we create one if cheat codes are disabled. As with cheat codes, code is placed at their addresses
+	// so code size checks prior to external calls succeed.
+	if len(testChainConfig.Precompiles) > 0 {
+		if cheatTracer == nil {
+			cheatTracer = newCheatCodeTracer()
+		}
+		precompiles, err := getConfiguredPrecompiles(cheatTracer, testChainConfig.Precompiles, genesisDefinition.Alloc)
+		if err != nil {
+			return nil, err
+		}
+		for address, precompile := range precompiles {
+			if account := genesisDefinition.Alloc[address]; len(account.Code) == 0 {
+				account.Code = []byte{0xFF}
+				if account.Balance == nil {
+					account.Balance = big.NewInt(0)
+				}
+				genesisDefinition.Alloc[address] = account
+			}
+			vmConfigExtensions.AdditionalPrecompiles[address] = precompile
+		}
+	}
+
 	// Create an in-memory database
 	db := rawdb.NewMemoryDatabase()
 	dbConfig := &triedb.Config{
@@ -172,8 +255,23 @@ func NewTestChain(genesisAlloc types.GenesisAlloc, testChainConfig *config.TestC
 	// Convert our genesis block (go-ethereum type) to a test chain block.
 	testChainGenesisBlock := chainTypes.NewBlock(genesisBlock.Header())
 
-	// Create our state database over-top our database.
-	stateDatabase := state.NewDatabaseWithConfig(db, dbConfig)
+	// Create our state database over-top our database. If we forked a remote chain, any state missing from it is read
+	// from remote, except for our genesis accounts and precompiles.
+	var stateDatabase state.Database = state.NewDatabaseWithConfig(db, dbConfig)
+	if provider != nil {
+		localAccounts := make(map[common.Address]struct{})
+		for address := range genesisDefinition.Alloc {
+			localAccounts[address] = struct{}{}
+		}
+		for address := range vmConfigExtensions.AdditionalPrecompiles {
+			localAccounts[address] = struct{}{}
+		}
+		rules := chainConfig.Rules(big.NewInt(0), true, genesisDefinition.Timestamp)
+		for _, address := range vm.ActivePrecompiles(rules) {
+			localAccounts[address] = struct{}{}
+		}
+		stateDatabase = newRemoteStateDatabase(stateDatabase, provider, localAccounts)
+	}
 
 	// Create a tracer forwarder to support the addition of multiple tracers for transaction and call execution.
 	transactionTracerRouter := NewTestChainTracerRouter()
@@ -193,11 +291,13 @@ func NewTestChain(genesisAlloc types.GenesisAlloc, testChainConfig *config.TestC
 		testChainConfig:         testChainConfig,
 		chainConfig:             genesisDefinition.Config,
 		vmConfigExtensions:      vmConfigExtensions,
+		remoteStateProvider:     provider,
+		cheatCodeTracer:         cheatTracer,
 	}
 
 	// Add our internal tracers to this chain.
 	chain.AddTracer(newTestChainDeploymentsTracer().NativeTracer(), true, false)
-	if testChainConfig.CheatCodeConfig.CheatCodesEnabled {
+	if cheatTracer != nil {
 		chain.AddTracer(cheatTracer.NativeTracer(), true, true)
 		cheatTracer.bindToChain(chain)
 	}
@@ -220,20 +320,52 @@ func NewTestChain(genesisAlloc types.GenesisAlloc, testChainConfig *config.TestC
 func (t *TestChain) Close() {
 	// Reset the state DB's cache
 	t.stateDatabase.TrieDB().Close()
+
+	// Persist any remote state we fetched, so it can be reused.
+	if t.remoteStateProvider != nil {
+		_ = t.remoteStateProvider.Flush()
+	}
+
+	// Close any chains linked to this one.
+	for _, linkedChain := range t.linkedChains {
+		linkedChain.Close()
+	}
 }
 
-// Clone recreates the current TestChain state into a new instance. This simply reconstructs the block/chain state
-// but does not perform any other API-related changes such as adding additional tracers the original had. Additionally,
-// this does not clone pending blocks. The provided method, if non-nil, is used as callback to provide an intermediate
-// step between chain creation, and copying of all blocks, allowing for tracers to be added.
+// FlushRemoteStateCache persists any remote state fetched by this chain (or any chain sharing its remote state
+// provider) to disk, if the chain was created in fork mode with a cache directory.
+// Returns an error if one occurred.
+func (t *TestChain) FlushRemoteStateCache() error {
+	if t.remoteStateProvider == nil {
+		return nil
+	}
+	return t.remoteStateProvider.Flush()
+}
+
+// Clone recreates the current TestChain state into a new instance. This copies the committed blocks and the
+// underlying state database of this chain, rather than re-executing every message, but does not perform any other
+// API-related changes such as adding additional tracers the original had. Additionally, this does not clone pending
+// blocks. The provided method, if non-nil, is used as callback to provide an intermediate step between chain creation,
+// and copying of all blocks, allowing for tracers to be added and events to be subscribed to. As blocks are copied
+// rather than re-executed, tracers added this way will not observe their execution, and any results they would have
+// stored are copied from this chain instead. All events which executing the messages of the copied blocks would emit
+// (pending block, transaction and contract deployment events) are still emitted, in the same order.
+// Any chains linked to this chain are cloned in the same way after it, invoking the provided method for each, and are
+// linked to the new chain.
 // Returns the new chain, or an error if one occurred.
 func (t *TestChain) Clone(onCreateFunc func(chain *TestChain) error) (*TestChain, error) {
-	// Create a new chain with the same genesis definition and config
-	targetChain, err := NewTestChain(t.genesisDefinition.Alloc, t.testChainConfig)
+	// Create a new chain with the same genesis definition and config, sharing our remote state provider (if any)
+	targetChain, err := newTestChain(t.genesisDefinition.Alloc, t.testChainConfig, t.remoteStateProvider)
 	if err != nil {
 		return nil, err
 	}
 
+	// Carry over the name we were linked under, so the new chain can be identified before it is linked.
+	targetChain.name = t.name
+
+	// Carry over any contract artifacts registered with us.
+	targetChain.contractArtifacts = slices.Clone(t.contractArtifacts)
+
 	// If we have a provided function for our creation event, execute it now
 	if onCreateFunc != nil {
 		err = onCreateFunc(targetChain)
@@ -242,44 +374,223 @@ func (t *TestChain) Clone(onCreateFunc func(chain *TestChain) error) (*TestChain
 		}
 	}
 
-	// Replay all messages after genesis onto it. We set the block gas limit each time we mine so the chain acts as it
-	// did originally.
-	for i := 1; i < len(t.blocks); i++ {
-		// First create a new pending block to commit
-		blockHeader := t.blocks[i].Header
-		_, err = targetChain.PendingBlockCreateWithParameters(blockHeader.Number.Uint64(), blockHeader.Time, &blockHeader.GasLimit)
+	// Announce any contracts which were registered from our genesis state.
+	for _, genesisContract := range t.genesisContracts {
+		err = targetChain.RegisterGenesisContract(genesisContract)
 		if err != nil {
 			return nil, err
 		}
+	}
 
-		// Now add each transaction/message to it.
-		messages := t.blocks[i].Messages
-		for j := 0; j < len(messages); j++ {
-			err = targetChain.PendingBlockAddTx(messages[j])
+	// Copy our database onto the new chain, so it holds the state after every block we committed.
+	batch := targetChain.db.NewBatch()
+	err = t.iterateDatabase(func(key []byte, value []byte) error {
+		err := batch.Put(key, value)
+		if err != nil {
+			return err
+		}
+		if batch.ValueSize() >= ethdb.IdealBatchSize {
+			err = batch.Write()
 			if err != nil {
-				return nil, err
+				return err
 			}
+			batch.Reset()
 		}
-
-		// Commit the block finally
-		err = targetChain.PendingBlockCommit()
-		if err != nil {
-			return nil, err
-		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not copy chain database onto a new chain: %v", err)
+	}
+	err = batch.Write()
+	if err != nil {
+		return nil, fmt.Errorf("could not copy chain database onto a new chain: %v", err)
 	}
 
-	// Set our final block gas limit
-	targetChain.BlockGasLimit = t.BlockGasLimit
+	// Copy all blocks after genesis onto it.
+	blocks := make([]*chainTypes.Block, 0, len(t.blocks)-1)
+	for i := 1; i < len(t.blocks); i++ {
+		blocks = append(blocks, copyCommittedBlock(t.blocks[i]))
+	}
+	err = targetChain.restoreBlocks(blocks, t.chainConfig.ChainID, t.BlockGasLimit)
+	if err != nil {
+		return nil, err
+	}
 
 	// Verify our state
 	if targetChain.Head().Hash != t.Head().Hash {
 		return nil, errors.New("could not copy chain state onto a new chain, resulting chain head hashes did not match")
 	}
 
+	// Clone any chains linked to this one and link them to our new chain.
+	for _, name := range t.linkedChainNames() {
+		linkedChain, err := t.linkedChains[name].Clone(onCreateFunc)
+		if err != nil {
+			return nil, err
+		}
+		err = targetChain.LinkChain(name, linkedChain)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	// Return our new chain
 	return targetChain, nil
 }
 
+// iterateDatabase flushes the state of every committed block to the underlying database, then iterates over all
+// key-value pairs in it, invoking the provided callback for each. The values provided to the callback must not be
+// modified.
+// Returns an error if one occurred, or the first error returned by the callback.
+func (t *TestChain) iterateDatabase(callback func(key []byte, value []byte) error) error {
+	// Acquire our database lock, as this may be called by multiple clones of the chain at once.
+	t.dbLock.Lock()
+	defer t.dbLock.Unlock()
+
+	// Committed state is held in the trie database's cache until it is flushed, so we flush the state of every block
+	// to ensure the database holds all of it.
+	for _, block := range t.blocks {
+		err := t.stateDatabase.TrieDB().Commit(block.Header.Root, false)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Iterate over every key-value pair in our database.
+	iterator := t.db.NewIterator(nil, nil)
+	defer iterator.Release()
+	for iterator.Next() {
+		err := callback(iterator.Key(), iterator.Value())
+		if err != nil {
+			return err
+		}
+	}
+	return iterator.Error()
+}
+
+// copyCommittedBlock creates a copy of a committed block, so it can be committed to another chain whose database
+// already holds its state. Results stored by tracers are copied as-is, while hooks to execute when its messages are
+// reverted are not, as they act on the chain the block was originally committed to. They are instead added anew for
+// the chain the block is restored onto, by its tracers and event subscribers, as it is restored.
+// Returns the copied block.
+func copyCommittedBlock(block *chainTypes.Block) *chainTypes.Block {
+	messageResults := make([]*chainTypes.MessageResults, len(block.MessageResults))
+	for i, result := range block.MessageResults {
+		messageResults[i] = &chainTypes.MessageResults{
+			PostStateRoot:             result.PostStateRoot,
+			ExecutionResult:           result.ExecutionResult,
+			Receipt:                   result.Receipt,
+			ContractDeploymentChanges: result.ContractDeploymentChanges,
+			AdditionalResults:         maps.Clone(result.AdditionalResults),
+		}
+	}
+	return &chainTypes.Block{
+		Hash:           block.Hash,
+		Header:         types.CopyHeader(block.Header),
+		BlobBaseFee:    new(big.Int).Set(block.BlobBaseFee),
+		Messages:       slices.Clone(block.Messages),
+		MessageResults: messageResults,
+	}
+}
+
+// restoreBlocks appends the provided committed blocks to the chain, whose database must already hold the state after
+// each of them. This is used to restore the blocks of another chain without re-executing their messages. The events
+// which executing each message would emit are emitted as it is restored, with the block growing as each message is
+// added to it, so subscribers can track the restored blocks as they would track executed ones. However, the chain
+// state reflects all restored blocks while they are emitted. The chain ID and block gas limit of the chain the blocks
+// were committed to are restored as well, as they may have been changed while committing them.
+// Returns an error if one occurred.
+func (t *TestChain) restoreBlocks(blocks []*chainTypes.Block, chainID *big.Int, blockGasLimit uint64) error {
+	// Discard any pending block, as it would not build upon our restored blocks.
+	err := t.PendingBlockDiscard()
+	if err != nil {
+		return err
+	}
+
+	// Load the state after our new head.
+	if len(blocks) > 0 {
+		t.state, err = t.StateFromRoot(blocks[len(blocks)-1].Header.Root)
+		if err != nil {
+			return fmt.Errorf("could not load the state of restored blocks: %v", err)
+		}
+	}
+	t.chainConfig.ChainID = new(big.Int).Set(chainID)
+	t.BlockGasLimit = blockGasLimit
+
+	for _, block := range blocks {
+		// Create our block without any messages, so they can be added as they are restored.
+		restoredBlock := &chainTypes.Block{
+			Hash:           block.Hash,
+			Header:         block.Header,
+			BlobBaseFee:    block.BlobBaseFee,
+			Messages:       make([]*core.Message, 0, len(block.Messages)),
+			MessageResults: make([]*chainTypes.MessageResults, 0, len(block.MessageResults)),
+		}
+		err = t.Events.PendingBlockCreated.Publish(PendingBlockCreatedEvent{
+			Chain: t,
+			Block: restoredBlock,
+		})
+		if err != nil {
+			return err
+		}
+
+		for i, messageResult := range block.MessageResults {
+			restoredBlock.Messages = append(restoredBlock.Messages, block.Messages[i])
+			restoredBlock.MessageResults = append(restoredBlock.MessageResults, messageResult)
+
+			// Restore the state our cheat code tracer held after the message, as it was not executed on this chain.
+			if t.cheatCodeTracer != nil {
+				t.cheatCodeTracer.restorePersistentState(messageResult)
+			}
+
+			// Emit our events as if the message was executed.
+			err = t.emitContractChangeEvents(false, messageResult)
+			if err != nil {
+				return err
+			}
+			err = t.Events.PendingBlockAddedTx.Publish(PendingBlockAddedTxEvent{
+				Chain:            t,
+				Block:            restoredBlock,
+				TransactionIndex: len(restoredBlock.Messages),
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		// Commit our block.
+		t.blocks = append(t.blocks, restoredBlock)
+		err = t.Events.PendingBlockCommitted.Publish(PendingBlockCommittedEvent{
+			Chain: t,
+			Block: restoredBlock,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RegisterGenesisContract registers a contract which exists in the chain's genesis state (rather than being deployed
+// by a transaction) and emits a contract deployment added event for it, so it can be tracked as any deployed contract
+// would be. The provided bytecode is passed on to event subscribers as-is, so it may describe the contract definition
+// the contract should be matched to. Registered contracts are announced again on any clone of this chain, after its
+// creation callback was executed.
+// Returns an error if the address holds no code at genesis, or if an event subscriber returned an error.
+func (t *TestChain) RegisterGenesisContract(contract *chainTypes.DeployedContractBytecode) error {
+	// Verify the contract exists in our genesis state.
+	if account, ok := t.genesisDefinition.Alloc[contract.Address]; !ok || len(account.Code) == 0 {
+		return fmt.Errorf("could not register genesis contract at address %s, as no code exists there at genesis", contract.Address.String())
+	}
+
+	// Register the contract and emit our event for it.
+	t.genesisContracts = append(t.genesisContracts, contract)
+	return t.Events.ContractDeploymentAddedEventEmitter.Publish(ContractDeploymentsAddedEvent{
+		Chain:             t,
+		Contract:          contract,
+		DynamicDeployment: false,
+	})
+}
+
 // AddTracer adds a given tracers.Tracer or TestChainTracer to the TestChain. If directed, the tracer will be attached
 // for transactions and/or non-state changing calls made via CallContract.
 func (t *TestChain) AddTracer(tracer *TestChainTracer, txs bool, calls bool) {
@@ -301,7 +612,20 @@ func (t *TestChain) State() *state.StateDB {
 	return t.state
 }
 
-// CheatCodeContracts returns all cheat code contracts which are installed in the chain.
+// ChainConfig returns the params.ChainConfig used by the chain, which describes the forks it simulates.
+func (t *TestChain) ChainConfig() *params.ChainConfig {
+	return t.chainConfig
+}
+
+// ExternalInputsRead indicates whether any cheat code which reads inputs from outside the chain (files, environment
+// variables or command outputs) was executed on this chain. If so, the chain's state may not be reproduced from its
+// configuration and the messages executed on it alone.
+func (t *TestChain) ExternalInputsRead() bool {
+	return t.cheatCodeTracer != nil && t.cheatCodeTracer.externalInputsRead
+}
+
+// CheatCodeContracts returns all cheat code contracts which are installed in the chain. This includes any built-in
+// precompiles provided by the chain config which are implemented as cheat code contracts.
 func (t *TestChain) CheatCodeContracts() map[common.Address]*CheatCodeContract {
 	// Create a map of cheat code contracts to store our results
 	contracts := make(map[common.Address]*CheatCodeContract, 0)
@@ -512,11 +836,20 @@ func (t *TestChain) RevertToBlockNumber(blockNumber uint64) error {
 		return fmt.Errorf("could not revert to block number %d because it does not refer to an internally committed block", blockNumber)
 	}
 
+	// Revert to our block
+	return t.revertToBlockIndex(closestBlockIndex)
+}
+
+// revertToBlockIndex sets the head of the chain to the committed block at the provided index in the chain's internal
+// block list, discarding any pending block, emitting contract deployment change events and executing revert hooks for
+// every removed block, and reloading the state from the underlying database.
+// Returns an error if one occurred.
+func (t *TestChain) revertToBlockIndex(blockIndex int) error {
 	// Slice off our blocks to be removed (to produce relevant events)
-	removedBlocks := t.blocks[closestBlockIndex+1:]
+	removedBlocks := t.blocks[blockIndex+1:]
 
 	// Remove the relevant blocks from the chain
-	t.blocks = t.blocks[:closestBlockIndex+1]
+	t.blocks = t.blocks[:blockIndex+1]
 
 	// Discard our pending block
 	err := t.PendingBlockDiscard()
@@ -539,7 +872,7 @@ func (t *TestChain) RevertToBlockNumber(blockNumber uint64) error {
 	}
 
 	// Reload our state from our database
-	t.state, err = t.StateAfterBlockNumber(blockNumber)
+	t.state, err = t.StateFromRoot(t.blocks[blockIndex].Header.Root)
 	if err != nil {
 		return err
 	}
@@ -552,6 +885,16 @@ func (t *TestChain) RevertToBlockNumber(blockNumber uint64) error {
 	return err
 }
 
+// remoteStateBackup returns a copy of the provided state if it is the chain's current state and the chain was created
+// in fork mode, or nil otherwise. In fork mode, reading remote state may fail during execution. The state retains such
+// errors and refuses to be committed afterwards, so the copy is used to restore the chain's state when this occurs.
+func (t *TestChain) remoteStateBackup(stateDB *state.StateDB) *state.StateDB {
+	if t.remoteStateProvider == nil || stateDB != t.state {
+		return nil
+	}
+	return stateDB.Copy()
+}
+
 // CallContract performs a message call over the current test chain state and obtains a core.ExecutionResult.
 // This is similar to the CallContract method provided by Ethereum for use in calling pure/view functions, as it
 // executed a transaction without committing any changes, instead discarding them.
@@ -564,6 +907,9 @@ func (t *TestChain) CallContract(msg *core.Message, state *state.StateDB, additi
 		state = t.state
 	}
 
+	// Back up our chain state, so it can be restored if we fail to read remote state during execution.
+	stateBackup := t.remoteStateBackup(state)
+
 	// Obtain our state snapshot to revert any changes after our call
 	snapshot := state.Snapshot()
 
@@ -572,7 +918,7 @@ func (t *TestChain) CallContract(msg *core.Message, state *state.StateDB, additi
 
 	// Create our transaction and block contexts for the vm
 	txContext := core.NewEVMTxContext(msg)
-	blockContext := newTestChainBlockContext(t, t.Head().Header)
+	blockContext := newTestChainBlockContext(t, t.Head())
 
 	// Create a new call tracer router that incorporates any additional tracers provided just for this call, while
 	// still calling our internal tracers.
@@ -586,9 +932,15 @@ func (t *TestChain) CallContract(msg *core.Message, state *state.StateDB, additi
 		NoBaseFee:        true,
 		ConfigExtensions: t.vmConfigExtensions,
 	})
-	// Set our block context and chain config in order for cheatcodes to override what EVM interpreter sees.
+	// The EVM zeroes the blob base fee for messages with no blob fee cap when NoBaseFee is set, so we restore it to
+	// ensure BLOBBASEFEE reflects our block.
+	evm.Context.BlobBaseFee = blockContext.BlobBaseFee
+
+	// Set our block context, tx context, and chain config in order for cheatcodes to override what EVM interpreter sees.
 	t.pendingBlockContext = &evm.Context
+	t.pendingTxContext = &evm.TxContext
 	t.pendingBlockChainConfig = evm.ChainConfig()
+	t.pendingEVM = evm
 
 	// Create a tx from our msg, for hashing/receipt purposes
 	tx := utils.MessageToTransaction(msg)
@@ -606,6 +958,14 @@ func (t *TestChain) CallContract(msg *core.Message, state *state.StateDB, additi
 	// Revert to our state snapshot to undo any changes.
 	state.RevertToSnapshot(snapshot)
 
+	// If we failed to read state during execution, the result cannot be trusted.
+	if state.Error() != nil {
+		err = fmt.Errorf("could not read state during call: %v", state.Error())
+		if stateBackup != nil {
+			t.state = stateBackup
+		}
+	}
+
 	// Gather receipt for OnTxEnd
 	receipt := &types.Receipt{Type: tx.Type()}
 	if msgResult.Failed() {
@@ -638,15 +998,15 @@ func (t *TestChain) PendingBlockCreate() (*chainTypes.Block, error) {
 	// Create a block with default parameters
 	blockNumber := t.HeadBlockNumber() + 1
 	timestamp := t.Head().Header.Time + 1
-	return t.PendingBlockCreateWithParameters(blockNumber, timestamp, nil)
+	return t.PendingBlockCreateWithParameters(blockNumber, timestamp, nil, nil, nil, nil)
 }
 
 // PendingBlockCreateWithParameters constructs an empty block which is pending addition to the chain, using the block
 // properties provided. Values should be sensibly chosen (e.g., block number and timestamps should be greater than the
 // previous block). Providing a block number that is greater than the previous block number plus one will simulate empty
-// blocks between.
+// blocks between. If the block gas limit, base fee, prevrandao, or blob base fee values are nil, defaults are used.
 // Returns the constructed block, or an error if one occurred.
-func (t *TestChain) PendingBlockCreateWithParameters(blockNumber uint64, blockTime uint64, blockGasLimit *uint64) (*chainTypes.Block, error) {
+func (t *TestChain) PendingBlockCreateWithParameters(blockNumber uint64, blockTime uint64, blockGasLimit *uint64, blockBaseFee *big.Int, blockPrevRandao *common.Hash, blockBlobBaseFee *big.Int) (*chainTypes.Block, error) {
 	// If we already have a pending block, return an error.
 	if t.pendingBlock != nil {
 		return nil, fmt.Errorf("could not create a new pending block for chain, as a block is already pending")
@@ -657,6 +1017,11 @@ func (t *TestChain) PendingBlockCreateWithParameters(blockNumber uint64, blockTi
 		blockGasLimit = &t.BlockGasLimit
 	}
 
+	// If our base fee is not specified, use the initial base fee defined by EIP-1559.
+	if blockBaseFee == nil {
+		blockBaseFee = big.NewInt(params.InitialBaseFee)
+	}
+
 	// Validate our block number exceeds our previous head
 	currentHeadBlockNumber := t.Head().Header.Number.Uint64()
 	if blockNumber <= currentHeadBlockNumber {
@@ -686,8 +1051,8 @@ func (t *TestChain) PendingBlockCreateWithParameters(blockNumber uint64, blockTi
 	// - Bloom is aggregated for each transaction in the block (for now empty).
 	// - TODO: Difficulty should be revisited/checked.
 	// - GasUsed is aggregated for each transaction in the block (for now zero).
-	// - Mix digest is only useful for randomness, so we just fake randomness by using the previous block hash.
-	// - TODO: BaseFee should be revisited/checked.
+	// - Mix digest is only useful for randomness (prevrandao), so unless a value was provided, we just fake randomness
+	//   by using the previous block hash.
 	header := &types.Header{
 		ParentHash:  parentBlockHash,
 		UncleHash:   types.EmptyUncleHash,
@@ -704,12 +1069,18 @@ func (t *TestChain) PendingBlockCreateWithParameters(blockNumber uint64, blockTi
 		Extra:       []byte{},
 		MixDigest:   parentBlockHash,
 		Nonce:       types.BlockNonce{},
-		BaseFee:     big.NewInt(params.InitialBaseFee),
+		BaseFee:     new(big.Int).Set(blockBaseFee),
+	}
+	if blockPrevRandao != nil {
+		header.MixDigest = *blockPrevRandao
 	}
 
 	// Create a new block for our test node
 	t.pendingBlock = chainTypes.NewBlock(header)
 	t.pendingBlock.Hash = t.pendingBlock.Header.Hash()
+	if blockBlobBaseFee != nil {
+		t.pendingBlock.BlobBaseFee = new(big.Int).Set(blockBlobBaseFee)
+	}
 
 	// Emit our event for the pending block being created
 	err := t.Events.PendingBlockCreated.Publish(PendingBlockCreatedEvent{
@@ -740,7 +1111,7 @@ func (t *TestChain) PendingBlockAddTx(message *core.Message, additionalTracers .
 	tx := utils.MessageToTransaction(message)
 
 	// Create a new context to be used in the EVM environment
-	blockContext := newTestChainBlockContext(t, t.pendingBlock.Header)
+	blockContext := newTestChainBlockContext(t, t.pendingBlock)
 
 	// Create our VM config
 	vmConfig := vm.Config{
@@ -768,13 +1139,32 @@ func (t *TestChain) PendingBlockAddTx(message *core.Message, additionalTracers .
 	// Create our EVM instance.
 	evm := vm.NewEVM(blockContext, core.NewEVMTxContext(message), t.state, t.chainConfig, vmConfig)
 
-	// Set our block context and chain config in order for cheatcodes to override what EVM interpreter sees.
+	// The EVM zeroes the blob base fee for messages with no blob fee cap when NoBaseFee is set, so we restore it to
+	// ensure BLOBBASEFEE reflects our block.
+	evm.Context.BlobBaseFee = blockContext.BlobBaseFee
+
+	// Set our block context, tx context, and chain config in order for cheatcodes to override what EVM interpreter sees.
 	t.pendingBlockContext = &evm.Context
+	t.pendingTxContext = &evm.TxContext
 	t.pendingBlockChainConfig = evm.ChainConfig()
+	t.pendingEVM = evm
+
+	// Back up our state, so it can be restored if we fail to read remote state during execution. Snapshots cannot be
+	// used for this, as applying the transaction finalises the state, which clears its journal.
+	stateBackup := t.remoteStateBackup(t.state)
 
 	// Apply our transaction
 	var usedGas uint64
 	receipt, executionResult, err := vendored.EVMApplyTransaction(message, t.chainConfig, t.testChainConfig, &t.pendingBlock.Header.Coinbase, gasPool, t.state, t.pendingBlock.Header.Number, t.pendingBlock.Hash, tx, &usedGas, evm)
+
+	// If we failed to read state during execution, the result cannot be trusted. We restore our state (if backed up)
+	// before returning, so it stays in sync with our pending block, which was not yet updated, nor was its gas used.
+	if stateErr := t.state.Error(); stateErr != nil {
+		if stateBackup != nil {
+			t.state = stateBackup
+		}
+		return fmt.Errorf("could not read state when adding tx to pending block: %v", stateErr)
+	}
 	if err != nil {
 		return fmt.Errorf("test chain state write error when adding tx to pending block: %v", err)
 	}
@@ -840,9 +1230,11 @@ func (t *TestChain) PendingBlockCommit() error {
 		return err
 	}
 
-	// Discard the test chain's reference to the EVM interpreter's block context and chain config.
+	// Discard the test chain's reference to the EVM interpreter, its block context, tx context, and chain config.
 	t.pendingBlockContext = nil
+	t.pendingTxContext = nil
 	t.pendingBlockChainConfig = nil
+	t.pendingEVM = nil
 
 	// Append our new block to our chain.
 	t.blocks = append(t.blocks, t.pendingBlock)
@@ -874,7 +1266,9 @@ func (t *TestChain) PendingBlockDiscard() error {
 	pendingBlock := t.pendingBlock
 	t.pendingBlock = nil
 	t.pendingBlockContext = nil
+	t.pendingTxContext = nil
 	t.pendingBlockChainConfig = nil
+	t.pendingEVM = nil
 
 	// Emit our contract change events for the messages reverted
 	err := t.emitContractChangeEvents(true, pendingBlock.MessageResults...)
@@ -0,0 +1,67 @@
+package chain
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	compilationTypes "github.com/crytic/medusa/compilation/types"
+)
+
+// ContractArtifact describes a compiled contract registered with a TestChain, so cheat codes can refer to it by name
+// (e.g. to deploy it).
+type ContractArtifact struct {
+	// Name describes the name of the contract.
+	Name string
+
+	// SourcePath describes the path of the source file which defines the contract.
+	SourcePath string
+
+	// CompiledContract describes the compiled contract.
+	CompiledContract *compilationTypes.CompiledContract
+}
+
+// RegisterContractArtifact registers a compiled contract with the chain, so cheat codes executed on it, or on any chain
+// linked to it, can refer to it by name. Registered artifacts are carried over to any clone of this chain.
+func (t *TestChain) RegisterContractArtifact(artifact *ContractArtifact) {
+	t.contractArtifacts = append(t.contractArtifacts, artifact)
+}
+
+// ContractArtifact obtains a compiled contract registered with the primary chain by its identifier. The identifier can
+// be the name of the contract (e.g. "Name"), the path of the source file which defines it (e.g. "File.sol"), or both
+// (e.g. "File.sol:Name"). Paths match any source path ending with them.
+// Returns the contract artifact, or an error if no single contract artifact matched the identifier.
+func (t *TestChain) ContractArtifact(identifier string) (*ContractArtifact, error) {
+	// Split our identifier into its path and name components.
+	var path, name string
+	if separatorIndex := strings.LastIndex(identifier, ":"); separatorIndex >= 0 {
+		path, name = identifier[:separatorIndex], identifier[separatorIndex+1:]
+	} else if strings.HasSuffix(identifier, ".sol") {
+		path = identifier
+	} else {
+		name = identifier
+	}
+	path = filepath.ToSlash(path)
+
+	// Find each artifact matching our identifier. The same contract may be included in several compilations, so we
+	// only consider the first artifact for a given source path and name.
+	var match *ContractArtifact
+	for _, artifact := range t.PrimaryChain().contractArtifacts {
+		sourcePath := filepath.ToSlash(artifact.SourcePath)
+		if name != "" && artifact.Name != name {
+			continue
+		}
+		if path != "" && sourcePath != path && !strings.HasSuffix(sourcePath, "/"+path) {
+			continue
+		}
+		if match == nil {
+			match = artifact
+		} else if match.Name != artifact.Name || match.SourcePath != artifact.SourcePath {
+			return nil, fmt.Errorf("'%s' matches multiple contracts, including %s:%s and %s:%s", identifier, match.SourcePath, match.Name, artifact.SourcePath, artifact.Name)
+		}
+	}
+	if match == nil {
+		return nil, fmt.Errorf("no contract matching '%s' was found in the compilation artifacts", identifier)
+	}
+	return match, nil
+}
@@ -0,0 +1,341 @@
+package chain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	chainTypes "github.com/crytic/medusa/chain/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TestChainExport describes the committed blocks and underlying state database of a TestChain in a serializable form.
+// It can be imported into a new TestChain created with the same genesis allocations and config, restoring the
+// exported chain without re-executing any of its messages.
+type TestChainExport struct {
+	// GenesisHash describes the genesis block hash of the exported chain, used to verify the chain an export is
+	// imported into was created equivalently.
+	GenesisHash common.Hash `json:"genesisHash"`
+
+	// ChainID describes the chain ID of the exported chain.
+	ChainID *hexutil.Big `json:"chainId"`
+
+	// BlockGasLimit describes the block gas limit of the exported chain.
+	BlockGasLimit uint64 `json:"blockGasLimit"`
+
+	// GenesisContracts describes the contracts which were registered with the exported chain via
+	// TestChain.RegisterGenesisContract.
+	GenesisContracts []*exportedContract `json:"genesisContracts"`
+
+	// Blocks describes all blocks committed to the exported chain after genesis.
+	Blocks []*exportedBlock `json:"blocks"`
+
+	// Database describes every key-value pair in the underlying database of the exported chain, where keys are hex
+	// encoded.
+	Database map[string]hexutil.Bytes `json:"database"`
+}
+
+// exportedAdditionalResults describes the results stored by tracers in chainTypes.MessageResults.AdditionalResults
+// which are included in a TestChainExport, by their key, alongside a function to decode each from its JSON encoding.
+// Results stored under any other key are not exported. Entries are added with RegisterExportedAdditionalResults.
+var exportedAdditionalResults = map[string]func(data json.RawMessage) (any, error){
+	cheatCodePersistentStateKey:  decodeExportedAdditionalResults[*cheatCodePersistentState],
+	cheatCodeDictionaryValuesKey: decodeExportedAdditionalResults[cheatCodeDictionaryValues],
+}
+
+// RegisterExportedAdditionalResults registers the results stored by a tracer under the provided key in
+// chainTypes.MessageResults.AdditionalResults to be included in any TestChainExport, so they are restored when the
+// export is imported (e.g. so results collected while setting up a chain are available when it is restored from a
+// cache). The results must be of type T, and support JSON encoding. This must only be called when a package is
+// initialized, from its init function.
+func RegisterExportedAdditionalResults[T any](key string) {
+	exportedAdditionalResults[key] = decodeExportedAdditionalResults[T]
+}
+
+// decodeExportedAdditionalResults decodes results of type T stored by a tracer from their JSON encoding.
+// Returns the decoded results, or an error if one occurred.
+func decodeExportedAdditionalResults[T any](data json.RawMessage) (any, error) {
+	var value T
+	err := json.Unmarshal(data, &value)
+	return value, err
+}
+
+// exportedBlock describes a committed block within a TestChainExport.
+type exportedBlock struct {
+	// Hash describes the block hash.
+	Hash common.Hash `json:"hash"`
+
+	// Header describes the block header.
+	Header *types.Header `json:"header"`
+
+	// BlobBaseFee describes the blob base fee messages in the block were executed with.
+	BlobBaseFee *hexutil.Big `json:"blobBaseFee"`
+
+	// Messages describes the messages executed in the block.
+	Messages []*core.Message `json:"messages"`
+
+	// MessageResults describes the results of executing each message in the block.
+	MessageResults []*exportedMessageResults `json:"messageResults"`
+}
+
+// exportedMessageResults describes the results of a message in an exportedBlock. Results stored by tracers are only
+// exported if they were registered with RegisterExportedAdditionalResults.
+type exportedMessageResults struct {
+	// PostStateRoot describes the state root hash after the execution of the message.
+	PostStateRoot common.Hash `json:"postStateRoot"`
+
+	// Receipt describes the message's transaction receipt.
+	Receipt *types.Receipt `json:"receipt"`
+
+	// UsedGas describes the gas used by the execution of the message.
+	UsedGas uint64 `json:"usedGas"`
+
+	// RefundedGas describes the gas refunded after the execution of the message.
+	RefundedGas uint64 `json:"refundedGas"`
+
+	// Err describes the error the message execution resulted in, or an empty string if it succeeded.
+	Err string `json:"err"`
+
+	// ReturnData describes the data returned by the execution of the message.
+	ReturnData hexutil.Bytes `json:"returnData"`
+
+	// ContractDeploymentChanges describes the changes made to deployed contracts by the message.
+	ContractDeploymentChanges []*exportedContractChange `json:"contractDeploymentChanges"`
+
+	// AdditionalResults describes the JSON encoded results stored by tracers for the message, by their key.
+	AdditionalResults map[string]json.RawMessage `json:"additionalResults"`
+}
+
+// exportedContract describes a chainTypes.DeployedContractBytecode within a TestChainExport.
+type exportedContract struct {
+	// Address describes the address of the contract.
+	Address common.Address `json:"address"`
+
+	// InitBytecode describes the bytecode used to deploy the contract.
+	InitBytecode hexutil.Bytes `json:"initBytecode"`
+
+	// RuntimeBytecode describes the runtime bytecode of the contract.
+	RuntimeBytecode hexutil.Bytes `json:"runtimeBytecode"`
+}
+
+// exportedContractChange describes a chainTypes.DeployedContractBytecodeChange within a TestChainExport.
+type exportedContractChange struct {
+	// Contract describes the contract which was affected.
+	Contract *exportedContract `json:"contract"`
+
+	// Creation indicates whether the change was a contract creation.
+	Creation bool `json:"creation"`
+
+	// DynamicCreation indicates whether the change was a dynamic contract creation.
+	DynamicCreation bool `json:"dynamicCreation"`
+
+	// SelfDestructed indicates whether the change was due to a self-destruct instruction being executed.
+	SelfDestructed bool `json:"selfDestructed"`
+
+	// Destroyed indicates whether the contract was destroyed as a result of the change.
+	Destroyed bool `json:"destroyed"`
+}
+
+// Export creates a TestChainExport describing the committed blocks and state of the chain, which can later be imported
+// into a new chain using Import. Chains created in fork mode, or with a pending block, cannot be exported.
+// Returns the export, or an error if one occurred.
+func (t *TestChain) Export() (*TestChainExport, error) {
+	// Remote state loaded into a chain in fork mode is tracked outside the chain's state, so it cannot be exported.
+	if t.remoteStateProvider != nil {
+		return nil, errors.New("could not export chain, as exporting chains created in fork mode is not supported")
+	}
+	if t.pendingBlock != nil {
+		return nil, errors.New("could not export chain, as it has a pending block")
+	}
+
+	// Create our export and copy our database into it.
+	export := &TestChainExport{
+		GenesisHash:      t.blocks[0].Hash,
+		ChainID:          (*hexutil.Big)(t.chainConfig.ChainID),
+		BlockGasLimit:    t.BlockGasLimit,
+		GenesisContracts: make([]*exportedContract, 0, len(t.genesisContracts)),
+		Blocks:           make([]*exportedBlock, 0, len(t.blocks)-1),
+		Database:         make(map[string]hexutil.Bytes),
+	}
+	err := t.iterateDatabase(func(key []byte, value []byte) error {
+		export.Database[hexutil.Encode(key)] = common.CopyBytes(value)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not export chain database: %v", err)
+	}
+
+	// Export our genesis contracts and blocks.
+	for _, genesisContract := range t.genesisContracts {
+		export.GenesisContracts = append(export.GenesisContracts, newExportedContract(genesisContract))
+	}
+	for i := 1; i < len(t.blocks); i++ {
+		block := t.blocks[i]
+		exportBlock := &exportedBlock{
+			Hash:           block.Hash,
+			Header:         block.Header,
+			BlobBaseFee:    (*hexutil.Big)(block.BlobBaseFee),
+			Messages:       block.Messages,
+			MessageResults: make([]*exportedMessageResults, 0, len(block.MessageResults)),
+		}
+		for _, result := range block.MessageResults {
+			// Receipts require their logs to be set when they are decoded, so we ensure they are never exported as null.
+			receipt := *result.Receipt
+			if receipt.Logs == nil {
+				receipt.Logs = make([]*types.Log, 0)
+			}
+			exportResult := &exportedMessageResults{
+				PostStateRoot:             result.PostStateRoot,
+				Receipt:                   &receipt,
+				UsedGas:                   result.ExecutionResult.UsedGas,
+				RefundedGas:               result.ExecutionResult.RefundedGas,
+				ReturnData:                result.ExecutionResult.ReturnData,
+				ContractDeploymentChanges: make([]*exportedContractChange, 0, len(result.ContractDeploymentChanges)),
+				AdditionalResults:         make(map[string]json.RawMessage),
+			}
+			for key, value := range result.AdditionalResults {
+				if _, ok := exportedAdditionalResults[key]; !ok {
+					continue
+				}
+				exportResult.AdditionalResults[key], err = json.Marshal(value)
+				if err != nil {
+					return nil, fmt.Errorf("could not export results stored under key '%s': %v", key, err)
+				}
+			}
+			if result.ExecutionResult.Err != nil {
+				exportResult.Err = result.ExecutionResult.Err.Error()
+			}
+			for _, change := range result.ContractDeploymentChanges {
+				exportResult.ContractDeploymentChanges = append(exportResult.ContractDeploymentChanges, &exportedContractChange{
+					Contract:        newExportedContract(change.Contract),
+					Creation:        change.Creation,
+					DynamicCreation: change.DynamicCreation,
+					SelfDestructed:  change.SelfDestructed,
+					Destroyed:       change.Destroyed,
+				})
+			}
+			exportBlock.MessageResults = append(exportBlock.MessageResults, exportResult)
+		}
+		export.Blocks = append(export.Blocks, exportBlock)
+	}
+	return export, nil
+}
+
+// Import restores the committed blocks and state described by a TestChainExport onto the chain, without re-executing
+// any messages. The chain must only hold its genesis block, and must have been created with the same genesis
+// allocations and config as the exported chain. As with Clone, contract deployment events are emitted for any
+// genesis contracts restored, and all events which executing the messages of the restored blocks would emit are
+// emitted as they are restored.
+// Returns an error if one occurred.
+func (t *TestChain) Import(export *TestChainExport) error {
+	// Verify our chain can hold the exported one.
+	if t.remoteStateProvider != nil {
+		return errors.New("could not import chain, as importing into chains created in fork mode is not supported")
+	}
+	if len(t.blocks) != 1 {
+		return errors.New("could not import chain, as blocks were already committed to the chain after genesis")
+	}
+	if t.blocks[0].Hash != export.GenesisHash {
+		return fmt.Errorf("could not import chain, as its genesis block hash %s does not match the exported genesis block hash %s", t.blocks[0].Hash.String(), export.GenesisHash.String())
+	}
+
+	// Write the exported database into our own.
+	batch := t.db.NewBatch()
+	for key, value := range export.Database {
+		decodedKey, err := hexutil.Decode(key)
+		if err != nil {
+			return fmt.Errorf("could not import chain, as its database contains an invalid key: %v", err)
+		}
+		err = batch.Put(decodedKey, value)
+		if err != nil {
+			return err
+		}
+	}
+	err := batch.Write()
+	if err != nil {
+		return fmt.Errorf("could not import chain database: %v", err)
+	}
+
+	// Register our genesis contracts.
+	for _, genesisContract := range export.GenesisContracts {
+		err = t.RegisterGenesisContract(genesisContract.toDeployedContractBytecode())
+		if err != nil {
+			return err
+		}
+	}
+
+	// Reconstruct our blocks and restore them.
+	blocks := make([]*chainTypes.Block, 0, len(export.Blocks))
+	for _, exportBlock := range export.Blocks {
+		if exportBlock.BlobBaseFee == nil {
+			return errors.New("could not import chain, as a block is missing its blob base fee")
+		}
+		block := &chainTypes.Block{
+			Hash:           exportBlock.Hash,
+			Header:         exportBlock.Header,
+			BlobBaseFee:    exportBlock.BlobBaseFee.ToInt(),
+			Messages:       exportBlock.Messages,
+			MessageResults: make([]*chainTypes.MessageResults, 0, len(exportBlock.MessageResults)),
+		}
+		for _, exportResult := range exportBlock.MessageResults {
+			result := &chainTypes.MessageResults{
+				PostStateRoot: exportResult.PostStateRoot,
+				ExecutionResult: &core.ExecutionResult{
+					UsedGas:     exportResult.UsedGas,
+					RefundedGas: exportResult.RefundedGas,
+					ReturnData:  exportResult.ReturnData,
+				},
+				Receipt:                   exportResult.Receipt,
+				ContractDeploymentChanges: make([]chainTypes.DeployedContractBytecodeChange, 0, len(exportResult.ContractDeploymentChanges)),
+				AdditionalResults:         make(map[string]any, 0),
+			}
+			if exportResult.Err != "" {
+				result.ExecutionResult.Err = errors.New(exportResult.Err)
+			}
+			for key, data := range exportResult.AdditionalResults {
+				decode, ok := exportedAdditionalResults[key]
+				if !ok {
+					continue
+				}
+				result.AdditionalResults[key], err = decode(data)
+				if err != nil {
+					return fmt.Errorf("could not import results stored under key '%s': %v", key, err)
+				}
+			}
+			for _, change := range exportResult.ContractDeploymentChanges {
+				result.ContractDeploymentChanges = append(result.ContractDeploymentChanges, chainTypes.DeployedContractBytecodeChange{
+					Contract:        change.Contract.toDeployedContractBytecode(),
+					Creation:        change.Creation,
+					DynamicCreation: change.DynamicCreation,
+					SelfDestructed:  change.SelfDestructed,
+					Destroyed:       change.Destroyed,
+				})
+			}
+			block.MessageResults = append(block.MessageResults, result)
+		}
+		blocks = append(blocks, block)
+	}
+	// Restoring our blocks loads the state after our new head, which verifies the exported database holds it.
+	return t.restoreBlocks(blocks, export.ChainID.ToInt(), export.BlockGasLimit)
+}
+
+// newExportedContract creates an exportedContract from the provided chainTypes.DeployedContractBytecode.
+func newExportedContract(contract *chainTypes.DeployedContractBytecode) *exportedContract {
+	return &exportedContract{
+		Address:         contract.Address,
+		InitBytecode:    contract.InitBytecode,
+		RuntimeBytecode: contract.RuntimeBytecode,
+	}
+}
+
+// toDeployedContractBytecode converts the exportedContract back into a chainTypes.DeployedContractBytecode.
+func (c *exportedContract) toDeployedContractBytecode() *chainTypes.DeployedContractBytecode {
+	return &chainTypes.DeployedContractBytecode{
+		Address:         c.Address,
+		InitBytecode:    c.InitBytecode,
+		RuntimeBytecode: c.RuntimeBytecode,
+	}
+}
@@ -0,0 +1,259 @@
+package chain
+
+import (
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/crytic/medusa/chain/config"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeRemoteChain is an in-process stand-in for a JSON-RPC endpoint, serving the state of a remote chain at a single
+// block number. It records how many requests it served, so tests can verify caching behavior.
+type fakeRemoteChain struct {
+	// blockNumber is the latest (and only) block number of the remote chain.
+	blockNumber uint64
+	// blockTimestamp is the timestamp of the block.
+	blockTimestamp uint64
+	// accounts describes the state of the remote chain.
+	accounts types.GenesisAlloc
+	// requestCount describes the number of state requests served by the endpoint.
+	requestCount int
+	// failRequests indicates whether the endpoint should fail all requests.
+	failRequests bool
+	// lock synchronizes access to requestCount and failRequests.
+	lock sync.Mutex
+}
+
+// ServeHTTP serves a JSON-RPC request for the fake remote chain.
+func (f *fakeRemoteChain) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	f.lock.Lock()
+	failRequests := f.failRequests
+	f.lock.Unlock()
+	if failRequests {
+		http.Error(w, "endpoint unavailable", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Parse our address parameter, if any.
+	var address common.Address
+	if len(request.Params) > 0 {
+		_ = json.Unmarshal(request.Params[0], &address)
+	}
+	account := f.accounts[address]
+
+	// Serve our method
+	var result any
+	switch request.Method {
+	case "eth_blockNumber":
+		result = hexutil.Uint64(f.blockNumber)
+	case "eth_getBlockByNumber":
+		result = map[string]any{"number": hexutil.Uint64(f.blockNumber), "timestamp": hexutil.Uint64(f.blockTimestamp)}
+	case "eth_getBalance":
+		balance := new(big.Int)
+		if account.Balance != nil {
+			balance.Set(account.Balance)
+		}
+		result = (*hexutil.Big)(balance)
+	case "eth_getTransactionCount":
+		result = hexutil.Uint64(account.Nonce)
+	case "eth_getCode":
+		result = hexutil.Bytes(account.Code)
+	case "eth_getStorageAt":
+		var slot common.Hash
+		_ = json.Unmarshal(request.Params[1], &slot)
+		result = account.Storage[slot]
+	default:
+		http.Error(w, "method not supported", http.StatusBadRequest)
+		return
+	}
+	f.lock.Lock()
+	f.requestCount++
+	f.lock.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"jsonrpc": "2.0", "id": request.ID, "result": result})
+}
+
+// RequestCount returns the number of requests served by the fake remote chain.
+func (f *fakeRemoteChain) RequestCount() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.requestCount
+}
+
+// TestChainForkMode creates a TestChain in fork mode over a fake remote chain, and ensures remote accounts, code and
+// storage are lazily read as committed state, changes to them persist across blocks and clones, and they are only
+// fetched once.
+func TestChainForkMode(t *testing.T) {
+	// Create our remote chain, with a contract which returns the value of storage slot zero, and one which clears it.
+	remoteContract := common.HexToAddress("0x1234")
+	remoteClearContract := common.HexToAddress("0x5678")
+	remoteChain := &fakeRemoteChain{
+		blockNumber:    100,
+		blockTimestamp: 1700000000,
+		accounts: types.GenesisAlloc{
+			remoteContract: types.Account{
+				Balance: big.NewInt(1000),
+				Nonce:   1,
+				Code:    common.FromHex("0x60005460005260206000f3"),
+				Storage: map[common.Hash]common.Hash{
+					{}: common.BigToHash(big.NewInt(42)),
+				},
+			},
+			remoteClearContract: types.Account{
+				Balance: big.NewInt(0),
+				Nonce:   1,
+				Code:    common.FromHex("0x6000600055"),
+				Storage: map[common.Hash]common.Hash{
+					{}: common.BigToHash(big.NewInt(42)),
+				},
+			},
+		},
+	}
+	server := httptest.NewServer(remoteChain)
+	defer server.Close()
+
+	// Create a test chain which forks the remote chain.
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChainConfig.ForkConfig = config.ForkConfig{
+		ForkModeEnabled: true,
+		RpcUrl:          server.URL,
+		CacheDirectory:  t.TempDir(),
+	}
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := types.GenesisAlloc{
+		sender: types.Account{Balance: big.NewInt(1_000_000_000_000_000_000)},
+	}
+	chain, err := NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+
+	// Our chain should start from the timestamp of the remote block.
+	assert.EqualValues(t, remoteChain.blockTimestamp, chain.Head().Header.Time)
+
+	// Call our remote contract and verify its storage was loaded.
+	msg := core.Message{
+		To:                &remoteContract,
+		From:              sender,
+		Nonce:             chain.State().GetNonce(sender),
+		Value:             big.NewInt(0),
+		GasLimit:          chain.BlockGasLimit,
+		GasPrice:          big.NewInt(1),
+		GasFeeCap:         big.NewInt(0),
+		GasTipCap:         big.NewInt(0),
+		Data:              nil,
+		AccessList:        nil,
+		SkipAccountChecks: false,
+	}
+	result, err := chain.CallContract(&msg, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(42)).Bytes(), result.ReturnData)
+
+	// Remote state should be read as committed state.
+	assert.True(t, chain.State().Exist(remoteContract))
+	assert.EqualValues(t, common.BigToHash(big.NewInt(42)), chain.State().GetCommittedState(remoteContract, common.Hash{}))
+
+	// Send a transaction to our remote contract in a new block.
+	block, err := chain.PendingBlockCreate()
+	assert.NoError(t, err)
+	err = chain.PendingBlockAddTx(&msg)
+	assert.NoError(t, err)
+	err = chain.PendingBlockCommit()
+	assert.NoError(t, err)
+	assert.EqualValues(t, types.ReceiptStatusSuccessful, block.MessageResults[0].Receipt.Status)
+
+	// The remote account should still be in our state.
+	assert.EqualValues(t, 1000, chain.State().GetBalance(remoteContract).Uint64())
+	assert.EqualValues(t, 1, chain.State().GetNonce(remoteContract))
+	assert.EqualValues(t, remoteChain.accounts[remoteContract].Code, chain.State().GetCode(remoteContract))
+	assert.EqualValues(t, common.BigToHash(big.NewInt(42)), chain.State().GetState(remoteContract, common.Hash{}))
+
+	// Clear the remote storage slot of our other contract. As the remote value is committed state, clearing it should
+	// be refunded.
+	clearMsg := msg
+	clearMsg.To = &remoteClearContract
+	clearMsg.Nonce = chain.State().GetNonce(sender)
+	block, err = chain.PendingBlockCreate()
+	assert.NoError(t, err)
+	err = chain.PendingBlockAddTx(&clearMsg)
+	assert.NoError(t, err)
+	err = chain.PendingBlockCommit()
+	assert.NoError(t, err)
+	assert.EqualValues(t, types.ReceiptStatusSuccessful, block.MessageResults[0].Receipt.Status)
+	assert.Greater(t, block.MessageResults[0].ExecutionResult.RefundedGas, uint64(0))
+
+	// The cleared slot should not be read from remote again.
+	assert.EqualValues(t, common.Hash{}, chain.State().GetState(remoteClearContract, common.Hash{}))
+
+	// Clone our chain and verify it matches, without fetching any remote state again.
+	requestCount := remoteChain.RequestCount()
+	clonedChain, err := chain.Clone(nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, chain.Head().Hash, clonedChain.Head().Hash)
+	assert.EqualValues(t, common.Hash{}, clonedChain.State().GetState(remoteClearContract, common.Hash{}))
+	assert.EqualValues(t, requestCount, remoteChain.RequestCount())
+
+	// Revert our blocks, and verify the remote state is read again.
+	err = chain.RevertToBlockNumber(0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(42)), chain.State().GetState(remoteClearContract, common.Hash{}))
+	result, err = chain.CallContract(&msg, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(42)).Bytes(), result.ReturnData)
+
+	// Flush our cache to disk and ensure a new chain can be created from it without fetching any remote state.
+	err = chain.FlushRemoteStateCache()
+	assert.NoError(t, err)
+	cacheFiles, err := os.ReadDir(testChainConfig.ForkConfig.CacheDirectory)
+	assert.NoError(t, err)
+	assert.Len(t, cacheFiles, 1)
+	assert.EqualValues(t, "100", filepath.Base(cacheFiles[0].Name())[:3])
+
+	testChainConfig.ForkConfig.RpcBlock = remoteChain.blockNumber
+	requestCount = remoteChain.RequestCount()
+	cachedChain, err := NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+	result, err = cachedChain.CallContract(&msg, nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(42)).Bytes(), result.ReturnData)
+	assert.EqualValues(t, requestCount, remoteChain.RequestCount())
+
+	// Make our endpoint fail, and send a transaction which accesses an account which was not fetched yet. The
+	// transaction should fail to be added, without affecting our pending block, which should still be committable.
+	remoteChain.lock.Lock()
+	remoteChain.failRequests = true
+	remoteChain.lock.Unlock()
+	unfetchedAddress := common.HexToAddress("0x9999")
+	failingMsg := msg
+	failingMsg.To = &unfetchedAddress
+	failingMsg.Nonce = cachedChain.State().GetNonce(sender)
+	block, err = cachedChain.PendingBlockCreate()
+	assert.NoError(t, err)
+	err = cachedChain.PendingBlockAddTx(&failingMsg)
+	assert.Error(t, err)
+	assert.Len(t, block.Messages, 0)
+	assert.EqualValues(t, 0, block.Header.GasUsed)
+	assert.NoError(t, cachedChain.State().Error())
+	err = cachedChain.PendingBlockCommit()
+	assert.NoError(t, err)
+}
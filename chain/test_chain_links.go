@@ -0,0 +1,90 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+)
+
+// PrimaryChainName describes the name which refers to the primary chain among a set of linked chains. Chains cannot be
+// linked under this name.
+const PrimaryChainName = "primary"
+
+// LinkChain links the provided chain to this one under the provided name, making this chain the primary chain of the
+// pair. Linked chains are snapshotted, reverted to snapshots, cloned, and closed alongside their primary chain, so a set
+// of chains can be managed as one, e.g. to simulate contracts spanning multiple chains which communicate with one
+// another. Linked chains cannot have chains linked to them in turn.
+// Returns an error if the chain could not be linked.
+func (t *TestChain) LinkChain(name string, linkedChain *TestChain) error {
+	// Verify our name can be used to refer to the chain.
+	if name == "" || name == PrimaryChainName {
+		return fmt.Errorf("could not link chain, as '%s' is not a valid linked chain name", name)
+	}
+	if _, exists := t.linkedChains[name]; exists {
+		return fmt.Errorf("could not link chain, as a chain is already linked under the name '%s'", name)
+	}
+
+	// Verify we are not creating any nested or circular links.
+	if linkedChain == t {
+		return errors.New("could not link chain, as a chain cannot be linked to itself")
+	}
+	if t.primaryChain != nil || linkedChain.primaryChain != nil || len(linkedChain.linkedChains) > 0 {
+		return errors.New("could not link chain, as linked chains cannot have chains linked to them")
+	}
+
+	// Snapshots of linked chains are taken alongside this chain, so neither can have a pending block.
+	if t.pendingBlock != nil || linkedChain.pendingBlock != nil {
+		return errors.New("could not link chain, as a chain has a pending block")
+	}
+
+	// Link our chain.
+	if t.linkedChains == nil {
+		t.linkedChains = make(map[string]*TestChain)
+	}
+	t.linkedChains[name] = linkedChain
+	linkedChain.primaryChain = t
+	linkedChain.name = name
+	return nil
+}
+
+// Name returns the name this chain was linked to its primary chain under, or PrimaryChainName if it is not linked to
+// another chain.
+func (t *TestChain) Name() string {
+	if t.name == "" {
+		return PrimaryChainName
+	}
+	return t.name
+}
+
+// PrimaryChain returns the chain this chain is linked to, or this chain itself if it is not linked to another chain.
+func (t *TestChain) PrimaryChain() *TestChain {
+	if t.primaryChain == nil {
+		return t
+	}
+	return t.primaryChain
+}
+
+// LinkedChain obtains a chain linked to the same primary chain as this one by its name. An empty name or
+// PrimaryChainName refer to the primary chain itself.
+// Returns the chain, or nil if no chain is linked under the provided name.
+func (t *TestChain) LinkedChain(name string) *TestChain {
+	primaryChain := t.PrimaryChain()
+	if name == "" || name == PrimaryChainName {
+		return primaryChain
+	}
+	return primaryChain.linkedChains[name]
+}
+
+// LinkedChains returns a mapping of names to chains which were linked to this chain with LinkChain.
+func (t *TestChain) LinkedChains() map[string]*TestChain {
+	return maps.Clone(t.linkedChains)
+}
+
+// linkedChainNames returns the names of chains which were linked to this chain, in a deterministic order.
+func (t *TestChain) linkedChainNames() []string {
+	names := maps.Keys(t.linkedChains)
+	slices.Sort(names)
+	return names
+}
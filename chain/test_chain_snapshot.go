@@ -0,0 +1,190 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+
+	chainTypes "github.com/crytic/medusa/chain/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// TestChainSnapshot describes a point in a TestChain's history which the chain can be reverted to with
+// TestChain.RevertToSnapshot, including points between the transactions of a pending block. Snapshots are cheap to take
+// and restore, as they never re-execute messages:
+//   - Committed state tries are immutable and share all unchanged nodes with one another, so a snapshot only records
+//     the committed head it refers to, and restoring it reloads the state from that head's state root.
+//   - The state of a pending block is not committed, so a snapshot taken while a block is pending records a copy of the
+//     pending state instead. The copy only duplicates the accounts loaded by the pending block, sharing the committed
+//     trie beneath it, and is copied again whenever it is restored so the snapshot remains valid.
+//
+// Snapshots of a chain include snapshots of any chains linked to it.
+type TestChainSnapshot struct {
+	// blockIndex describes the index of the chain head within the chain's committed blocks when the snapshot was
+	// taken.
+	blockIndex int
+
+	// headHash describes the hash of the chain head when the snapshot was taken. It is used to verify the snapshot
+	// still refers to a block in the chain's history.
+	headHash common.Hash
+
+	// pendingBlock describes the block which was pending when the snapshot was taken, or nil if there was none. The
+	// block may since have been committed, in which case it is made pending again when the snapshot is restored.
+	pendingBlock *chainTypes.Block
+
+	// pendingHeader describes a copy of the header of pendingBlock when the snapshot was taken.
+	pendingHeader *types.Header
+
+	// pendingMessageCount describes the number of messages pendingBlock contained when the snapshot was taken.
+	pendingMessageCount int
+
+	// pendingLastMessageResults describes the results of the last message pendingBlock contained when the snapshot was
+	// taken, or nil if it contained none. It is used to verify the messages of pendingBlock the snapshot refers to were
+	// not since reverted and replaced.
+	pendingLastMessageResults *chainTypes.MessageResults
+
+	// pendingState describes a copy of the chain state when the snapshot was taken, if a block was pending.
+	pendingState *state.StateDB
+
+	// linkedSnapshots describes a mapping of names to snapshots of the chains linked to the chain when the snapshot
+	// was taken.
+	linkedSnapshots map[string]*TestChainSnapshot
+}
+
+// Snapshot creates a TestChainSnapshot of the chain's current state, which the chain can later be reverted to with
+// RevertToSnapshot. If a block is pending, the snapshot captures its state following the messages added to it so far.
+// The states of any linked chains are captured alongside it.
+// Returns the snapshot, or an error if one occurred.
+func (t *TestChain) Snapshot() (*TestChainSnapshot, error) {
+	snapshot := &TestChainSnapshot{
+		blockIndex:      len(t.blocks) - 1,
+		headHash:        t.Head().Hash,
+		linkedSnapshots: make(map[string]*TestChainSnapshot, len(t.linkedChains)),
+	}
+
+	// If we have a pending block, its state is not committed, so we capture a copy of it.
+	if t.pendingBlock != nil {
+		snapshot.pendingBlock = t.pendingBlock
+		snapshot.pendingHeader = types.CopyHeader(t.pendingBlock.Header)
+		snapshot.pendingMessageCount = len(t.pendingBlock.MessageResults)
+		if snapshot.pendingMessageCount > 0 {
+			snapshot.pendingLastMessageResults = t.pendingBlock.MessageResults[snapshot.pendingMessageCount-1]
+		}
+		snapshot.pendingState = t.state.Copy()
+	}
+
+	// Capture the states of our linked chains as well.
+	for name, linkedChain := range t.linkedChains {
+		linkedSnapshot, err := linkedChain.Snapshot()
+		if err != nil {
+			return nil, fmt.Errorf("could not create snapshot of linked chain '%s': %v", name, err)
+		}
+		snapshot.linkedSnapshots[name] = linkedSnapshot
+	}
+	return snapshot, nil
+}
+
+// RevertToSnapshot sets the state of the chain to the one captured by the provided TestChainSnapshot. Any blocks
+// committed and messages added since the snapshot was taken are discarded, in the same way RevertToBlockNumber does.
+// If a block was pending when the snapshot was taken, it is made pending again, containing only the messages it
+// contained at the time, even if it has since been committed.
+// A snapshot remains valid after it is restored, so it can be reverted to repeatedly. It becomes invalid once the chain
+// is reverted past the point it refers to. Any linked chains are reverted to the states captured alongside it.
+// Returns an error if one occurred.
+func (t *TestChain) RevertToSnapshot(snapshot *TestChainSnapshot) error {
+	// Verify our snapshot still refers to a point in our history.
+	if !t.snapshotInHistory(snapshot) {
+		return errors.New("could not revert to chain snapshot, as the block it refers to is no longer part of the chain")
+	}
+
+	// Verify our snapshot captured every chain linked to ours, so we do not revert only some of them.
+	for name := range t.linkedChains {
+		if _, ok := snapshot.linkedSnapshots[name]; !ok {
+			return fmt.Errorf("could not revert to chain snapshot, as it was taken before chain '%s' was linked", name)
+		}
+	}
+
+	// Revert to our block
+	var err error
+	if snapshot.pendingBlock == nil {
+		err = t.revertToBlockIndex(snapshot.blockIndex)
+	} else {
+		err = t.revertToPendingSnapshot(snapshot)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Revert our linked chains to their captured states.
+	for name, linkedChain := range t.linkedChains {
+		err = linkedChain.RevertToSnapshot(snapshot.linkedSnapshots[name])
+		if err != nil {
+			return fmt.Errorf("could not revert linked chain '%s' to snapshot: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// snapshotInHistory indicates whether the provided TestChainSnapshot still refers to a point in the chain's history,
+// i.e. the chain was not reverted past it since it was taken. This does not verify the snapshots of linked chains.
+func (t *TestChain) snapshotInHistory(snapshot *TestChainSnapshot) bool {
+	// Verify the head the snapshot was taken at is still committed.
+	if snapshot.blockIndex >= len(t.blocks) || t.blocks[snapshot.blockIndex].Hash != snapshot.headHash {
+		return false
+	}
+	if snapshot.pendingBlock == nil {
+		return true
+	}
+
+	// Verify the block which was pending is either still pending, or was committed immediately after the head.
+	pendingBlock := snapshot.pendingBlock
+	pendingStillPending := t.pendingBlock == pendingBlock && len(t.blocks) == snapshot.blockIndex+1
+	pendingCommitted := len(t.blocks) > snapshot.blockIndex+1 && t.blocks[snapshot.blockIndex+1] == pendingBlock
+	if !pendingStillPending && !pendingCommitted {
+		return false
+	}
+
+	// Verify the messages the block contained were not since reverted.
+	if len(pendingBlock.MessageResults) < snapshot.pendingMessageCount {
+		return false
+	}
+	return snapshot.pendingMessageCount == 0 || pendingBlock.MessageResults[snapshot.pendingMessageCount-1] == snapshot.pendingLastMessageResults
+}
+
+// revertToPendingSnapshot reverts the chain to a TestChainSnapshot taken while a block was pending, which still refers
+// to a point in the chain's history. Blocks committed after the pending block are removed, the pending block is made
+// pending again if it was committed, and any messages added to it after the snapshot was taken are reverted.
+// Returns an error if one occurred.
+func (t *TestChain) revertToPendingSnapshot(snapshot *TestChainSnapshot) error {
+	// If the block which was pending has since been committed, remove it and any blocks which followed it, then make it
+	// pending again. Otherwise, it is still pending, so we only need to revert messages within it.
+	pendingBlock := snapshot.pendingBlock
+	if t.pendingBlock != pendingBlock {
+		err := t.revertToBlockIndex(snapshot.blockIndex + 1)
+		if err != nil {
+			return err
+		}
+		t.blocks = t.blocks[:snapshot.blockIndex+1]
+		t.pendingBlock = pendingBlock
+	}
+
+	// Emit our contract change events for the messages added since the snapshot was taken, and execute their revert
+	// hooks in reverse order.
+	revertedMessageResults := pendingBlock.MessageResults[snapshot.pendingMessageCount:]
+	err := t.emitContractChangeEvents(true, revertedMessageResults...)
+	if err != nil {
+		return err
+	}
+	for i := len(revertedMessageResults) - 1; i >= 0; i-- {
+		revertedMessageResults[i].OnRevertHookFuncs.Execute(false, true)
+	}
+
+	// Remove the reverted messages from the block and restore its header and our state. We copy our captured state so
+	// the snapshot remains valid as the chain continues to update it.
+	pendingBlock.Messages = pendingBlock.Messages[:snapshot.pendingMessageCount]
+	pendingBlock.MessageResults = pendingBlock.MessageResults[:snapshot.pendingMessageCount]
+	pendingBlock.Header = types.CopyHeader(snapshot.pendingHeader)
+	t.state = snapshot.pendingState.Copy()
+	return nil
+}
@@ -1,10 +1,15 @@
 package chain
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/json"
 	"math/big"
 	"math/rand"
 	"testing"
 
+	"github.com/crytic/medusa/chain/config"
+	chainTypes "github.com/crytic/medusa/chain/types"
 	"github.com/crytic/medusa/compilation/platforms"
 	"github.com/crytic/medusa/utils"
 	"github.com/crytic/medusa/utils/testutils"
@@ -12,6 +17,8 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -114,7 +121,7 @@ func TestChainReverting(t *testing.T) {
 			// the diff.
 
 			// Create a block with our parameters
-			_, err := chain.PendingBlockCreateWithParameters(newBlockNumber, chain.Head().Header.Time+jumpDistance, nil)
+			_, err := chain.PendingBlockCreateWithParameters(newBlockNumber, chain.Head().Header.Time+jumpDistance, nil, nil, nil, nil)
 			assert.NoError(t, err)
 			err = chain.PendingBlockCommit()
 			assert.NoError(t, err)
@@ -147,6 +154,195 @@ func TestChainReverting(t *testing.T) {
 	}
 }
 
+// TestChainSnapshots creates a TestChain, takes snapshots while committing blocks, and reverts to them repeatedly to
+// ensure the chain state is restored and snapshots of removed blocks are rejected.
+func TestChainSnapshots(t *testing.T) {
+	const blocksToProduce = 20
+
+	// Obtain our chain and senders
+	chain, _ := createChain(t)
+
+	// Create some empty blocks, taking a snapshot and recording the head hash before each.
+	snapshots := make([]*TestChainSnapshot, 0)
+	headHashes := make([]common.Hash, 0)
+	for x := 0; x < blocksToProduce; x++ {
+		snapshot, err := chain.Snapshot()
+		assert.NoError(t, err)
+		snapshots = append(snapshots, snapshot)
+		headHashes = append(headHashes, chain.Head().Hash)
+
+		_, err = chain.PendingBlockCreate()
+		assert.NoError(t, err)
+		err = chain.PendingBlockCommit()
+		assert.NoError(t, err)
+	}
+
+	// Revert to a snapshot in the middle of our chain, then commit a new block over it.
+	middle := blocksToProduce / 2
+	err := chain.RevertToSnapshot(snapshots[middle])
+	assert.NoError(t, err)
+	assert.EqualValues(t, headHashes[middle], chain.Head().Hash)
+	verifyChain(t, chain)
+	_, err = chain.PendingBlockCreateWithParameters(chain.HeadBlockNumber()+2, chain.Head().Header.Time+2, nil, nil, nil, nil)
+	assert.NoError(t, err)
+	err = chain.PendingBlockCommit()
+	assert.NoError(t, err)
+
+	// Snapshots of blocks which were replaced should no longer be valid, while the snapshot we reverted to should
+	// remain valid.
+	err = chain.RevertToSnapshot(snapshots[middle+1])
+	assert.Error(t, err)
+	err = chain.RevertToSnapshot(snapshots[middle])
+	assert.NoError(t, err)
+	assert.EqualValues(t, headHashes[middle], chain.Head().Hash)
+
+	// Revert backwards through our remaining snapshots.
+	for i := middle; i >= 0; i-- {
+		err = chain.RevertToSnapshot(snapshots[i])
+		assert.NoError(t, err)
+		assert.EqualValues(t, headHashes[i], chain.Head().Hash)
+		verifyChain(t, chain)
+	}
+}
+
+// TestChainPendingSnapshots creates a TestChain, takes snapshots between the transactions of a pending block, and
+// reverts to them after the block is extended and committed, to ensure the pending block and its state are restored and
+// snapshots of reverted transactions are rejected.
+func TestChainPendingSnapshots(t *testing.T) {
+	// Obtain our chain and senders
+	chain, senders := createChain(t)
+
+	// Define a helper to transfer value between our senders in the pending block.
+	transfer := func() {
+		msg := core.Message{
+			To:                &senders[1],
+			From:              senders[0],
+			Nonce:             chain.State().GetNonce(senders[0]),
+			Value:             big.NewInt(1),
+			GasLimit:          params.TxGas,
+			GasPrice:          big.NewInt(1),
+			GasFeeCap:         big.NewInt(0),
+			GasTipCap:         big.NewInt(0),
+			Data:              nil,
+			AccessList:        nil,
+			SkipAccountChecks: false,
+		}
+		err := chain.PendingBlockAddTx(&msg)
+		assert.NoError(t, err)
+	}
+
+	// Create a pending block with a transfer, and take a snapshot before and after it.
+	headHash := chain.Head().Hash
+	_, err := chain.PendingBlockCreate()
+	assert.NoError(t, err)
+	emptySnapshot, err := chain.Snapshot()
+	assert.NoError(t, err)
+	transfer()
+	snapshot, err := chain.Snapshot()
+	assert.NoError(t, err)
+	snapshotBalance := chain.State().GetBalance(senders[1])
+	pendingBlock := chain.PendingBlock()
+
+	// Extend and commit our pending block, then commit another block over it, and revert to our snapshot.
+	for i := 0; i < 2; i++ {
+		transfer()
+		err = chain.PendingBlockCommit()
+		assert.NoError(t, err)
+		_, err = chain.PendingBlockCreate()
+		assert.NoError(t, err)
+		transfer()
+		err = chain.PendingBlockCommit()
+		assert.NoError(t, err)
+
+		// Our pending block should be pending again, with only the transfer it contained when the snapshot was taken.
+		err = chain.RevertToSnapshot(snapshot)
+		assert.NoError(t, err)
+		assert.EqualValues(t, headHash, chain.Head().Hash)
+		assert.Same(t, pendingBlock, chain.PendingBlock())
+		assert.Len(t, chain.PendingBlock().Messages, 1)
+		assert.Len(t, chain.PendingBlock().MessageResults, 1)
+		assert.EqualValues(t, snapshotBalance, chain.State().GetBalance(senders[1]))
+	}
+
+	// Revert past our transfer, then replace it. Our snapshot of it should no longer be valid.
+	err = chain.RevertToSnapshot(emptySnapshot)
+	assert.NoError(t, err)
+	assert.Len(t, chain.PendingBlock().Messages, 0)
+	transfer()
+	err = chain.RevertToSnapshot(snapshot)
+	assert.Error(t, err)
+
+	// Commit our block and ensure the chain remains valid.
+	err = chain.PendingBlockCommit()
+	assert.NoError(t, err)
+	verifyChain(t, chain)
+}
+
+// TestChainLinking creates two TestChains, links one to the other, and ensures snapshots, reverts, and clones of the
+// primary chain apply to the linked chain as well.
+func TestChainLinking(t *testing.T) {
+	// Obtain our chains and link them.
+	primaryChain, _ := createChain(t)
+	linkedChain, _ := createChain(t)
+	err := primaryChain.LinkChain(PrimaryChainName, linkedChain)
+	assert.Error(t, err)
+	err = primaryChain.LinkChain("linked", primaryChain)
+	assert.Error(t, err)
+	err = primaryChain.LinkChain("linked", linkedChain)
+	assert.NoError(t, err)
+	err = linkedChain.LinkChain("nested", primaryChain)
+	assert.Error(t, err)
+
+	// Verify each chain resolves the other by name.
+	assert.EqualValues(t, PrimaryChainName, primaryChain.Name())
+	assert.EqualValues(t, "linked", linkedChain.Name())
+	assert.Same(t, primaryChain, linkedChain.PrimaryChain())
+	assert.Same(t, linkedChain, primaryChain.LinkedChain("linked"))
+	assert.Same(t, primaryChain, linkedChain.LinkedChain(PrimaryChainName))
+	assert.Nil(t, primaryChain.LinkedChain("unknown"))
+
+	// Take a snapshot, then commit blocks to both chains.
+	snapshot, err := primaryChain.Snapshot()
+	assert.NoError(t, err)
+	primaryHeadHash, linkedHeadHash := primaryChain.Head().Hash, linkedChain.Head().Hash
+	for _, testChain := range []*TestChain{primaryChain, linkedChain} {
+		_, err = testChain.PendingBlockCreate()
+		assert.NoError(t, err)
+		err = testChain.PendingBlockCommit()
+		assert.NoError(t, err)
+	}
+
+	// Clone our primary chain and verify the linked chain was cloned with it.
+	clonedChain, err := primaryChain.Clone(nil)
+	assert.NoError(t, err)
+	clonedLinkedChain := clonedChain.LinkedChain("linked")
+	assert.NotNil(t, clonedLinkedChain)
+	assert.NotSame(t, linkedChain, clonedLinkedChain)
+	assert.EqualValues(t, linkedChain.Head().Hash, clonedLinkedChain.Head().Hash)
+	clonedChain.Close()
+
+	// Snapshots taken while a linked chain has a pending block capture it, so it is pending again once reverted to.
+	pendingBlock, err := linkedChain.PendingBlockCreate()
+	assert.NoError(t, err)
+	pendingSnapshot, err := primaryChain.Snapshot()
+	assert.NoError(t, err)
+	err = linkedChain.PendingBlockCommit()
+	assert.NoError(t, err)
+	err = primaryChain.RevertToSnapshot(pendingSnapshot)
+	assert.NoError(t, err)
+	assert.Same(t, pendingBlock, linkedChain.PendingBlock())
+	err = linkedChain.PendingBlockDiscard()
+	assert.NoError(t, err)
+
+	// Revert to our snapshot and verify both chains were reverted.
+	err = primaryChain.RevertToSnapshot(snapshot)
+	assert.NoError(t, err)
+	assert.EqualValues(t, primaryHeadHash, primaryChain.Head().Hash)
+	assert.EqualValues(t, linkedHeadHash, linkedChain.Head().Hash)
+	verifyChain(t, primaryChain)
+	verifyChain(t, linkedChain)
+}
+
 // TestChainBlockNumberJumping creates a TestChain and creates blocks with block numbers which jumped (are
 // non-consecutive) to ensure the chain appropriately spoofs intermediate blocks.
 func TestChainBlockNumberJumping(t *testing.T) {
@@ -178,7 +374,7 @@ func TestChainBlockNumberJumping(t *testing.T) {
 			// the diff.
 
 			// Create a block with our parameters
-			_, err := chain.PendingBlockCreateWithParameters(newBlockNumber, chain.Head().Header.Time+jumpDistance, nil)
+			_, err := chain.PendingBlockCreateWithParameters(newBlockNumber, chain.Head().Header.Time+jumpDistance, nil, nil, nil, nil)
 			assert.NoError(t, err)
 			err = chain.PendingBlockCommit()
 			assert.NoError(t, err)
@@ -524,21 +720,129 @@ func TestChainCloning(t *testing.T) {
 			}
 		}
 
-		// Clone our chain
-		recreatedChain, err := chain.Clone(nil)
+		// Clone our chain, tracking the transactions announced as its blocks are copied.
+		addedTxs := 0
+		recreatedChain, err := chain.Clone(func(newChain *TestChain) error {
+			newChain.Events.PendingBlockAddedTx.Subscribe(func(event PendingBlockAddedTxEvent) error {
+				// Verify the event describes the last message of the block copied so far.
+				assert.EqualValues(t, len(event.Block.Messages), event.TransactionIndex)
+				assert.EqualValues(t, len(event.Block.Messages), len(event.Block.MessageResults))
+				addedTxs++
+				return nil
+			})
+			return nil
+		})
 		assert.NoError(t, err)
 
 		// Verify both chains
 		verifyChain(t, chain)
 		verifyChain(t, recreatedChain)
 
-		// Verify our final block hashes equal in both chains.
+		// Verify every transaction was announced, and our final block hashes equal in both chains.
+		expectedTxs := 0
+		for _, block := range chain.CommittedBlocks() {
+			expectedTxs += len(block.Messages)
+		}
+		assert.EqualValues(t, expectedTxs, addedTxs)
 		assert.EqualValues(t, chain.Head().Hash, recreatedChain.Head().Hash)
 		assert.EqualValues(t, chain.Head().Header.Hash(), recreatedChain.Head().Header.Hash())
 		assert.EqualValues(t, chain.Head().Header.Root, recreatedChain.Head().Header.Root)
 	})
 }
 
+// TestChainExportImport creates a TestChain, deploys contracts to it, exports it, then imports the serialized export
+// into a new chain and ensures the resulting chain state and deployed contracts are the same.
+func TestChainExportImport(t *testing.T) {
+	// Copy our testdata over to our testing directory
+	contractPath := testutils.CopyToTestDirectory(t, "testdata/contracts/deployment_single.sol")
+
+	// Execute our tests in the given test path
+	testutils.ExecuteInDirectory(t, contractPath, func() {
+		// Create a crytic-compile provider
+		cryticCompile := platforms.NewCryticCompilationConfig(contractPath)
+
+		// Obtain our compilations and ensure we didn't encounter an error
+		compilations, _, err := cryticCompile.Compile()
+		assert.NoError(t, err)
+		assert.True(t, len(compilations) > 0)
+
+		// Obtain our chain and senders
+		chain, senders := createChain(t)
+
+		// Deploy each contract that has no construct arguments.
+		deployedContracts := 0
+		for _, compilation := range compilations {
+			for _, source := range compilation.SourcePathToArtifact {
+				for _, contract := range source.Contracts {
+					contract := contract
+					if len(contract.Abi.Constructor.Inputs) == 0 {
+						// Create a message to represent our contract deployment.
+						msg := core.Message{
+							To:                nil,
+							From:              senders[0],
+							Nonce:             chain.State().GetNonce(senders[0]),
+							Value:             big.NewInt(0),
+							GasLimit:          chain.BlockGasLimit,
+							GasPrice:          big.NewInt(1),
+							GasFeeCap:         big.NewInt(0),
+							GasTipCap:         big.NewInt(0),
+							Data:              contract.InitBytecode,
+							AccessList:        nil,
+							SkipAccountChecks: false,
+						}
+
+						// Create a new pending block, add our transaction, and commit it.
+						block, err := chain.PendingBlockCreate()
+						assert.NoError(t, err)
+						err = chain.PendingBlockAddTx(&msg)
+						assert.NoError(t, err)
+						err = chain.PendingBlockCommit()
+						assert.NoError(t, err)
+
+						// Ensure our transaction succeeded
+						assert.EqualValues(t, types.ReceiptStatusSuccessful, block.MessageResults[0].Receipt.Status, "contract deployment tx returned a failed status: %v", block.MessageResults[0].ExecutionResult.Err)
+						deployedContracts++
+					}
+				}
+			}
+		}
+
+		// Export our chain and serialize it.
+		export, err := chain.Export()
+		assert.NoError(t, err)
+		b, err := json.Marshal(export)
+		assert.NoError(t, err)
+
+		// Create a new chain with the same genesis state, track its contract deployments, and import our export.
+		var importedExport TestChainExport
+		err = json.Unmarshal(b, &importedExport)
+		assert.NoError(t, err)
+		importedChain, _ := createChain(t)
+		importedContracts := 0
+		importedChain.Events.ContractDeploymentAddedEventEmitter.Subscribe(func(event ContractDeploymentsAddedEvent) error {
+			assert.NotEmpty(t, importedChain.State().GetCode(event.Contract.Address))
+			importedContracts++
+			return nil
+		})
+		err = importedChain.Import(&importedExport)
+		assert.NoError(t, err)
+
+		// Verify both chains
+		verifyChain(t, chain)
+		verifyChain(t, importedChain)
+
+		// Verify our final block hashes equal in both chains, and our contract deployments were announced.
+		assert.EqualValues(t, chain.Head().Hash, importedChain.Head().Hash)
+		assert.EqualValues(t, chain.Head().Header.Root, importedChain.Head().Header.Root)
+		assert.EqualValues(t, chain.State().GetNonce(senders[0]), importedChain.State().GetNonce(senders[0]))
+		assert.EqualValues(t, deployedContracts, importedContracts)
+
+		// Importing into a chain which already committed blocks should fail.
+		err = importedChain.Import(&importedExport)
+		assert.Error(t, err)
+	})
+}
+
 // TestChainCallSequenceReplayMatchSimple creates a TestChain, sends some messages to it, then creates another chain which
 // it replays the same sequence on. It ensures that the ending state is the same.
 // Note: this does not set block timestamps or other data that might be non-deterministic.
@@ -644,3 +948,557 @@ func TestChainCallSequenceReplayMatchSimple(t *testing.T) {
 		assert.EqualValues(t, chain.Head().Header.Root, recreatedChain.Head().Header.Root)
 	})
 }
+
+// TestChainEVMVersion creates TestChains configured for different EVM versions and ensures that opcodes are only
+// supported by the chains whose EVM version introduced them.
+func TestChainEVMVersion(t *testing.T) {
+	// Define a contract which uses PUSH0 to return empty data.
+	push0Contract := common.HexToAddress("0x1234")
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := types.GenesisAlloc{
+		sender:        types.Account{Balance: big.NewInt(1_000_000_000_000_000_000)},
+		push0Contract: types.Account{Balance: big.NewInt(0), Code: common.FromHex("0x5f5ff3")},
+	}
+
+	// Define our expectation for each EVM version.
+	push0Supported := map[string]bool{
+		"london":   false,
+		"paris":    false,
+		"shanghai": true,
+		"cancun":   true,
+		"prague":   true,
+	}
+	for evmVersion, expectedSupported := range push0Supported {
+		// Create our chain with the given EVM version.
+		testChainConfig, err := config.DefaultTestChainConfig()
+		assert.NoError(t, err)
+		testChainConfig.EVMVersion = evmVersion
+		chain, err := NewTestChain(genesisAlloc, testChainConfig)
+		assert.NoError(t, err)
+
+		// Verify contracts compiled for shanghai are only reported as supported if PUSH0 is supported.
+		assert.EqualValues(t, expectedSupported, testChainConfig.SupportsCompilerEVMVersion("shanghai"))
+
+		// Call our contract and verify it only executes successfully if PUSH0 is supported.
+		msg := core.Message{
+			To:                &push0Contract,
+			From:              sender,
+			Nonce:             chain.State().GetNonce(sender),
+			Value:             big.NewInt(0),
+			GasLimit:          chain.BlockGasLimit,
+			GasPrice:          big.NewInt(1),
+			GasFeeCap:         big.NewInt(0),
+			GasTipCap:         big.NewInt(0),
+			Data:              nil,
+			AccessList:        nil,
+			SkipAccountChecks: false,
+		}
+		result, err := chain.CallContract(&msg, nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, expectedSupported, !result.Failed(), "unexpected result for EVM version %s", evmVersion)
+
+		// Call the BLS12-381 G1 addition precompile with invalid input, and verify it only exists (and fails) on prague.
+		blsG1AddPrecompile := common.BytesToAddress([]byte{0x0b})
+		msg.To = &blsG1AddPrecompile
+		result, err = chain.CallContract(&msg, nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, evmVersion == "prague", result.Failed(), "unexpected BLS12-381 result for EVM version %s", evmVersion)
+
+		// Clones must simulate the same EVM version.
+		clonedChain, err := chain.Clone(nil)
+		assert.NoError(t, err)
+		assert.EqualValues(t, chain.ChainConfig().ShanghaiTime, clonedChain.ChainConfig().ShanghaiTime)
+	}
+
+	// Unsupported EVM versions should result in an error.
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChainConfig.EVMVersion = "frontier"
+	_, err = NewTestChain(genesisAlloc, testChainConfig)
+	assert.Error(t, err)
+}
+
+// TestChainRegisterGenesisContract creates a TestChain with a contract in its genesis state, registers it, and ensures
+// that it is announced as a deployed contract on the chain and any of its clones.
+func TestChainRegisterGenesisContract(t *testing.T) {
+	// Create a chain with a contract at genesis.
+	contractAddress := common.HexToAddress("0x1234")
+	genesisAlloc := types.GenesisAlloc{
+		contractAddress: types.Account{Balance: big.NewInt(0), Code: common.FromHex("0x60005460005260206000f3")},
+	}
+	chain, err := NewTestChain(genesisAlloc, nil)
+	assert.NoError(t, err)
+
+	// Count the contract deployments announced by the chain.
+	deployedContracts := 0
+	chain.Events.ContractDeploymentAddedEventEmitter.Subscribe(func(event ContractDeploymentsAddedEvent) error {
+		assert.EqualValues(t, contractAddress, event.Contract.Address)
+		deployedContracts++
+		return nil
+	})
+
+	// Registering an address without code should fail.
+	err = chain.RegisterGenesisContract(&chainTypes.DeployedContractBytecode{Address: common.HexToAddress("0x5678")})
+	assert.Error(t, err)
+	assert.EqualValues(t, 0, deployedContracts)
+
+	// Register our contract and ensure it was announced.
+	err = chain.RegisterGenesisContract(&chainTypes.DeployedContractBytecode{
+		Address:         contractAddress,
+		RuntimeBytecode: genesisAlloc[contractAddress].Code,
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, deployedContracts)
+
+	// Clone our chain and ensure the contract is announced on the clone as well.
+	clonedDeployedContracts := 0
+	_, err = chain.Clone(func(clonedChain *TestChain) error {
+		clonedChain.Events.ContractDeploymentAddedEventEmitter.Subscribe(func(event ContractDeploymentsAddedEvent) error {
+			assert.EqualValues(t, contractAddress, event.Contract.Address)
+			clonedDeployedContracts++
+			return nil
+		})
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, clonedDeployedContracts)
+}
+
+// TestChainPrecompiles creates a TestChain with precompiles provided by its config, and ensures they are installed and
+// executed as expected.
+func TestChainPrecompiles(t *testing.T) {
+	// Create a chain with our built-in precompiles, and a contract-backed precompile which returns the value 0x2a.
+	// Cheat codes are disabled to ensure built-in precompiles do not depend on them.
+	contractPrecompile := common.HexToAddress("0x1000")
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := types.GenesisAlloc{
+		sender:             types.Account{Balance: big.NewInt(1_000_000_000_000_000_000)},
+		contractPrecompile: types.Account{Balance: big.NewInt(0), Code: common.FromHex("0x602a60005260206000f3")},
+	}
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChainConfig.CheatCodeConfig.CheatCodesEnabled = false
+	testChainConfig.Precompiles = []config.PrecompileConfig{
+		{Builtin: config.BuiltinPrecompileP256Verify},
+		{Builtin: config.BuiltinPrecompileArbSys},
+		{Address: contractPrecompile, ContractName: "ReturnsConstant"},
+	}
+	chain, err := NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+
+	// Define a helper to call an address with the provided data and return its output.
+	call := func(to common.Address, data []byte) []byte {
+		msg := core.Message{
+			To:                &to,
+			From:              sender,
+			Nonce:             chain.State().GetNonce(sender),
+			Value:             big.NewInt(0),
+			GasLimit:          chain.BlockGasLimit,
+			GasPrice:          big.NewInt(1),
+			GasFeeCap:         big.NewInt(0),
+			GasTipCap:         big.NewInt(0),
+			Data:              data,
+			AccessList:        nil,
+			SkipAccountChecks: false,
+		}
+		result, err := chain.CallContract(&msg, nil)
+		assert.NoError(t, err)
+		assert.False(t, result.Failed())
+		return result.ReturnData
+	}
+
+	// Sign a message hash with a secp256r1 key and verify the P256VERIFY precompile accepts the signature.
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.New(rand.NewSource(0)))
+	assert.NoError(t, err)
+	hash := crypto.Keccak256([]byte("medusa"))
+	r, s, err := ecdsa.Sign(rand.New(rand.NewSource(0)), privateKey, hash)
+	assert.NoError(t, err)
+	input := make([]byte, 0, 160)
+	for _, value := range []*big.Int{new(big.Int).SetBytes(hash), r, s, privateKey.X, privateKey.Y} {
+		input = append(input, common.LeftPadBytes(value.Bytes(), 32)...)
+	}
+	assert.EqualValues(t, common.LeftPadBytes([]byte{1}, 32), call(P256VerifyPrecompileAddress, input))
+
+	// A signature over a different hash should not be accepted.
+	input[0] ^= 0xFF
+	assert.Empty(t, call(P256VerifyPrecompileAddress, input))
+
+	// The ArbSys stub should be installed at its default address with code, and report our chain ID.
+	arbSys, ok := chain.CheatCodeContracts()[ArbSysPrecompileAddress]
+	assert.True(t, ok)
+	assert.NotEmpty(t, chain.State().GetCode(ArbSysPrecompileAddress))
+	output := call(ArbSysPrecompileAddress, arbSys.Abi().Methods["arbChainID()"].ID)
+	assert.EqualValues(t, common.BigToHash(chain.ChainConfig().ChainID).Bytes(), output)
+
+	// Messages sent to L1 through the ArbSys stub should be assigned sequential identifiers, which are reverted along
+	// with their blocks and carried over to clones.
+	sendTxToL1, err := arbSys.Abi().Pack("sendTxToL1(address,bytes)", sender, []byte{})
+	assert.NoError(t, err)
+	sendTxToL1InBlock := func(chain *TestChain) []byte {
+		_, err := chain.PendingBlockCreate()
+		assert.NoError(t, err)
+		err = chain.PendingBlockAddTx(&core.Message{
+			To:                &ArbSysPrecompileAddress,
+			From:              sender,
+			Nonce:             chain.State().GetNonce(sender),
+			Value:             big.NewInt(0),
+			GasLimit:          chain.BlockGasLimit,
+			GasPrice:          big.NewInt(1),
+			GasFeeCap:         big.NewInt(0),
+			GasTipCap:         big.NewInt(0),
+			Data:              sendTxToL1,
+			AccessList:        nil,
+			SkipAccountChecks: false,
+		})
+		assert.NoError(t, err)
+		result := chain.PendingBlock().MessageResults[0].ExecutionResult
+		err = chain.PendingBlockCommit()
+		assert.NoError(t, err)
+		assert.False(t, result.Failed())
+		return result.ReturnData
+	}
+	assert.EqualValues(t, common.BigToHash(big.NewInt(0)).Bytes(), sendTxToL1InBlock(chain))
+	assert.EqualValues(t, common.BigToHash(big.NewInt(1)).Bytes(), sendTxToL1InBlock(chain))
+	clonedChain, err := chain.Clone(nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(2)).Bytes(), sendTxToL1InBlock(clonedChain))
+	err = chain.RevertToBlockNumber(1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(1)).Bytes(), sendTxToL1InBlock(chain))
+
+	// Our contract-backed precompile should execute its code.
+	assert.EqualValues(t, common.BigToHash(big.NewInt(0x2a)).Bytes(), call(contractPrecompile, nil))
+
+	// Contract-backed precompiles without code at genesis, and unknown built-in precompiles should result in an error.
+	testChainConfig.Precompiles = []config.PrecompileConfig{{Address: common.HexToAddress("0x2000"), ContractName: "Missing"}}
+	_, err = NewTestChain(genesisAlloc, testChainConfig)
+	assert.Error(t, err)
+	testChainConfig.Precompiles = []config.PrecompileConfig{{Builtin: "unknown"}}
+	_, err = NewTestChain(genesisAlloc, testChainConfig)
+	assert.Error(t, err)
+}
+
+// TestChainBlobs creates a TestChain and ensures messages carrying blob versioned hashes expose them through the
+// BLOBHASH opcode, and that the blob base fee of a block is exposed through the BLOBBASEFEE opcode.
+func TestChainBlobs(t *testing.T) {
+	// Define a contract which returns BLOBHASH for the index provided in calldata, followed by BLOBBASEFEE.
+	blobContract := common.HexToAddress("0x1234")
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := types.GenesisAlloc{
+		sender:       types.Account{Balance: big.NewInt(1_000_000_000_000_000_000)},
+		blobContract: types.Account{Balance: big.NewInt(0), Code: common.FromHex("0x600035496000524a60205260406000f3")},
+	}
+	chain, err := NewTestChain(genesisAlloc, nil)
+	assert.NoError(t, err)
+
+	// Define a message carrying two blobs, which queries the second blob hash.
+	blobHashes := []common.Hash{
+		common.HexToHash("0x0100000000000000000000000000000000000000000000000000000000000001"),
+		common.HexToHash("0x0100000000000000000000000000000000000000000000000000000000000002"),
+	}
+	msg := core.Message{
+		To:                &blobContract,
+		From:              sender,
+		Nonce:             chain.State().GetNonce(sender),
+		Value:             big.NewInt(0),
+		GasLimit:          chain.BlockGasLimit,
+		GasPrice:          big.NewInt(1),
+		GasFeeCap:         big.NewInt(0),
+		GasTipCap:         big.NewInt(0),
+		BlobGasFeeCap:     big.NewInt(0),
+		BlobHashes:        blobHashes,
+		Data:              common.BigToHash(big.NewInt(1)).Bytes(),
+		AccessList:        nil,
+		SkipAccountChecks: false,
+	}
+
+	// Calls should be executed with the blob base fee of the chain head, which defaults to the minimum.
+	result, err := chain.CallContract(&msg, nil)
+	assert.NoError(t, err)
+	assert.False(t, result.Failed())
+	assert.EqualValues(t, append(blobHashes[1].Bytes(), common.BigToHash(big.NewInt(1)).Bytes()...), result.ReturnData)
+
+	// Create a block with a custom blob base fee and verify our message is executed with it.
+	_, err = chain.PendingBlockCreateWithParameters(chain.HeadBlockNumber()+1, chain.Head().Header.Time+1, nil, nil, nil, big.NewInt(7))
+	assert.NoError(t, err)
+	err = chain.PendingBlockAddTx(&msg)
+	assert.NoError(t, err)
+	result = chain.PendingBlock().MessageResults[0].ExecutionResult
+	assert.False(t, result.Failed())
+	assert.EqualValues(t, append(blobHashes[1].Bytes(), common.BigToHash(big.NewInt(7)).Bytes()...), result.ReturnData)
+	err = chain.PendingBlockCommit()
+	assert.NoError(t, err)
+
+	// The blob base fee should be preserved when cloning the chain.
+	clonedChain, err := chain.Clone(nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, big.NewInt(7), clonedChain.Head().BlobBaseFee)
+
+	// Messages carrying blob hashes with an invalid version should not be executed.
+	msg.Nonce = chain.State().GetNonce(sender)
+	msg.BlobHashes = []common.Hash{common.HexToHash("0x02")}
+	_, err = chain.PendingBlockCreate()
+	assert.NoError(t, err)
+	err = chain.PendingBlockAddTx(&msg)
+	assert.Error(t, err)
+}
+
+// TestChainMockedCalls creates a TestChain and ensures calls mocked with cheat codes return the mocked data, that mocks
+// persist on clones and imports of the chain, that calls to precompiles cannot be mocked, and that mocks are removed
+// when the chain reverts the block which added them.
+func TestChainMockedCalls(t *testing.T) {
+	// Define a contract which returns the value 1, and a proxy which calls it and returns its output.
+	target := common.HexToAddress("0x1234")
+	proxy := common.HexToAddress("0x1235")
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := types.GenesisAlloc{
+		sender: types.Account{Balance: big.NewInt(1_000_000_000_000_000_000)},
+		target: types.Account{Balance: big.NewInt(0), Code: common.FromHex("0x600160005260206000f3")},
+		proxy:  types.Account{Balance: big.NewInt(0), Code: common.FromHex("0x602060006000600060006112345af15060206000f3")},
+	}
+	chain, err := NewTestChain(genesisAlloc, nil)
+	assert.NoError(t, err)
+
+	// Define a helper to create a message to an address with the provided data.
+	createMessage := func(to common.Address, data []byte) *core.Message {
+		return &core.Message{
+			To:                &to,
+			From:              sender,
+			Nonce:             chain.State().GetNonce(sender),
+			Value:             big.NewInt(0),
+			GasLimit:          chain.BlockGasLimit,
+			GasPrice:          big.NewInt(1),
+			GasFeeCap:         big.NewInt(0),
+			GasTipCap:         big.NewInt(0),
+			Data:              data,
+			AccessList:        nil,
+			SkipAccountChecks: false,
+		}
+	}
+
+	// Define a helper to call our proxy on a chain and return the output of our target.
+	callProxyOnChain := func(chain *TestChain) []byte {
+		msg := createMessage(proxy, nil)
+		msg.Nonce = chain.State().GetNonce(sender)
+		result, err := chain.CallContract(msg, nil)
+		assert.NoError(t, err)
+		assert.False(t, result.Failed())
+		return result.ReturnData
+	}
+	callProxy := func() []byte {
+		return callProxyOnChain(chain)
+	}
+	assert.EqualValues(t, common.BigToHash(big.NewInt(1)).Bytes(), callProxy())
+
+	// Mock calls to our target in a new block, and verify the mocked data is returned.
+	cheatCodeContract := chain.CheatCodeContracts()[StandardCheatcodeContractAddress]
+	mockCallData, err := cheatCodeContract.Abi().Pack("mockCall(address,bytes,bytes)", target, []byte{}, common.BigToHash(big.NewInt(5)).Bytes())
+	assert.NoError(t, err)
+	_, err = chain.PendingBlockCreate()
+	assert.NoError(t, err)
+	err = chain.PendingBlockAddTx(createMessage(StandardCheatcodeContractAddress, mockCallData))
+	assert.NoError(t, err)
+	err = chain.PendingBlockCommit()
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(5)).Bytes(), callProxy())
+
+	// Clone our chain, as the fuzzer does after setting up its chain, and verify the mock persists on the clone.
+	clonedChain, err := chain.Clone(nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(5)).Bytes(), callProxyOnChain(clonedChain))
+
+	// Export our chain and import it into a new chain, and verify the mock persists on the imported chain.
+	export, err := chain.Export()
+	assert.NoError(t, err)
+	b, err := json.Marshal(export)
+	assert.NoError(t, err)
+	var importedExport TestChainExport
+	err = json.Unmarshal(b, &importedExport)
+	assert.NoError(t, err)
+	importedChain, err := NewTestChain(genesisAlloc, nil)
+	assert.NoError(t, err)
+	err = importedChain.Import(&importedExport)
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(5)).Bytes(), callProxyOnChain(importedChain))
+
+	// Reverting the block which added the mock on our clone should remove it from the clone only.
+	err = clonedChain.RevertToBlockNumber(0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(1)).Bytes(), callProxyOnChain(clonedChain))
+	assert.EqualValues(t, common.BigToHash(big.NewInt(5)).Bytes(), callProxy())
+
+	// Mocking calls to a precompile should revert.
+	mockPrecompileData, err := cheatCodeContract.Abi().Pack("mockCall(address,bytes,bytes)", common.BytesToAddress([]byte{0x01}), []byte{}, []byte{})
+	assert.NoError(t, err)
+	result, err := chain.CallContract(createMessage(StandardCheatcodeContractAddress, mockPrecompileData), nil)
+	assert.NoError(t, err)
+	assert.True(t, result.Failed())
+
+	// Revert the block which added the mock, and verify the original data is returned.
+	err = chain.RevertToBlockNumber(0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(1)).Bytes(), callProxy())
+}
+
+// TestChainRememberedKeys creates a TestChain and ensures keys remembered with cheat codes can be used to sign digests,
+// that they persist on clones of the chain, and that they are forgotten when the chain reverts the block which
+// remembered them.
+func TestChainRememberedKeys(t *testing.T) {
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := types.GenesisAlloc{
+		sender: types.Account{Balance: big.NewInt(1_000_000_000_000_000_000)},
+	}
+	chain, err := NewTestChain(genesisAlloc, nil)
+	assert.NoError(t, err)
+
+	// Define a helper to create a message to our cheat code contract with the provided data on a chain.
+	createMessage := func(chain *TestChain, data []byte) *core.Message {
+		return &core.Message{
+			To:                &StandardCheatcodeContractAddress,
+			From:              sender,
+			Nonce:             chain.State().GetNonce(sender),
+			Value:             big.NewInt(0),
+			GasLimit:          chain.BlockGasLimit,
+			GasPrice:          big.NewInt(1),
+			GasFeeCap:         big.NewInt(0),
+			GasTipCap:         big.NewInt(0),
+			Data:              data,
+			AccessList:        nil,
+			SkipAccountChecks: false,
+		}
+	}
+
+	// Define a helper to sign a digest with the remembered key for our signer on a chain.
+	privateKey := big.NewInt(0x1234)
+	signer := crypto.PubkeyToAddress(crypto.ToECDSAUnsafe(common.LeftPadBytes(privateKey.Bytes(), 32)).PublicKey)
+	cheatCodeContract := chain.CheatCodeContracts()[StandardCheatcodeContractAddress]
+	signData, err := cheatCodeContract.Abi().Pack("sign(address,bytes32)", signer, [32]byte{1})
+	assert.NoError(t, err)
+	canSign := func(chain *TestChain) bool {
+		result, err := chain.CallContract(createMessage(chain, signData), nil)
+		assert.NoError(t, err)
+		return !result.Failed()
+	}
+	assert.False(t, canSign(chain))
+
+	// Remember our key in a new block, and verify it can be used to sign.
+	rememberKeyData, err := cheatCodeContract.Abi().Pack("rememberKey(uint256)", privateKey)
+	assert.NoError(t, err)
+	_, err = chain.PendingBlockCreate()
+	assert.NoError(t, err)
+	err = chain.PendingBlockAddTx(createMessage(chain, rememberKeyData))
+	assert.NoError(t, err)
+	err = chain.PendingBlockCommit()
+	assert.NoError(t, err)
+	assert.True(t, canSign(chain))
+
+	// Clone our chain, and verify the key remains remembered on the clone until it reverts the block which remembered
+	// it.
+	clonedChain, err := chain.Clone(nil)
+	assert.NoError(t, err)
+	assert.True(t, canSign(clonedChain))
+	err = clonedChain.RevertToBlockNumber(0)
+	assert.NoError(t, err)
+	assert.False(t, canSign(clonedChain))
+	assert.True(t, canSign(chain))
+}
+
+// TestChainCheatCodePolicy creates a TestChain which restricts the callers of cheat codes and the commands the ffi cheat
+// code may execute, and ensures calls are only permitted as configured.
+func TestChainCheatCodePolicy(t *testing.T) {
+	allowedSender := common.HexToAddress("0x0707")
+	deniedSender := common.HexToAddress("0x0808")
+	genesisAlloc := types.GenesisAlloc{
+		allowedSender: types.Account{Balance: big.NewInt(1_000_000_000_000_000_000)},
+		deniedSender:  types.Account{Balance: big.NewInt(1_000_000_000_000_000_000)},
+	}
+
+	// Create a chain config which only allows our sender to call cheat codes, and only allows the ffi cheat code to
+	// echo lowercase words.
+	testChainConfig, err := config.DefaultTestChainConfig()
+	assert.NoError(t, err)
+	testChainConfig.CheatCodeConfig.EnableFFI = true
+	testChainConfig.CheatCodeConfig.AllowedCallers = []common.Address{allowedSender}
+	testChainConfig.CheatCodeConfig.AllowedFFICommands = []config.FFICommandConfig{
+		{Executable: "echo", Arguments: []string{"[a-z]+"}},
+	}
+	chain, err := NewTestChain(genesisAlloc, testChainConfig)
+	assert.NoError(t, err)
+
+	// Define a helper to call the ffi cheat code with the provided command from the provided sender.
+	cheatCodeContract := chain.CheatCodeContracts()[StandardCheatcodeContractAddress]
+	callFFI := func(sender common.Address, cmdAndInputs ...string) bool {
+		data, err := cheatCodeContract.Abi().Pack("ffi(string[])", cmdAndInputs)
+		assert.NoError(t, err)
+		result, err := chain.CallContract(&core.Message{
+			To:                &StandardCheatcodeContractAddress,
+			From:              sender,
+			Nonce:             chain.State().GetNonce(sender),
+			Value:             big.NewInt(0),
+			GasLimit:          chain.BlockGasLimit,
+			GasPrice:          big.NewInt(1),
+			GasFeeCap:         big.NewInt(0),
+			GasTipCap:         big.NewInt(0),
+			Data:              data,
+			AccessList:        nil,
+			SkipAccountChecks: false,
+		}, nil)
+		assert.NoError(t, err)
+		return !result.Failed()
+	}
+
+	// Verify only our allowed sender may execute allowed commands, and arguments must match their patterns entirely.
+	assert.True(t, callFFI(allowedSender, "echo", "hello"))
+	assert.False(t, callFFI(deniedSender, "echo", "hello"))
+	assert.False(t, callFFI(allowedSender, "echo", "hello world"))
+	assert.False(t, callFFI(allowedSender, "echo", "hello", "world"))
+	assert.False(t, callFFI(allowedSender, "ls", "hello"))
+
+	// Verify a chain cannot be created with an argument pattern which does not compile.
+	testChainConfig.CheatCodeConfig.AllowedFFICommands[0].Arguments = []string{"[a-z"}
+	_, err = NewTestChain(genesisAlloc, testChainConfig)
+	assert.Error(t, err)
+}
+
+// TestChainExternalInputsRead ensures a TestChain reports whether cheat codes which read inputs from outside the chain
+// were executed on it.
+func TestChainExternalInputsRead(t *testing.T) {
+	sender := common.HexToAddress("0x0707")
+	genesisAlloc := types.GenesisAlloc{
+		sender: types.Account{Balance: big.NewInt(1_000_000_000_000_000_000)},
+	}
+	chain, err := NewTestChain(genesisAlloc, nil)
+	assert.NoError(t, err)
+
+	// Define a helper to call a cheat code with the provided input data.
+	callCheatCode := func(data []byte) {
+		result, err := chain.CallContract(&core.Message{
+			To:                &StandardCheatcodeContractAddress,
+			From:              sender,
+			Nonce:             chain.State().GetNonce(sender),
+			Value:             big.NewInt(0),
+			GasLimit:          chain.BlockGasLimit,
+			GasPrice:          big.NewInt(1),
+			GasFeeCap:         big.NewInt(0),
+			GasTipCap:         big.NewInt(0),
+			Data:              data,
+			AccessList:        nil,
+			SkipAccountChecks: false,
+		}, nil)
+		assert.NoError(t, err)
+		assert.False(t, result.Failed())
+	}
+
+	// Calling a cheat code which does not read external inputs should not be reported.
+	cheatCodeContract := chain.CheatCodeContracts()[StandardCheatcodeContractAddress]
+	data, err := cheatCodeContract.Abi().Pack("addr(uint256)", big.NewInt(1))
+	assert.NoError(t, err)
+	callCheatCode(data)
+	assert.False(t, chain.ExternalInputsRead())
+
+	// Reading an environment variable should be reported, even if it is not set.
+	data, err = cheatCodeContract.Abi().Pack("envOr(string,uint256)", "MEDUSA_TEST_UNSET_VARIABLE", big.NewInt(1))
+	assert.NoError(t, err)
+	callCheatCode(data)
+	assert.True(t, chain.ExternalInputsRead())
+}
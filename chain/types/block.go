@@ -1,9 +1,12 @@
 package types
 
 import (
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
 )
 
 // Block represents a rudimentary block structure generated by sending messages to a test chain.
@@ -14,6 +17,10 @@ type Block struct {
 	// header represents the block header for this current block.
 	Header *types.Header
 
+	// BlobBaseFee represents the blob base fee (EIP-4844) messages in this block are executed with. The test chain does
+	// not track excess blob gas across blocks, so this is stored alongside the header rather than derived from it.
+	BlobBaseFee *big.Int
+
 	// Messages represent internal EVM core.Message objects. Messages are derived from transactions after validation
 	// of a transaction occurs and can be thought of as an internal EVM transaction. It contains typical transaction
 	// fields plainly (e.g., no transaction signature is included, the sender is derived and simply supplied as a field
@@ -24,12 +31,14 @@ type Block struct {
 	MessageResults []*MessageResults
 }
 
-// NewBlock returns a new Block with the provided parameters.
+// NewBlock returns a new Block with the provided parameters. The blob base fee is set to the minimum defined by
+// EIP-4844.
 func NewBlock(header *types.Header) *Block {
 	// Create our block and return it
 	block := &Block{
 		Hash:           header.Hash(),
 		Header:         header,
+		BlobBaseFee:    big.NewInt(params.BlobTxMinBlobGasprice),
 		Messages:       make([]*core.Message, 0),
 		MessageResults: make([]*MessageResults, 0),
 	}
@@ -9,4 +9,7 @@ const (
 
 	// TargetFlagDescription stores the description for the --target flag
 	TargetFlagDescription = "target contract or directory to compile"
+
+	// DefaultRPCAddress describes the default address the serve command serves JSON-RPC requests on
+	DefaultRPCAddress = "127.0.0.1:8545"
 )
@@ -0,0 +1,179 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"github.com/crytic/medusa/cmd/exitcodes"
+	"github.com/crytic/medusa/logging/colors"
+
+	"github.com/crytic/medusa/fuzzing"
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// serveCmd represents the command provider for serving a test chain over JSON-RPC
+var serveCmd = &cobra.Command{
+	Use:               "serve",
+	Short:             "Replays a call sequence and serves the resulting chain over JSON-RPC",
+	Long:              `Sets up the test chain, replays a call sequence (by default, the most recent test result in the corpus) on it, and serves the resulting chain over JSON-RPC for debugging`,
+	Args:              cmdValidateServeArgs,
+	ValidArgsFunction: cmdValidServeArgs,
+	RunE:              cmdRunServe,
+	SilenceUsage:      true,
+	SilenceErrors:     true,
+}
+
+func init() {
+	// Add all the flags allowed for the serve command
+	err := addServeFlags()
+	if err != nil {
+		cmdLogger.Panic("Failed to initialize the serve command", err)
+	}
+
+	// Add the serve command and its associated flags to the root command
+	rootCmd.AddCommand(serveCmd)
+}
+
+// cmdValidServeArgs will return which flags and sub-commands are valid for dynamic completion for the serve command
+func cmdValidServeArgs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	// Gather a list of flags that are available to be used in the current command but have not been used yet
+	var unusedFlags []string
+
+	// Examine all the flags, and add any flags that have not been set in the current command line
+	// to a list of unused flags
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if !flag.Changed {
+			unusedFlags = append(unusedFlags, "--"+flag.Name)
+		}
+	})
+	// Provide a list of flags that can be used in the current command (but have not been used yet)
+	// for autocompletion suggestions
+	return unusedFlags, cobra.ShellCompDirectiveNoFileComp
+}
+
+// cmdValidateServeArgs makes sure that there are no positional arguments provided to the serve command
+func cmdValidateServeArgs(cmd *cobra.Command, args []string) error {
+	// Make sure we have no positional args
+	if err := cobra.NoArgs(cmd, args); err != nil {
+		err = fmt.Errorf("serve does not accept any positional arguments, only flags and their associated values")
+		cmdLogger.Error("Failed to validate args to the serve command", err)
+		return err
+	}
+	return nil
+}
+
+// cmdRunServe executes the CLI serve command. The project configuration is resolved in the same way as the fuzz
+// command: either a custom config file (via --config), the default (medusa.json), or the default project
+// configuration if neither is found.
+func cmdRunServe(cmd *cobra.Command, args []string) error {
+	var projectConfig *config.ProjectConfig
+
+	// Check to see if --config flag was used and store the value of --config flag
+	configFlagUsed := cmd.Flags().Changed("config")
+	configPath, err := cmd.Flags().GetString("config")
+	if err != nil {
+		cmdLogger.Error("Failed to run the serve command", err)
+		return err
+	}
+
+	// If --config was not used, look for `medusa.json` in the current work directory
+	if !configFlagUsed {
+		workingDirectory, err := os.Getwd()
+		if err != nil {
+			cmdLogger.Error("Failed to run the serve command", err)
+			return err
+		}
+		configPath = filepath.Join(workingDirectory, DefaultProjectConfigFilename)
+	}
+
+	// Check to see if the file exists at configPath
+	_, existenceError := os.Stat(configPath)
+
+	// If the file was found, try to read it and throw an error if something goes wrong
+	if existenceError == nil {
+		cmdLogger.Info("Reading the configuration file at: ", colors.Bold, configPath, colors.Reset)
+		projectConfig, err = config.ReadProjectConfigFromFile(configPath, DefaultCompilationPlatform)
+		if err != nil {
+			cmdLogger.Error("Failed to run the serve command", err)
+			return err
+		}
+	}
+
+	// If the --config flag was used, and we couldn't find the file, we'll throw an error
+	if configFlagUsed && existenceError != nil {
+		cmdLogger.Error("Failed to run the serve command", err)
+		return existenceError
+	}
+
+	// If the --config flag was not used and medusa.json was not found, use the default project config
+	if !configFlagUsed && existenceError != nil {
+		cmdLogger.Warn(fmt.Sprintf("Unable to find the config file at %v, will use the default project configuration for the "+
+			"%v compilation platform instead", configPath, DefaultCompilationPlatform))
+
+		projectConfig, err = config.GetDefaultProjectConfig(DefaultCompilationPlatform)
+		if err != nil {
+			cmdLogger.Error("Failed to run the serve command", err)
+			return err
+		}
+	}
+
+	// Update the project configuration given whatever flags were set using the CLI
+	err = updateProjectConfigWithServeFlags(cmd, projectConfig)
+	if err != nil {
+		cmdLogger.Error("Failed to run the serve command", err)
+		return err
+	}
+
+	// Obtain our call sequence path and RPC address. The call sequence path is made absolute, as it is relative to the
+	// current working directory, which we are about to change.
+	callSequencePath, err := cmd.Flags().GetString("sequence")
+	if err != nil {
+		cmdLogger.Error("Failed to run the serve command", err)
+		return err
+	}
+	if callSequencePath != "" {
+		callSequencePath, err = filepath.Abs(callSequencePath)
+		if err != nil {
+			cmdLogger.Error("Failed to run the serve command", err)
+			return err
+		}
+	}
+	rpcAddress, err := cmd.Flags().GetString("rpc")
+	if err != nil {
+		cmdLogger.Error("Failed to run the serve command", err)
+		return err
+	}
+
+	// Change our working directory to the parent directory of the project configuration file, as paths in the
+	// configuration are relative to it.
+	err = os.Chdir(filepath.Dir(configPath))
+	if err != nil {
+		cmdLogger.Error("Failed to run the serve command", err)
+		return err
+	}
+
+	// Create our fuzzer, which compiles our targets and sets up our chain.
+	fuzzer, fuzzErr := fuzzing.NewFuzzer(*projectConfig)
+	if fuzzErr != nil {
+		return exitcodes.NewErrorWithExitCode(fuzzErr, exitcodes.ExitCodeHandledError)
+	}
+
+	// Stop serving on keyboard interrupts
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	go func() {
+		<-c
+		fuzzer.Stop()
+	}()
+
+	// Serve our chain until we are interrupted.
+	fuzzErr = fuzzer.Serve(rpcAddress, callSequencePath)
+	if fuzzErr != nil {
+		return exitcodes.NewErrorWithExitCode(fuzzErr, exitcodes.ExitCodeHandledError)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/spf13/cobra"
+)
+
+// addServeFlags adds the various flags for the serve command
+func addServeFlags() error {
+	// Get the default project config and throw an error if we cant
+	defaultConfig, err := config.GetDefaultProjectConfig(DefaultCompilationPlatform)
+	if err != nil {
+		return err
+	}
+
+	// Prevent alphabetical sorting of usage message
+	serveCmd.Flags().SortFlags = false
+
+	// Config file
+	serveCmd.Flags().String("config", "", "path to config file")
+
+	// Compilation Target
+	serveCmd.Flags().String("compilation-target", "", TargetFlagDescription)
+
+	// Target contracts
+	serveCmd.Flags().StringSlice("target-contracts", []string{},
+		fmt.Sprintf("target contracts for fuzz testing (unless a config file is provided, default is %v)", defaultConfig.Fuzzing.TargetContracts))
+
+	// Corpus directory
+	serveCmd.Flags().String("corpus-dir", "",
+		fmt.Sprintf("directory path for corpus items and coverage reports (unless a config file is provided, default is %q)", defaultConfig.Fuzzing.CorpusDirectory))
+
+	// Call sequence
+	serveCmd.Flags().String("sequence", "",
+		"path to a call sequence file to replay (default is the most recent test result in the corpus directory)")
+
+	// RPC address
+	serveCmd.Flags().String("rpc", DefaultRPCAddress, "address to serve JSON-RPC requests on")
+
+	// Logging color
+	serveCmd.Flags().Bool("no-color", false, "disabled colored terminal output")
+
+	return nil
+}
+
+// updateProjectConfigWithServeFlags will update the given projectConfig with any CLI arguments that were provided to the serve command
+func updateProjectConfigWithServeFlags(cmd *cobra.Command, projectConfig *config.ProjectConfig) error {
+	var err error
+
+	// If --compilation-target was used
+	if cmd.Flags().Changed("compilation-target") {
+		// Get the new target
+		newTarget, err := cmd.Flags().GetString("compilation-target")
+		if err != nil {
+			return err
+		}
+
+		err = projectConfig.Compilation.SetTarget(newTarget)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update target contracts
+	if cmd.Flags().Changed("target-contracts") {
+		projectConfig.Fuzzing.TargetContracts, err = cmd.Flags().GetStringSlice("target-contracts")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update corpus directory
+	if cmd.Flags().Changed("corpus-dir") {
+		projectConfig.Fuzzing.CorpusDirectory, err = cmd.Flags().GetString("corpus-dir")
+		if err != nil {
+			return err
+		}
+	}
+
+	// Update logging color mode
+	if cmd.Flags().Changed("no-color") {
+		projectConfig.Logging.NoColor, err = cmd.Flags().GetBool("no-color")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -154,6 +154,7 @@ func (c *CryticCompilationConfig) Compile() ([]types.Compilation, string, error)
 		Abi           any    `json:"abi"`
 		Bin           string `json:"bin"`
 		BinRuntime    string `json:"bin-runtime"`
+		Metadata      any    `json:"metadata"`
 	}
 	type solcExportData struct {
 		Sources   map[string]solcSourceUnit     `json:"sources"`
@@ -258,6 +259,7 @@ func (c *CryticCompilationConfig) Compile() ([]types.Compilation, string, error)
 				SrcMapsInit:     contract.SrcMap,
 				SrcMapsRuntime:  contract.SrcMapRuntime,
 				Kind:            contractKinds[contractName],
+				EVMVersion:      types.GetCompilerMetadataEVMVersion(contract.Metadata),
 			}
 		}
 
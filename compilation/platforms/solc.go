@@ -72,10 +72,10 @@ func (s *SolcCompilationConfig) SetSolcOutputOptions(v *semver.Version) string {
 		return "abi,ast,bin,bin-runtime,srcmap,srcmap-runtime,userdoc,devdoc"
 	} else if useCompactFormat {
 		// Both 'hashes' and 'compact-format' are allowed as outputOptions
-		return "abi,ast,bin,bin-runtime,srcmap,srcmap-runtime,userdoc,devdoc,hashes,compact-format"
+		return "abi,ast,bin,bin-runtime,srcmap,srcmap-runtime,userdoc,devdoc,hashes,metadata,compact-format"
 	} else {
 		// Can't use 'compact-format' but 'hashes' is allowed as outputOption
-		return "abi,ast,bin,bin-runtime,srcmap,srcmap-runtime,userdoc,devdoc,hashes"
+		return "abi,ast,bin,bin-runtime,srcmap,srcmap-runtime,userdoc,devdoc,hashes,metadata"
 	}
 }
 func (s *SolcCompilationConfig) Compile() ([]types.Compilation, string, error) {
@@ -195,6 +195,7 @@ func (s *SolcCompilationConfig) Compile() ([]types.Compilation, string, error) {
 			SrcMapsInit:     contract.Info.SrcMap.(string),
 			SrcMapsRuntime:  contract.Info.SrcMapRuntime,
 			Kind:            contractKinds[contractName],
+			EVMVersion:      types.GetCompilerMetadataEVMVersion(contract.Info.Metadata),
 		}
 	}
 
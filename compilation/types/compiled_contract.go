@@ -32,6 +32,10 @@ type CompiledContract struct {
 
 	// Kind describes the kind of contract, i.e. contract, library, interface.
 	Kind ContractKind
+
+	// EVMVersion describes the EVM version (hard fork) the contract was compiled for, as declared by the compiler
+	// metadata (e.g. "shanghai"). This is empty if the compiler metadata was not available.
+	EVMVersion string
 }
 
 // IsMatch returns a boolean indicating whether provided contract bytecode is a match to this compiled contract
@@ -2,6 +2,7 @@ package types
 
 import (
 	"bytes"
+	"encoding/json"
 
 	"github.com/fxamacker/cbor"
 )
@@ -27,6 +28,31 @@ var byteCodeHashMetadataKeys = [...]string{
 	"ipfs",
 }
 
+// GetCompilerMetadataEVMVersion obtains the EVM version a contract was compiled for from the JSON metadata emitted
+// by the Solidity compiler (not to be confused with the CBOR-encoded ContractMetadata embedded in bytecode). The
+// metadata may be provided either as a JSON string, or as an already decoded JSON object.
+// Returns the EVM version declared in the metadata's settings, or an empty string if it could not be obtained.
+func GetCompilerMetadataEVMVersion(metadata any) string {
+	// If our metadata is a JSON string, decode it first.
+	if metadataStr, ok := metadata.(string); ok {
+		if err := json.Unmarshal([]byte(metadataStr), &metadata); err != nil {
+			return ""
+		}
+	}
+
+	// Obtain the EVM version from the metadata's settings.
+	metadataDict, ok := metadata.(map[string]any)
+	if !ok {
+		return ""
+	}
+	settings, ok := metadataDict["settings"].(map[string]any)
+	if !ok {
+		return ""
+	}
+	evmVersion, _ := settings["evmVersion"].(string)
+	return evmVersion
+}
+
 // ExtractContractMetadata extracts contract metadata from provided byte code and returns it. If contract metadata
 // could not be extracted, nil is returned.
 func ExtractContractMetadata(bytecode []byte) *ContractMetadata {
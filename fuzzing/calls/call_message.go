@@ -58,6 +58,10 @@ type CallMessage struct {
 	// set, allowing Data to be sourced from method ABI input arguments instead.
 	DataAbiValues *CallMessageDataAbiValues `json:"dataAbiValues,omitempty"`
 
+	// BlobHashes represents the versioned hashes of blobs (EIP-4844) carried by the message, which are made available
+	// to the receiver through the BLOBHASH opcode. If this is empty, the message carries no blobs.
+	BlobHashes []common.Hash `json:"blobHashes,omitempty"`
+
 	// AccessList represents a core.Message's AccessList parameter which represents the storage slots and contracts
 	// that will be accessed during the execution of this message.
 	AccessList coreTypes.AccessList
@@ -188,14 +192,16 @@ func (m *CallMessage) Clone() (*CallMessage, error) {
 		GasTipCap:         new(big.Int).Set(m.GasTipCap),
 		Data:              slices.Clone(m.Data),
 		DataAbiValues:     clonedAbiValues,
+		BlobHashes:        slices.Clone(m.BlobHashes),
 		AccessList:        m.AccessList,
 		SkipAccountChecks: m.SkipAccountChecks,
 	}
 	return clone, nil
 }
 
+// ToCoreMessage converts the call message to a core.Message which can be applied by the EVM.
 func (m *CallMessage) ToCoreMessage() *core.Message {
-	return &core.Message{
+	msg := &core.Message{
 		To:                m.To,
 		From:              m.From,
 		Nonce:             m.Nonce,
@@ -208,4 +214,12 @@ func (m *CallMessage) ToCoreMessage() *core.Message {
 		AccessList:        m.AccessList,
 		SkipAccountChecks: m.SkipAccountChecks,
 	}
+
+	// Blob hashes must only be set if blobs are carried, otherwise the message is treated as an invalid blob
+	// transaction. Similar to the fee and tip cap, a zero blob fee cap bypasses blob base fee validation.
+	if len(m.BlobHashes) > 0 {
+		msg.BlobHashes = slices.Clone(m.BlobHashes)
+		msg.BlobGasFeeCap = big.NewInt(0)
+	}
+	return msg
 }
@@ -3,6 +3,7 @@ package calls
 import (
 	"encoding/binary"
 	"fmt"
+	"math/big"
 	"strconv"
 
 	"github.com/crytic/medusa/chain"
@@ -80,6 +81,60 @@ func (cs CallSequence) Clone() (CallSequence, error) {
 	return r, nil
 }
 
+// WithoutDiscarded creates a copy of the call sequence without any elements whose calls were discarded because an
+// assumption made with the assume cheat code was not met. If a removed element created a new block, its block delays
+// are carried over to the next remaining element executed on the same chain, along with its block parameters if that
+// element was included in the same block, so the remaining elements are executed in the same blocks.
+// Returns the call sequence without discarded elements, or an error if one occurs.
+func (cs CallSequence) WithoutDiscarded() (CallSequence, error) {
+	r := make(CallSequence, 0, len(cs))
+	removedBlockElements := make(map[string]*CallSequenceElement)
+	for _, cse := range cs {
+		// If this element was discarded, remove it, tracking the block delays and parameters to carry over if it
+		// created a new block.
+		if cse.Discarded() {
+			if cse.BlockNumberDelay == 0 {
+				continue
+			}
+			removedBlockElement := removedBlockElements[cse.Chain]
+			if removedBlockElement == nil {
+				removedBlockElement = &CallSequenceElement{}
+				removedBlockElements[cse.Chain] = removedBlockElement
+			}
+			removedBlockElement.BlockNumberDelay += cse.BlockNumberDelay
+			removedBlockElement.BlockTimestampDelay += cse.BlockTimestampDelay
+			removedBlockElement.BlockBaseFee = cse.BlockBaseFee
+			removedBlockElement.BlockPrevRandao = cse.BlockPrevRandao
+			removedBlockElement.BlockBlobBaseFee = cse.BlockBlobBaseFee
+			continue
+		}
+
+		// If no removed element on this element's chain created a block, we can keep it as is.
+		removedBlockElement := removedBlockElements[cse.Chain]
+		if removedBlockElement == nil {
+			r = append(r, cse)
+			continue
+		}
+		delete(removedBlockElements, cse.Chain)
+
+		// Otherwise carry over the delays of the removed elements. If this element was included in the block the last
+		// of them created, it now creates that block, so it uses its parameters.
+		clone, err := cse.Clone()
+		if err != nil {
+			return nil, err
+		}
+		if clone.BlockNumberDelay == 0 {
+			clone.BlockBaseFee = removedBlockElement.BlockBaseFee
+			clone.BlockPrevRandao = removedBlockElement.BlockPrevRandao
+			clone.BlockBlobBaseFee = removedBlockElement.BlockBlobBaseFee
+		}
+		clone.BlockNumberDelay += removedBlockElement.BlockNumberDelay
+		clone.BlockTimestampDelay += removedBlockElement.BlockTimestampDelay
+		r = append(r, clone)
+	}
+	return r, nil
+}
+
 // Hash calculates a unique hash which represents the uniqueness of the call sequence and each element in it. It does
 // not hash execution/result data.
 // Returns the calculated hash, or an error if one occurs.
@@ -90,6 +145,20 @@ func (cs CallSequence) Hash() (common.Hash, error) {
 	for _, cse := range cs {
 		var temp [8]byte
 
+		// Hash the chain the call is executed on, if it is not the primary chain, and whether it was relayed.
+		if cse.Chain != "" {
+			_, err := hashProvider.Write([]byte(cse.Chain))
+			if err != nil {
+				return common.Hash{}, err
+			}
+		}
+		if cse.Relayed {
+			_, err := hashProvider.Write([]byte{1})
+			if err != nil {
+				return common.Hash{}, err
+			}
+		}
+
 		// Hash block number delay
 		binary.LittleEndian.PutUint64(temp[:], cse.BlockNumberDelay)
 		_, err := hashProvider.Write(temp[:])
@@ -104,6 +173,36 @@ func (cs CallSequence) Hash() (common.Hash, error) {
 			return common.Hash{}, err
 		}
 
+		// Hash block base fee, prevrandao, and blob base fee, if they are set
+		if cse.BlockBaseFee != nil {
+			_, err = hashProvider.Write(common.BigToHash(cse.BlockBaseFee).Bytes())
+			if err != nil {
+				return common.Hash{}, err
+			}
+		}
+		if cse.BlockPrevRandao != nil {
+			_, err = hashProvider.Write(cse.BlockPrevRandao.Bytes())
+			if err != nil {
+				return common.Hash{}, err
+			}
+		}
+		if cse.BlockBlobBaseFee != nil {
+			_, err = hashProvider.Write(common.BigToHash(cse.BlockBlobBaseFee).Bytes())
+			if err != nil {
+				return common.Hash{}, err
+			}
+		}
+
+		// Hash blob hashes, as they are not represented in the message's transaction hash below.
+		if cse.Call != nil {
+			for _, blobHash := range cse.Call.BlobHashes {
+				_, err = hashProvider.Write(blobHash.Bytes())
+				if err != nil {
+					return common.Hash{}, err
+				}
+			}
+		}
+
 		// Try to pack the call message and obtain a hash for it.
 		// This may panic if the ABI changed and the ABI method/function targeted does not resolve or the call
 		// could otherwise not be packed/serialized. If it does, we use fixed hash data instead.
@@ -143,6 +242,14 @@ type CallSequenceElement struct {
 	// Call represents the underlying message call.
 	Call *CallMessage `json:"call"`
 
+	// Chain describes the name of the chain the call should be executed on, among the chains linked to the chain the
+	// call sequence is executed on. If empty, the call is executed on the chain the call sequence is executed on.
+	Chain string `json:"chain,omitempty"`
+
+	// Relayed describes whether the call delivers a message emitted on another chain, rather than being sent by one of
+	// the fuzzer's senders.
+	Relayed bool `json:"relayed,omitempty"`
+
 	// BlockNumberDelay defines how much the block number should advance when executing this transaction, compared to
 	// the last executed transaction. If zero, this indicates the call should be included in the current pending block.
 	// This number is *suggestive*: if delay specifies we should add a tx to a block which is full, it will be added to
@@ -157,6 +264,21 @@ type CallSequenceElement struct {
 	// value will not be used.
 	BlockTimestampDelay uint64 `json:"blockTimestampDelay"`
 
+	// BlockBaseFee defines the base fee of the block created to include this transaction. If nil, the chain's default
+	// base fee is used.
+	// This value is only used if a new block is created for this transaction (see BlockNumberDelay).
+	BlockBaseFee *big.Int `json:"blockBaseFee,omitempty"`
+
+	// BlockPrevRandao defines the prevrandao value of the block created to include this transaction. If nil, the
+	// chain's default prevrandao value is used.
+	// This value is only used if a new block is created for this transaction (see BlockNumberDelay).
+	BlockPrevRandao *common.Hash `json:"blockPrevRandao,omitempty"`
+
+	// BlockBlobBaseFee defines the blob base fee of the block created to include this transaction. If nil, the
+	// chain's default blob base fee is used.
+	// This value is only used if a new block is created for this transaction (see BlockNumberDelay).
+	BlockBlobBaseFee *big.Int `json:"blockBlobBaseFee,omitempty"`
+
 	// ChainReference describes the inclusion of the Call as a transaction in a block. This block may not yet be
 	// committed to its underlying chain if this is a CallSequenceElement was just executed. Additional transactions
 	// may be included before the block is committed. This reference will remain compatible after the block finalizes.
@@ -191,11 +313,26 @@ func (cse *CallSequenceElement) Clone() (*CallSequenceElement, error) {
 	clone := &CallSequenceElement{
 		Contract:            cse.Contract,
 		Call:                clonedCall,
+		Chain:               cse.Chain,
+		Relayed:             cse.Relayed,
 		BlockNumberDelay:    cse.BlockNumberDelay,
 		BlockTimestampDelay: cse.BlockTimestampDelay,
+		BlockBaseFee:        nil,
+		BlockPrevRandao:     nil,
+		BlockBlobBaseFee:    nil,
 		ChainReference:      cse.ChainReference,
 		ExecutionTrace:      cse.ExecutionTrace,
 	}
+	if cse.BlockBaseFee != nil {
+		clone.BlockBaseFee = new(big.Int).Set(cse.BlockBaseFee)
+	}
+	if cse.BlockPrevRandao != nil {
+		blockPrevRandao := *cse.BlockPrevRandao
+		clone.BlockPrevRandao = &blockPrevRandao
+	}
+	if cse.BlockBlobBaseFee != nil {
+		clone.BlockBlobBaseFee = new(big.Int).Set(cse.BlockBlobBaseFee)
+	}
 	return clone, nil
 }
 
@@ -219,6 +356,15 @@ func (cse *CallSequenceElement) Method() (*abi.Method, error) {
 	return method, err
 }
 
+// Discarded indicates whether the CallSequenceElement.Call was executed and reverted because an assumption made with
+// the assume cheat code was not met, in which case its input should be discarded rather than treated as a revert.
+func (cse *CallSequenceElement) Discarded() bool {
+	if cse.ChainReference == nil {
+		return false
+	}
+	return chain.GetCheatCodeAssumptionViolated(cse.ChainReference.MessageResults())
+}
+
 // String returns a displayable string representing the CallSequenceElement.
 func (cse *CallSequenceElement) String() string {
 	// Obtain our contract name
@@ -235,31 +381,46 @@ func (cse *CallSequenceElement) String() string {
 	}
 
 	// Next decode our arguments (we jump four bytes to skip the function selector)
-	args, err := method.Inputs.Unpack(cse.Call.Data[4:])
 	argsText := "<unable to unpack args>"
-	if err == nil {
-		argsText, err = valuegeneration.EncodeABIArgumentsToString(method.Inputs, args)
-		if err != nil {
-			argsText = "<unresolved args>"
+	if method != nil && len(cse.Call.Data) >= 4 {
+		args, err := method.Inputs.Unpack(cse.Call.Data[4:])
+		if err == nil {
+			argsText, err = valuegeneration.EncodeABIArgumentsToString(method.Inputs, args)
+			if err != nil {
+				argsText = "<unresolved args>"
+			}
 		}
 	}
 
+	// If the call is executed on a linked chain or relays a message, note it.
+	chainText := ""
+	if cse.Chain != "" {
+		chainText = fmt.Sprintf("chain=%s, ", cse.Chain)
+	}
+	if cse.Relayed {
+		chainText += "relayed, "
+	}
+
 	// If we have runtime info, populate it
 	blockNumberStr := "n/a"
 	blockTimeStr := "n/a"
+	blockBaseFeeStr := "n/a"
 	if cse.ChainReference != nil {
 		blockNumberStr = cse.ChainReference.Block.Header.Number.String()
 		blockTimeStr = strconv.FormatUint(cse.ChainReference.Block.Header.Time, 10)
+		blockBaseFeeStr = cse.ChainReference.Block.Header.BaseFee.String()
 	}
 
 	// Return a formatted string representing this element.
 	return fmt.Sprintf(
-		"%s.%s(%s) (block=%s, time=%s, gas=%d, gasprice=%s, value=%s, sender=%s)",
+		"%s.%s(%s) (%sblock=%s, time=%s, basefee=%s, gas=%d, gasprice=%s, value=%s, sender=%s)",
 		contractName,
 		methodName,
 		argsText,
+		chainText,
 		blockNumberStr,
 		blockTimeStr,
+		blockBaseFeeStr,
 		cse.Call.GasLimit,
 		cse.Call.GasPrice.String(),
 		cse.Call.Value.String(),
@@ -267,9 +428,24 @@ func (cse *CallSequenceElement) String() string {
 	)
 }
 
+// ResolveChain obtains the chain the CallSequenceElement should be executed on, given the chain the call sequence is
+// executed on. If the element does not refer to a chain, it is executed on the provided chain. Otherwise, it is
+// executed on the chain it refers to among those linked to the same primary chain.
+// Returns the chain, or an error if no chain is linked under the name the element refers to.
+func (cse *CallSequenceElement) ResolveChain(testChain *chain.TestChain) (*chain.TestChain, error) {
+	if cse.Chain == "" {
+		return testChain, nil
+	}
+	resolvedChain := testChain.LinkedChain(cse.Chain)
+	if resolvedChain == nil {
+		return nil, fmt.Errorf("could not resolve chain '%s' for call sequence element", cse.Chain)
+	}
+	return resolvedChain, nil
+}
+
 // AttachExecutionTrace takes a given chain which executed the call sequence element, and a list of contract definitions,
 // and it replays the call with an execution tracer attached to it, it then sets CallSequenceElement.ExecutionTrace to
-// the resulting trace.
+// the resulting trace. If the element was executed on a linked chain, the call is replayed on that chain.
 // Returns an error if one occurred.
 func (cse *CallSequenceElement) AttachExecutionTrace(chain *chain.TestChain, contractDefinitions fuzzingTypes.Contracts) error {
 	// Verify the element has been executed before.
@@ -277,9 +453,14 @@ func (cse *CallSequenceElement) AttachExecutionTrace(chain *chain.TestChain, con
 		return fmt.Errorf("failed to resolve execution trace as the chain reference is nil, indicating the call sequence element has never been executed")
 	}
 
-	var err error
+	// Resolve the chain the element was executed on.
+	executedChain, err := cse.ResolveChain(chain)
+	if err != nil {
+		return err
+	}
+
 	// Perform our call with the given trace
-	_, cse.ExecutionTrace, err = executiontracer.CallWithExecutionTrace(chain, contractDefinitions, cse.Call.ToCoreMessage(), nil)
+	_, cse.ExecutionTrace, err = executiontracer.CallWithExecutionTrace(executedChain, contractDefinitions, cse.Call.ToCoreMessage(), nil)
 	if err != nil {
 		return fmt.Errorf("failed to resolve execution trace due to error replaying the call: %v", err)
 	}
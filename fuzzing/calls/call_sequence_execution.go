@@ -7,6 +7,7 @@ import (
 	"github.com/crytic/medusa/fuzzing/contracts"
 	"github.com/crytic/medusa/fuzzing/executiontracer"
 	"github.com/crytic/medusa/utils"
+	"golang.org/x/exp/maps"
 )
 
 // ExecuteCallSequenceFetchElementFunc describes a function that is called to obtain the next call sequence element to
@@ -25,6 +26,7 @@ type ExecuteCallSequenceExecutionCheckFunc func(currentExecutedSequence CallSequ
 // A "fetch next call" function is provided to fetch the next element to execute.
 // A "post element executed check" function is provided to check whether execution should stop after each element is
 // executed.
+// Each element is executed on the chain it refers to, among the chains linked to the provided chain.
 // Returns the call sequence which was executed and an error if one occurs.
 func ExecuteCallSequenceIteratively(chain *chain.TestChain, fetchElementFunc ExecuteCallSequenceFetchElementFunc, executionCheckFunc ExecuteCallSequenceExecutionCheckFunc, additionalTracers ...*chain.TestChainTracer) (CallSequence, error) {
 	// If there is no fetch element function provided, throw an error
@@ -51,21 +53,27 @@ func ExecuteCallSequenceIteratively(chain *chain.TestChain, fetchElementFunc Exe
 			break
 		}
 
+		// Resolve the chain our element should be executed on.
+		elementChain, err := callSequenceElement.ResolveChain(chain)
+		if err != nil {
+			return callSequenceExecuted, err
+		}
+
 		// We try to add the transaction with our call more than once. If the pending block is too full, we may hit a
 		// block gas limit, which we handle by committing the pending block without this tx, and creating a new pending
 		// block that is empty to try adding this tx there instead.
 		// If we encounter an error on an empty block, we throw the error as there is nothing more we can do.
 		for {
 			// If we have a pending block, but we intend to delay this call from the last, we commit that block.
-			if chain.PendingBlock() != nil && callSequenceElement.BlockNumberDelay > 0 {
-				err := chain.PendingBlockCommit()
+			if elementChain.PendingBlock() != nil && callSequenceElement.BlockNumberDelay > 0 {
+				err := elementChain.PendingBlockCommit()
 				if err != nil {
 					return callSequenceExecuted, err
 				}
 			}
 
 			// If we have no pending block to add a tx containing our call to, we must create one.
-			if chain.PendingBlock() == nil {
+			if elementChain.PendingBlock() == nil {
 				// The minimum step between blocks must be 1 in block number and timestamp, so we ensure this is the
 				// case.
 				numberDelay := callSequenceElement.BlockNumberDelay
@@ -81,14 +89,14 @@ func ExecuteCallSequenceIteratively(chain *chain.TestChain, fetchElementFunc Exe
 				if numberDelay > timeDelay {
 					numberDelay = timeDelay
 				}
-				_, err := chain.PendingBlockCreateWithParameters(chain.Head().Header.Number.Uint64()+numberDelay, chain.Head().Header.Time+timeDelay, nil)
+				_, err := elementChain.PendingBlockCreateWithParameters(elementChain.Head().Header.Number.Uint64()+numberDelay, elementChain.Head().Header.Time+timeDelay, nil, callSequenceElement.BlockBaseFee, callSequenceElement.BlockPrevRandao, callSequenceElement.BlockBlobBaseFee)
 				if err != nil {
 					return callSequenceExecuted, err
 				}
 			}
 
 			// Try to add our transaction to this block.
-			err = chain.PendingBlockAddTx(callSequenceElement.Call.ToCoreMessage(), additionalTracers...)
+			err = elementChain.PendingBlockAddTx(callSequenceElement.Call.ToCoreMessage(), additionalTracers...)
 
 			if err != nil {
 				// If we encountered a block gas limit error, this tx is too expensive to fit in this block.
@@ -98,8 +106,8 @@ func ExecuteCallSequenceIteratively(chain *chain.TestChain, fetchElementFunc Exe
 				// TODO: This should also check the condition that this is a block gas error specifically. For now, we
 				//  simply assume it is and try processing in an empty block (if that fails, that error will be
 				//  returned).
-				if len(chain.PendingBlock().Messages) > 0 {
-					err := chain.PendingBlockCommit()
+				if len(elementChain.PendingBlock().Messages) > 0 {
+					err := elementChain.PendingBlockCommit()
 					if err != nil {
 						return callSequenceExecuted, err
 					}
@@ -112,8 +120,8 @@ func ExecuteCallSequenceIteratively(chain *chain.TestChain, fetchElementFunc Exe
 
 			// Update our chain reference for this element.
 			callSequenceElement.ChainReference = &CallSequenceElementChainReference{
-				Block:            chain.PendingBlock(),
-				TransactionIndex: len(chain.PendingBlock().Messages) - 1,
+				Block:            elementChain.PendingBlock(),
+				TransactionIndex: len(elementChain.PendingBlock().Messages) - 1,
 			}
 
 			// Add to our executed call sequence
@@ -144,16 +152,30 @@ func ExecuteCallSequenceIteratively(chain *chain.TestChain, fetchElementFunc Exe
 		}
 	}
 
-	// Commit the last pending block.
-	if chain.PendingBlock() != nil {
-		err := chain.PendingBlockCommit()
-		if err != nil {
-			return callSequenceExecuted, err
-		}
+	// Commit the last pending block, along with those of any linked chains.
+	err := CommitPendingBlocks(chain)
+	if err != nil {
+		return callSequenceExecuted, err
 	}
 	return callSequenceExecuted, nil
 }
 
+// CommitPendingBlocks commits the pending block of the provided chain, as well as those of any chains linked to it,
+// so that a snapshot of them may be taken.
+// Returns an error if one occurred.
+func CommitPendingBlocks(testChain *chain.TestChain) error {
+	chains := append([]*chain.TestChain{testChain}, maps.Values(testChain.LinkedChains())...)
+	for _, c := range chains {
+		if c.PendingBlock() != nil {
+			err := c.PendingBlockCommit()
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
 // ExecuteCallSequence executes a provided CallSequence on the provided chain.
 // It returns the slice of the call sequence which was tested, and an error if one occurred.
 // If no error occurred, it can be expected that the returned call sequence contains all elements originally provided.
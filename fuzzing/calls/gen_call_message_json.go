@@ -26,6 +26,7 @@ func (c CallMessage) MarshalJSON() ([]byte, error) {
 		GasTipCap         *hexutil.Big              `json:"gasTipCap"`
 		Data              hexutil.Bytes             `json:"data,omitempty"`
 		DataAbiValues     *CallMessageDataAbiValues `json:"dataAbiValues,omitempty"`
+		BlobHashes        []common.Hash             `json:"blobHashes,omitempty"`
 		AccessList        types.AccessList
 		SkipAccountChecks bool
 	}
@@ -40,6 +41,7 @@ func (c CallMessage) MarshalJSON() ([]byte, error) {
 	enc.GasTipCap = (*hexutil.Big)(c.GasTipCap)
 	enc.Data = c.Data
 	enc.DataAbiValues = c.DataAbiValues
+	enc.BlobHashes = c.BlobHashes
 	enc.AccessList = c.AccessList
 	enc.SkipAccountChecks = c.SkipAccountChecks
 	return json.Marshal(&enc)
@@ -58,6 +60,7 @@ func (c *CallMessage) UnmarshalJSON(input []byte) error {
 		GasTipCap         *hexutil.Big              `json:"gasTipCap"`
 		Data              *hexutil.Bytes            `json:"data,omitempty"`
 		DataAbiValues     *CallMessageDataAbiValues `json:"dataAbiValues,omitempty"`
+		BlobHashes        []common.Hash             `json:"blobHashes,omitempty"`
 		AccessList        *types.AccessList
 		SkipAccountChecks *bool
 	}
@@ -95,6 +98,9 @@ func (c *CallMessage) UnmarshalJSON(input []byte) error {
 	if dec.DataAbiValues != nil {
 		c.DataAbiValues = dec.DataAbiValues
 	}
+	if dec.BlobHashes != nil {
+		c.BlobHashes = dec.BlobHashes
+	}
 	if dec.AccessList != nil {
 		c.AccessList = *dec.AccessList
 	}
@@ -7,11 +7,13 @@ import (
 	"math/big"
 	"os"
 
+	"github.com/crytic/medusa/chain"
 	"github.com/crytic/medusa/chain/config"
 	"github.com/crytic/medusa/compilation"
 	"github.com/crytic/medusa/logging"
 	"github.com/crytic/medusa/utils"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/rs/zerolog"
 )
 
@@ -58,6 +60,15 @@ type FuzzingConfig struct {
 	// the in-memory corpus will be used, but not flush to disk.
 	CorpusDirectory string `json:"corpusDirectory"`
 
+	// CacheBaseChain describes whether the state of the test chain after setup (contract deployments) should be cached
+	// in the CorpusDirectory and restored in later runs, rather than setting it up again, as long as the compilation
+	// artifacts and the configuration it depends on are unchanged.
+	CacheBaseChain bool `json:"cacheBaseChain"`
+
+	// PersistDictionary describes whether values added to the value dictionary by cheat codes at runtime should be
+	// persisted in the CorpusDirectory and restored in later runs.
+	PersistDictionary bool `json:"persistDictionary"`
+
 	// CoverageEnabled describes whether to use coverage-guided fuzzing
 	CoverageEnabled bool `json:"coverageEnabled"`
 
@@ -71,6 +82,12 @@ type FuzzingConfig struct {
 	// contract name to the deployment address
 	PredeployedContracts map[string]string `json:"predeployedContracts"`
 
+	// InitialStateFile describes the path to a file describing accounts which should exist in the chain's genesis
+	// state, prior to any contract deployments. Both go-ethereum genesis files (or their "alloc" section alone) and
+	// anvil state dumps (--dump-state) are supported. PredeployedContracts whose addresses already hold code in this
+	// state are not deployed, the existing contract is used instead.
+	InitialStateFile string `json:"initialStateFile"`
+
 	// TargetContractsBalances holds the amount of wei that should be sent during deployment for one or more contracts in
 	// TargetContracts
 	TargetContractsBalances []*big.Int `json:"targetContractsBalances"`
@@ -94,6 +111,47 @@ type FuzzingConfig struct {
 	// compared to the previous.
 	MaxBlockTimestampDelay uint64 `json:"blockTimestampDelayMax"`
 
+	// FuzzGasPrice describes whether the fuzzer will generate random gas prices when generating transactions, within
+	// the range described by MinGasPrice and MaxGasPrice. If false, each transaction's gas price is one.
+	FuzzGasPrice bool `json:"fuzzGasPrice"`
+
+	// MinGasPrice describes the minimum gas price the fuzzer will use when generating transactions.
+	MinGasPrice uint64 `json:"gasPriceMin"`
+
+	// MaxGasPrice describes the maximum gas price the fuzzer will use when generating transactions.
+	MaxGasPrice uint64 `json:"gasPriceMax"`
+
+	// FuzzBlockBaseFee describes whether the fuzzer will generate random base fees when generating blocks, within the
+	// range described by MinBlockBaseFee and MaxBlockBaseFee. If false, each block's base fee is the initial base fee
+	// defined by EIP-1559.
+	FuzzBlockBaseFee bool `json:"fuzzBlockBaseFee"`
+
+	// MinBlockBaseFee describes the minimum base fee the fuzzer will use when generating blocks.
+	MinBlockBaseFee uint64 `json:"blockBaseFeeMin"`
+
+	// MaxBlockBaseFee describes the maximum base fee the fuzzer will use when generating blocks.
+	MaxBlockBaseFee uint64 `json:"blockBaseFeeMax"`
+
+	// FuzzBlockPrevRandao describes whether the fuzzer will generate random prevrandao values when generating blocks.
+	// If false, each block's prevrandao value is derived from its parent block hash.
+	FuzzBlockPrevRandao bool `json:"fuzzBlockPrevRandao"`
+
+	// FuzzBlockBlobBaseFee describes whether the fuzzer will generate random blob base fees when generating blocks,
+	// within the range described by MinBlockBlobBaseFee and MaxBlockBlobBaseFee. If false, each block's blob base fee
+	// is the minimum blob base fee defined by EIP-4844.
+	FuzzBlockBlobBaseFee bool `json:"fuzzBlockBlobBaseFee"`
+
+	// MinBlockBlobBaseFee describes the minimum blob base fee the fuzzer will use when generating blocks.
+	MinBlockBlobBaseFee uint64 `json:"blockBlobBaseFeeMin"`
+
+	// MaxBlockBlobBaseFee describes the maximum blob base fee the fuzzer will use when generating blocks.
+	MaxBlockBlobBaseFee uint64 `json:"blockBlobBaseFeeMax"`
+
+	// MaxBlobHashes describes the maximum number of blob versioned hashes the fuzzer will attach to a generated
+	// transaction. If zero, generated transactions will not carry blobs. It must not exceed the maximum number of blobs
+	// per block defined by EIP-4844.
+	MaxBlobHashes uint64 `json:"blobHashesMax"`
+
 	// BlockGasLimit describes the maximum amount of gas that can be used in a block by transactions. This defines
 	// limits for how many transactions can be included per block.
 	BlockGasLimit uint64 `json:"blockGasLimit"`
@@ -106,6 +164,10 @@ type FuzzingConfig struct {
 
 	// TestChainConfig represents the chain.TestChain config to use when initializing a chain.
 	TestChainConfig config.TestChainConfig `json:"chainConfig"`
+
+	// MultiChain describes the configuration for multi-chain mode, in which contracts are deployed to and fuzzed
+	// across multiple chains, with messages relayed between them.
+	MultiChain MultiChainConfig `json:"multiChain"`
 }
 
 // fuzzingConfigMarshaling is a structure that overrides field types during JSON marshaling. It allows FuzzingConfig to
@@ -252,6 +314,92 @@ type OptimizationTestingConfig struct {
 	TestPrefixes []string `json:"testPrefixes"`
 }
 
+// MultiChainConfig describes the configuration options used for multi-chain mode. In multi-chain mode, each worker
+// manages additional chains alongside its primary chain, which is configured by the top-level chain configuration and
+// has the top-level target contracts deployed to it.
+type MultiChainConfig struct {
+	// Enabled describes whether multi-chain mode is enabled.
+	Enabled bool `json:"enabled"`
+
+	// Chains describes the chains to create alongside the primary chain.
+	Chains []LinkedChainConfig `json:"chains"`
+
+	// Relays describes the events which emit messages on one chain that should be delivered to another.
+	Relays []RelayConfig `json:"relays"`
+}
+
+// LinkedChainConfig describes a chain created alongside the primary chain in multi-chain mode. It otherwise shares the
+// configuration of the primary chain.
+type LinkedChainConfig struct {
+	// Name describes the name used to refer to the chain, e.g. in relays or cheat codes.
+	Name string `json:"name"`
+
+	// ChainID describes the chain ID reported by the chain. If zero, the chain ID of the primary chain is used.
+	ChainID uint64 `json:"chainId"`
+
+	// TargetContracts describes the contracts to deploy to the chain, in order. Constructor arguments are provided by
+	// the top-level constructor arguments, and may reference contracts deployed to the primary chain by name.
+	TargetContracts []string `json:"targetContracts"`
+}
+
+// RelayConfig describes an event which emits messages on one chain that should be delivered to another in multi-chain
+// mode. The event must declare an address parameter and a non-indexed bytes parameter, describing the contract to
+// call on the destination chain and the calldata to call it with. Messages are delivered from the address of the
+// emitting contract.
+type RelayConfig struct {
+	// SourceChain describes the name of the chain the event is emitted on. The primary chain is referred to as
+	// "primary".
+	SourceChain string `json:"sourceChain"`
+
+	// DestinationChain describes the name of the chain messages should be delivered to. The primary chain is referred
+	// to as "primary".
+	DestinationChain string `json:"destinationChain"`
+
+	// EmitterContract describes the name of the contract which emits the event.
+	EmitterContract string `json:"emitterContract"`
+
+	// Event describes the name of the event which emits messages.
+	Event string `json:"event"`
+}
+
+// Validate validates that the MultiChainConfig meets certain requirements.
+// Returns an error if one occurs.
+func (m *MultiChainConfig) Validate() error {
+	// If multi-chain mode is disabled, there is nothing to validate.
+	if !m.Enabled {
+		return nil
+	}
+
+	// Verify our chains are named uniquely, and do not use the name reserved for the primary chain.
+	if len(m.Chains) == 0 {
+		return errors.New("project configuration must specify at least one chain when multi-chain mode is enabled")
+	}
+	chainNames := map[string]bool{chain.PrimaryChainName: true}
+	for _, linkedChain := range m.Chains {
+		if linkedChain.Name == "" || linkedChain.Name == chain.PrimaryChainName {
+			return fmt.Errorf("project configuration must not specify an empty chain name or the reserved chain name '%s'", chain.PrimaryChainName)
+		}
+		if chainNames[linkedChain.Name] {
+			return fmt.Errorf("project configuration specifies multiple chains named '%s'", linkedChain.Name)
+		}
+		chainNames[linkedChain.Name] = true
+	}
+
+	// Verify our relays refer to known chains and events.
+	for _, relay := range m.Relays {
+		if !chainNames[relay.SourceChain] || !chainNames[relay.DestinationChain] {
+			return fmt.Errorf("project configuration specifies a relay between unknown chains '%s' and '%s'", relay.SourceChain, relay.DestinationChain)
+		}
+		if relay.SourceChain == relay.DestinationChain {
+			return fmt.Errorf("project configuration specifies a relay from chain '%s' to itself", relay.SourceChain)
+		}
+		if relay.EmitterContract == "" || relay.Event == "" {
+			return errors.New("project configuration must specify an emitter contract and event for each relay")
+		}
+	}
+	return nil
+}
+
 // LoggingConfig describes the configuration options for logging to console and file
 type LoggingConfig struct {
 	// Level describes whether logs of certain severity levels (eg info, warning, etc.) will be emitted or discarded.
@@ -378,6 +526,22 @@ func (p *ProjectConfig) Validate() error {
 			"always be exactly one.")
 	}
 
+	// Verify gas price, base fee, and blob base fee ranges are appropriate
+	if p.Fuzzing.MinGasPrice > p.Fuzzing.MaxGasPrice {
+		return errors.New("project configuration must specify a minimum gas price which does not exceed the maximum gas price")
+	}
+	if p.Fuzzing.MinBlockBaseFee > p.Fuzzing.MaxBlockBaseFee {
+		return errors.New("project configuration must specify a minimum block base fee which does not exceed the maximum block base fee")
+	}
+	if p.Fuzzing.MinBlockBlobBaseFee > p.Fuzzing.MaxBlockBlobBaseFee {
+		return errors.New("project configuration must specify a minimum block blob base fee which does not exceed the maximum block blob base fee")
+	}
+
+	// Verify the maximum number of blob hashes does not exceed the number of blobs a block can hold
+	if maxBlobsPerBlock := uint64(params.MaxBlobGasPerBlock / params.BlobTxBlobGasPerBlob); p.Fuzzing.MaxBlobHashes > maxBlobsPerBlock {
+		return fmt.Errorf("project configuration must specify a maximum number of blob hashes which does not exceed %d", maxBlobsPerBlock)
+	}
+
 	// Verify that senders are well-formed addresses
 	if _, err := utils.HexStringsToAddresses(p.Fuzzing.SenderAddresses); err != nil {
 		return errors.New("project configuration must specify only well-formed sender address(es)")
@@ -395,6 +559,29 @@ func (p *ProjectConfig) Validate() error {
 		}
 	}
 
+	// Verify that the EVM version is supported
+	if _, err := p.Fuzzing.TestChainConfig.GetChainConfig(); err != nil {
+		return fmt.Errorf("project configuration must specify a valid EVM version: %v", err)
+	}
+
+	// Verify that the cheat code configuration is valid
+	if err := p.Fuzzing.TestChainConfig.CheatCodeConfig.Validate(); err != nil {
+		return err
+	}
+
+	// Verify that fork mode has an endpoint to fork from
+	if p.Fuzzing.TestChainConfig.ForkConfig.ForkModeEnabled && p.Fuzzing.TestChainConfig.ForkConfig.RpcUrl == "" {
+		return errors.New("project configuration must specify an RPC URL when fork mode is enabled")
+	}
+
+	// Validate multi-chain config. Linked chains are created from scratch, so they cannot be used with fork mode.
+	if err := p.Fuzzing.MultiChain.Validate(); err != nil {
+		return err
+	}
+	if p.Fuzzing.MultiChain.Enabled && p.Fuzzing.TestChainConfig.ForkConfig.ForkModeEnabled {
+		return errors.New("project configuration must not enable both multi-chain mode and fork mode")
+	}
+
 	// The coverage report format must be either "lcov" or "html"
 	if p.Fuzzing.CoverageFormats != nil {
 		for _, report := range p.Fuzzing.CoverageFormats {
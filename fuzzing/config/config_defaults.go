@@ -43,8 +43,11 @@ func GetDefaultProjectConfig(platform string) (*ProjectConfig, error) {
 			TargetContracts:         []string{},
 			TargetContractsBalances: []*big.Int{},
 			PredeployedContracts:    map[string]string{},
+			InitialStateFile:        "",
 			ConstructorArgs:         map[string]map[string]any{},
 			CorpusDirectory:         "",
+			CacheBaseChain:          false,
+			PersistDictionary:       false,
 			CoverageEnabled:         true,
 			CoverageFormats:         []string{"html", "lcov"},
 			SenderAddresses: []string{
@@ -55,6 +58,17 @@ func GetDefaultProjectConfig(platform string) (*ProjectConfig, error) {
 			DeployerAddress:        "0x30000",
 			MaxBlockNumberDelay:    60480,
 			MaxBlockTimestampDelay: 604800,
+			FuzzGasPrice:           false,
+			MinGasPrice:            1,
+			MaxGasPrice:            1_000_000_000_000,
+			FuzzBlockBaseFee:       false,
+			MinBlockBaseFee:        0,
+			MaxBlockBaseFee:        1_000_000_000_000,
+			FuzzBlockPrevRandao:    false,
+			FuzzBlockBlobBaseFee:   false,
+			MinBlockBlobBaseFee:    1,
+			MaxBlockBlobBaseFee:    1_000_000_000_000,
+			MaxBlobHashes:          0,
 			BlockGasLimit:          125_000_000,
 			TransactionGasLimit:    12_500_000,
 			Testing: TestingConfig{
@@ -86,6 +100,11 @@ func GetDefaultProjectConfig(platform string) (*ProjectConfig, error) {
 				},
 			},
 			TestChainConfig: *chainConfig,
+			MultiChain: MultiChainConfig{
+				Enabled: false,
+				Chains:  []LinkedChainConfig{},
+				Relays:  []RelayConfig{},
+			},
 		},
 		Compilation: compilationConfig,
 		Logging: LoggingConfig{
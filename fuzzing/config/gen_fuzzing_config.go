@@ -22,20 +22,35 @@ func (f FuzzingConfig) MarshalJSON() ([]byte, error) {
 		ShrinkLimit             uint64                    `json:"shrinkLimit"`
 		CallSequenceLength      int                       `json:"callSequenceLength"`
 		CorpusDirectory         string                    `json:"corpusDirectory"`
+		CacheBaseChain          bool                      `json:"cacheBaseChain"`
+		PersistDictionary       bool                      `json:"persistDictionary"`
 		CoverageEnabled         bool                      `json:"coverageEnabled"`
 		CoverageFormats         []string                  `json:"coverageFormats"`
 		TargetContracts         []string                  `json:"targetContracts"`
 		PredeployedContracts    map[string]string         `json:"predeployedContracts"`
+		InitialStateFile        string                    `json:"initialStateFile"`
 		TargetContractsBalances []*hexutil.Big            `json:"targetContractsBalances"`
 		ConstructorArgs         map[string]map[string]any `json:"constructorArgs"`
 		DeployerAddress         string                    `json:"deployerAddress"`
 		SenderAddresses         []string                  `json:"senderAddresses"`
 		MaxBlockNumberDelay     uint64                    `json:"blockNumberDelayMax"`
 		MaxBlockTimestampDelay  uint64                    `json:"blockTimestampDelayMax"`
+		FuzzGasPrice            bool                      `json:"fuzzGasPrice"`
+		MinGasPrice             uint64                    `json:"gasPriceMin"`
+		MaxGasPrice             uint64                    `json:"gasPriceMax"`
+		FuzzBlockBaseFee        bool                      `json:"fuzzBlockBaseFee"`
+		MinBlockBaseFee         uint64                    `json:"blockBaseFeeMin"`
+		MaxBlockBaseFee         uint64                    `json:"blockBaseFeeMax"`
+		FuzzBlockPrevRandao     bool                      `json:"fuzzBlockPrevRandao"`
+		FuzzBlockBlobBaseFee    bool                      `json:"fuzzBlockBlobBaseFee"`
+		MinBlockBlobBaseFee     uint64                    `json:"blockBlobBaseFeeMin"`
+		MaxBlockBlobBaseFee     uint64                    `json:"blockBlobBaseFeeMax"`
+		MaxBlobHashes           uint64                    `json:"blobHashesMax"`
 		BlockGasLimit           uint64                    `json:"blockGasLimit"`
 		TransactionGasLimit     uint64                    `json:"transactionGasLimit"`
 		Testing                 TestingConfig             `json:"testing"`
 		TestChainConfig         config.TestChainConfig    `json:"chainConfig"`
+		MultiChain              MultiChainConfig          `json:"multiChain"`
 	}
 	var enc FuzzingConfig
 	enc.Workers = f.Workers
@@ -45,10 +60,13 @@ func (f FuzzingConfig) MarshalJSON() ([]byte, error) {
 	enc.ShrinkLimit = f.ShrinkLimit
 	enc.CallSequenceLength = f.CallSequenceLength
 	enc.CorpusDirectory = f.CorpusDirectory
+	enc.CacheBaseChain = f.CacheBaseChain
+	enc.PersistDictionary = f.PersistDictionary
 	enc.CoverageEnabled = f.CoverageEnabled
 	enc.CoverageFormats = f.CoverageFormats
 	enc.TargetContracts = f.TargetContracts
 	enc.PredeployedContracts = f.PredeployedContracts
+	enc.InitialStateFile = f.InitialStateFile
 	if f.TargetContractsBalances != nil {
 		enc.TargetContractsBalances = make([]*hexutil.Big, len(f.TargetContractsBalances))
 		for k, v := range f.TargetContractsBalances {
@@ -60,10 +78,22 @@ func (f FuzzingConfig) MarshalJSON() ([]byte, error) {
 	enc.SenderAddresses = f.SenderAddresses
 	enc.MaxBlockNumberDelay = f.MaxBlockNumberDelay
 	enc.MaxBlockTimestampDelay = f.MaxBlockTimestampDelay
+	enc.FuzzGasPrice = f.FuzzGasPrice
+	enc.MinGasPrice = f.MinGasPrice
+	enc.MaxGasPrice = f.MaxGasPrice
+	enc.FuzzBlockBaseFee = f.FuzzBlockBaseFee
+	enc.MinBlockBaseFee = f.MinBlockBaseFee
+	enc.MaxBlockBaseFee = f.MaxBlockBaseFee
+	enc.FuzzBlockPrevRandao = f.FuzzBlockPrevRandao
+	enc.FuzzBlockBlobBaseFee = f.FuzzBlockBlobBaseFee
+	enc.MinBlockBlobBaseFee = f.MinBlockBlobBaseFee
+	enc.MaxBlockBlobBaseFee = f.MaxBlockBlobBaseFee
+	enc.MaxBlobHashes = f.MaxBlobHashes
 	enc.BlockGasLimit = f.BlockGasLimit
 	enc.TransactionGasLimit = f.TransactionGasLimit
 	enc.Testing = f.Testing
 	enc.TestChainConfig = f.TestChainConfig
+	enc.MultiChain = f.MultiChain
 	return json.Marshal(&enc)
 }
 
@@ -77,20 +107,35 @@ func (f *FuzzingConfig) UnmarshalJSON(input []byte) error {
 		ShrinkLimit             *uint64                   `json:"shrinkLimit"`
 		CallSequenceLength      *int                      `json:"callSequenceLength"`
 		CorpusDirectory         *string                   `json:"corpusDirectory"`
+		CacheBaseChain          *bool                     `json:"cacheBaseChain"`
+		PersistDictionary       *bool                     `json:"persistDictionary"`
 		CoverageEnabled         *bool                     `json:"coverageEnabled"`
 		CoverageFormats         []string                  `json:"coverageFormats"`
 		TargetContracts         []string                  `json:"targetContracts"`
 		PredeployedContracts    map[string]string         `json:"predeployedContracts"`
+		InitialStateFile        *string                   `json:"initialStateFile"`
 		TargetContractsBalances []*hexutil.Big            `json:"targetContractsBalances"`
 		ConstructorArgs         map[string]map[string]any `json:"constructorArgs"`
 		DeployerAddress         *string                   `json:"deployerAddress"`
 		SenderAddresses         []string                  `json:"senderAddresses"`
 		MaxBlockNumberDelay     *uint64                   `json:"blockNumberDelayMax"`
 		MaxBlockTimestampDelay  *uint64                   `json:"blockTimestampDelayMax"`
+		FuzzGasPrice            *bool                     `json:"fuzzGasPrice"`
+		MinGasPrice             *uint64                   `json:"gasPriceMin"`
+		MaxGasPrice             *uint64                   `json:"gasPriceMax"`
+		FuzzBlockBaseFee        *bool                     `json:"fuzzBlockBaseFee"`
+		MinBlockBaseFee         *uint64                   `json:"blockBaseFeeMin"`
+		MaxBlockBaseFee         *uint64                   `json:"blockBaseFeeMax"`
+		FuzzBlockPrevRandao     *bool                     `json:"fuzzBlockPrevRandao"`
+		FuzzBlockBlobBaseFee    *bool                     `json:"fuzzBlockBlobBaseFee"`
+		MinBlockBlobBaseFee     *uint64                   `json:"blockBlobBaseFeeMin"`
+		MaxBlockBlobBaseFee     *uint64                   `json:"blockBlobBaseFeeMax"`
+		MaxBlobHashes           *uint64                   `json:"blobHashesMax"`
 		BlockGasLimit           *uint64                   `json:"blockGasLimit"`
 		TransactionGasLimit     *uint64                   `json:"transactionGasLimit"`
 		Testing                 *TestingConfig            `json:"testing"`
 		TestChainConfig         *config.TestChainConfig   `json:"chainConfig"`
+		MultiChain              *MultiChainConfig         `json:"multiChain"`
 	}
 	var dec FuzzingConfig
 	if err := json.Unmarshal(input, &dec); err != nil {
@@ -117,6 +162,12 @@ func (f *FuzzingConfig) UnmarshalJSON(input []byte) error {
 	if dec.CorpusDirectory != nil {
 		f.CorpusDirectory = *dec.CorpusDirectory
 	}
+	if dec.CacheBaseChain != nil {
+		f.CacheBaseChain = *dec.CacheBaseChain
+	}
+	if dec.PersistDictionary != nil {
+		f.PersistDictionary = *dec.PersistDictionary
+	}
 	if dec.CoverageEnabled != nil {
 		f.CoverageEnabled = *dec.CoverageEnabled
 	}
@@ -129,6 +180,9 @@ func (f *FuzzingConfig) UnmarshalJSON(input []byte) error {
 	if dec.PredeployedContracts != nil {
 		f.PredeployedContracts = dec.PredeployedContracts
 	}
+	if dec.InitialStateFile != nil {
+		f.InitialStateFile = *dec.InitialStateFile
+	}
 	if dec.TargetContractsBalances != nil {
 		f.TargetContractsBalances = make([]*big.Int, len(dec.TargetContractsBalances))
 		for k, v := range dec.TargetContractsBalances {
@@ -150,6 +204,39 @@ func (f *FuzzingConfig) UnmarshalJSON(input []byte) error {
 	if dec.MaxBlockTimestampDelay != nil {
 		f.MaxBlockTimestampDelay = *dec.MaxBlockTimestampDelay
 	}
+	if dec.FuzzGasPrice != nil {
+		f.FuzzGasPrice = *dec.FuzzGasPrice
+	}
+	if dec.MinGasPrice != nil {
+		f.MinGasPrice = *dec.MinGasPrice
+	}
+	if dec.MaxGasPrice != nil {
+		f.MaxGasPrice = *dec.MaxGasPrice
+	}
+	if dec.FuzzBlockBaseFee != nil {
+		f.FuzzBlockBaseFee = *dec.FuzzBlockBaseFee
+	}
+	if dec.MinBlockBaseFee != nil {
+		f.MinBlockBaseFee = *dec.MinBlockBaseFee
+	}
+	if dec.MaxBlockBaseFee != nil {
+		f.MaxBlockBaseFee = *dec.MaxBlockBaseFee
+	}
+	if dec.FuzzBlockPrevRandao != nil {
+		f.FuzzBlockPrevRandao = *dec.FuzzBlockPrevRandao
+	}
+	if dec.FuzzBlockBlobBaseFee != nil {
+		f.FuzzBlockBlobBaseFee = *dec.FuzzBlockBlobBaseFee
+	}
+	if dec.MinBlockBlobBaseFee != nil {
+		f.MinBlockBlobBaseFee = *dec.MinBlockBlobBaseFee
+	}
+	if dec.MaxBlockBlobBaseFee != nil {
+		f.MaxBlockBlobBaseFee = *dec.MaxBlockBlobBaseFee
+	}
+	if dec.MaxBlobHashes != nil {
+		f.MaxBlobHashes = *dec.MaxBlobHashes
+	}
 	if dec.BlockGasLimit != nil {
 		f.BlockGasLimit = *dec.BlockGasLimit
 	}
@@ -162,5 +249,8 @@ func (f *FuzzingConfig) UnmarshalJSON(input []byte) error {
 	if dec.TestChainConfig != nil {
 		f.TestChainConfig = *dec.TestChainConfig
 	}
+	if dec.MultiChain != nil {
+		f.MultiChain = *dec.MultiChain
+	}
 	return nil
 }
@@ -19,6 +19,7 @@ import (
 	"github.com/crytic/medusa/utils/randomutils"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/google/uuid"
+	"golang.org/x/exp/maps"
 
 	"github.com/crytic/medusa/fuzzing/contracts"
 )
@@ -214,11 +215,14 @@ func (c *Corpus) RandomMutationTargetSequence() (calls.CallSequence, error) {
 // If this sequence list being initialized is for use with mutations, it is added to the mutationTargetSequenceChooser.
 // Returns an error if one occurs.
 func (c *Corpus) initializeSequences(sequenceFiles *corpusDirectory[calls.CallSequence], testChain *chain.TestChain, deployedContracts map[common.Address]*contracts.Contract, useInMutations bool) error {
-	// Cache current HeadBlockNumber so that you can reset back to it after every sequence
-	baseBlockNumber := testChain.HeadBlockNumber()
+	// Take a snapshot of the current chain state so that you can reset back to it after every sequence. This
+	// includes any chains linked to it.
+	baseSnapshot, err := testChain.Snapshot()
+	if err != nil {
+		return fmt.Errorf("failed to snapshot the chain while seeding coverage: %v\n", err)
+	}
 
 	// Loop for each sequence
-	var err error
 	for _, sequenceFileData := range sequenceFiles.files {
 		// Unwrap the underlying sequence.
 		sequence := sequenceFileData.data
@@ -234,6 +238,12 @@ func (c *Corpus) initializeSequences(sequenceFiles *corpusDirectory[calls.CallSe
 
 			// If we are deploying a contract and not targeting one with this call, there should be no work to do.
 			currentSequenceElement := sequence[currentIndex]
+
+			// Ensure the chain the call targets still exists, as the chains configured for multi-chain fuzzing may
+			// have changed.
+			if _, sequenceInvalidError = currentSequenceElement.ResolveChain(testChain); sequenceInvalidError != nil {
+				return nil, nil
+			}
 			if currentSequenceElement.Call.To == nil {
 				return currentSequenceElement, nil
 			}
@@ -290,7 +300,7 @@ func (c *Corpus) initializeSequences(sequenceFiles *corpusDirectory[calls.CallSe
 		}
 
 		// Revert chain state to our starting point to test the next sequence.
-		if err := testChain.RevertToBlockNumber(baseBlockNumber); err != nil {
+		if err := testChain.RevertToSnapshot(baseSnapshot); err != nil {
 			return fmt.Errorf("failed to reset the chain while seeding coverage: %v\n", err)
 		}
 	}
@@ -319,7 +329,9 @@ func (c *Corpus) Initialize(baseTestChain *chain.TestChain, contractDefinitions
 
 	// Clone our test chain, adding listeners for contract deployment events from genesis.
 	testChain, err := baseTestChain.Clone(func(newChain *chain.TestChain) error {
-		// After genesis, prior to adding other blocks, we attach our coverage tracer
+		// We attach our coverage tracer to measure the coverage of the call sequences we replay below. Blocks are
+		// copied rather than re-executed when cloning, so the tracer does not observe them, and the coverage achieved
+		// while setting up the chain is instead obtained from the results copied with them.
 		newChain.AddTracer(coverageTracer.NativeTracer(), true, false)
 
 		// We also track any contract deployments, so we can resolve contract/method definitions for corpus call
@@ -341,14 +353,17 @@ func (c *Corpus) Initialize(baseTestChain *chain.TestChain, contractDefinitions
 		return 0, 0, fmt.Errorf("failed to initialize coverage maps, base test chain cloning encountered error: %v", err)
 	}
 
-	// Set our coverage maps to those collected when replaying all blocks when cloning.
+	// Set our coverage maps to those collected while setting up the base chain, which were copied along with its blocks
+	// when cloning (or restored with them, if the base chain was restored from its cache).
 	c.coverageMaps = coverage.NewCoverageMaps()
-	for _, block := range testChain.CommittedBlocks() {
-		for _, messageResults := range block.MessageResults {
-			covMaps := coverage.GetCoverageTracerResults(messageResults)
-			_, _, covErr := c.coverageMaps.Update(covMaps)
-			if covErr != nil {
-				return 0, 0, err
+	for _, setupChain := range append([]*chain.TestChain{testChain}, maps.Values(testChain.LinkedChains())...) {
+		for _, block := range setupChain.CommittedBlocks() {
+			for _, messageResults := range block.MessageResults {
+				covMaps := coverage.GetCoverageTracerResults(messageResults)
+				_, _, covErr := c.coverageMaps.Update(covMaps)
+				if covErr != nil {
+					return 0, 0, covErr
+				}
 			}
 		}
 	}
@@ -461,6 +476,12 @@ func (c *Corpus) CheckSequenceCoverageAndUpdate(callSequence calls.CallSequence,
 	// Memory optimization: Remove them from the results now that we obtained them, to free memory later.
 	coverage.RemoveCoverageTracerResults(lastMessageResult)
 
+	// If our last call was discarded because an assumption was not met, its input is irrelevant, so we do not record
+	// its coverage or save the sequence.
+	if lastCall.Discarded() {
+		return nil
+	}
+
 	// Merge the coverage maps into our total coverage maps and check if we had an update.
 	coverageUpdated, revertedCoverageUpdated, err := c.coverageMaps.Update(lastMessageCoverageMaps)
 	if err != nil {
@@ -469,6 +490,13 @@ func (c *Corpus) CheckSequenceCoverageAndUpdate(callSequence calls.CallSequence,
 
 	// If we had an increase in non-reverted or reverted coverage, we save the sequence.
 	if coverageUpdated || revertedCoverageUpdated {
+		// Remove any earlier calls which were discarded because an assumption was not met, as their inputs are
+		// irrelevant.
+		callSequence, err = callSequence.WithoutDiscarded()
+		if err != nil {
+			return err
+		}
+
 		// If we achieved new coverage, save this sequence for mutation purposes.
 		err = c.addCallSequence(c.callSequenceFiles, callSequence, true, mutationChooserWeight, flushImmediately)
 		if err != nil {
@@ -1,6 +1,9 @@
 package coverage
 
 import (
+	"encoding/json"
+
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 
 	compilationTypes "github.com/crytic/medusa/compilation/types"
@@ -16,6 +19,10 @@ type CoverageMaps struct {
 	// maps represents a structure used to track every ContractCoverageMap by a given deployed address/lookup hash.
 	maps map[common.Hash]map[common.Address]*ContractCoverageMap
 
+	// milestones represents a structure used to track the number of times each named milestone was reached with the
+	// cover cheat code. Each milestone is treated as a distinct coverage feature.
+	milestones map[string]uint
+
 	// cachedCodeAddress represents the last code address which coverage was updated for. This is used to prevent an
 	// expensive lookup in maps. If cachedCodeHash does not match the current code address for which we are updating
 	// coverage for, it, along with other cache variables are updated.
@@ -34,6 +41,30 @@ type CoverageMaps struct {
 	updateLock sync.Mutex
 }
 
+// exportedCoverageMaps describes CoverageMaps in a serializable form.
+type exportedCoverageMaps struct {
+	// Maps describes the coverage maps for each contract.
+	Maps []exportedContractCoverageMap `json:"maps"`
+
+	// Milestones describes the number of times each named milestone was reached.
+	Milestones map[string]uint `json:"milestones"`
+}
+
+// exportedContractCoverageMap describes a ContractCoverageMap in a serializable form.
+type exportedContractCoverageMap struct {
+	// LookupHash describes the hash the coverage map is tracked under.
+	LookupHash common.Hash `json:"lookupHash"`
+
+	// Address describes the address of the contract the coverage map was recorded for.
+	Address common.Address `json:"address"`
+
+	// SuccessfulCoverage describes the hit count for each program counter which did not encounter a revert.
+	SuccessfulCoverage []uint `json:"successfulCoverage"`
+
+	// RevertedCoverage describes the hit count for each program counter which encountered a revert.
+	RevertedCoverage []uint `json:"revertedCoverage"`
+}
+
 // NewCoverageMaps initializes a new CoverageMaps object.
 func NewCoverageMaps() *CoverageMaps {
 	maps := &CoverageMaps{}
@@ -41,9 +72,61 @@ func NewCoverageMaps() *CoverageMaps {
 	return maps
 }
 
+// MarshalJSON provides JSON marshalling for CoverageMaps, so they can be exported alongside the chain they were
+// recorded on.
+// Returns the JSON marshalled data, or an error if one occurs.
+func (cm *CoverageMaps) MarshalJSON() ([]byte, error) {
+	// Acquire our thread lock and defer our unlocking for when we exit this method
+	cm.updateLock.Lock()
+	defer cm.updateLock.Unlock()
+
+	exported := exportedCoverageMaps{
+		Maps:       make([]exportedContractCoverageMap, 0),
+		Milestones: cm.milestones,
+	}
+	for lookupHash, mapsByAddress := range cm.maps {
+		for address, contractCoverageMap := range mapsByAddress {
+			exported.Maps = append(exported.Maps, exportedContractCoverageMap{
+				LookupHash:         lookupHash,
+				Address:            address,
+				SuccessfulCoverage: contractCoverageMap.successfulCoverage.executedFlags,
+				RevertedCoverage:   contractCoverageMap.revertedCoverage.executedFlags,
+			})
+		}
+	}
+	return json.Marshal(exported)
+}
+
+// UnmarshalJSON provides JSON unmarshalling for CoverageMaps, so they can be restored alongside the chain they were
+// recorded on.
+// Returns an error if one occurs.
+func (cm *CoverageMaps) UnmarshalJSON(b []byte) error {
+	var exported exportedCoverageMaps
+	err := json.Unmarshal(b, &exported)
+	if err != nil {
+		return err
+	}
+
+	cm.Reset()
+	for _, exportedMap := range exported.Maps {
+		if _, ok := cm.maps[exportedMap.LookupHash]; !ok {
+			cm.maps[exportedMap.LookupHash] = make(map[common.Address]*ContractCoverageMap)
+		}
+		contractCoverageMap := newContractCoverageMap()
+		contractCoverageMap.successfulCoverage.executedFlags = exportedMap.SuccessfulCoverage
+		contractCoverageMap.revertedCoverage.executedFlags = exportedMap.RevertedCoverage
+		cm.maps[exportedMap.LookupHash][exportedMap.Address] = contractCoverageMap
+	}
+	for milestone, count := range exported.Milestones {
+		cm.milestones[milestone] = count
+	}
+	return nil
+}
+
 // Reset clears the coverage state for the CoverageMaps.
 func (cm *CoverageMaps) Reset() {
 	cm.maps = make(map[common.Hash]map[common.Address]*ContractCoverageMap)
+	cm.milestones = make(map[string]uint)
 	cm.cachedCodeAddress = common.Address{}
 	cm.cachedCodeHash = common.Hash{}
 	cm.cachedMap = nil
@@ -71,6 +154,16 @@ func (cm *CoverageMaps) Equal(b *CoverageMaps) bool {
 			}
 		}
 	}
+
+	// Verify the same milestones were reached.
+	if len(cm.milestones) != len(b.milestones) {
+		return false
+	}
+	for milestone := range cm.milestones {
+		if _, ok := b.milestones[milestone]; !ok {
+			return false
+		}
+	}
 	return true
 }
 
@@ -165,10 +258,38 @@ func (cm *CoverageMaps) Update(coverageMaps *CoverageMaps) (bool, bool, error) {
 		}
 	}
 
+	// Merge the milestones reached, treating any milestone reached for the first time as new successful coverage.
+	for milestone, hitCount := range coverageMaps.milestones {
+		if cm.milestones[milestone] == 0 {
+			successCoverageChanged = true
+		}
+		cm.milestones[milestone] += hitCount
+	}
+
 	// Return our results
 	return successCoverageChanged, revertedCoverageChanged, nil
 }
 
+// UpdateMilestone records that the named milestone was reached with the cover cheat code.
+// Returns a boolean indicating whether the milestone was reached for the first time.
+func (cm *CoverageMaps) UpdateMilestone(milestone string) bool {
+	// Acquire our thread lock and defer our unlocking for when we exit this method
+	cm.updateLock.Lock()
+	defer cm.updateLock.Unlock()
+
+	cm.milestones[milestone]++
+	return cm.milestones[milestone] == 1
+}
+
+// Milestones returns the number of times each named milestone was reached with the cover cheat code.
+func (cm *CoverageMaps) Milestones() map[string]uint {
+	// Acquire our thread lock and defer our unlocking for when we exit this method
+	cm.updateLock.Lock()
+	defer cm.updateLock.Unlock()
+
+	return maps.Clone(cm.milestones)
+}
+
 // UpdateAt updates the hit count of a given program counter location within code coverage data.
 func (cm *CoverageMaps) UpdateAt(codeAddress common.Address, codeLookupHash common.Hash, codeSize int, pc uint64) (bool, error) {
 	// If the code size is zero, do nothing
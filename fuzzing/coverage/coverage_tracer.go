@@ -17,6 +17,12 @@ import (
 // querying them.
 const coverageTracerResultsKey = "CoverageTracerResults"
 
+// init registers the results stored by a CoverageTracer to be exported alongside test chains, so the coverage achieved
+// while setting up a chain is known when it is restored from an export.
+func init() {
+	chain.RegisterExportedAdditionalResults[*CoverageMaps](coverageTracerResultsKey)
+}
+
 // GetCoverageTracerResults obtains CoverageMaps stored by a CoverageTracer from message results. This is nil if
 // no CoverageMaps were recorded by a tracer (e.g. CoverageTracer was not attached during this message execution).
 func GetCoverageTracerResults(messageResults *types.MessageResults) *CoverageMaps {
@@ -199,6 +205,12 @@ func (t *CoverageTracer) OnOpcode(pc uint64, op byte, gas, cost uint64, scope tr
 // tracer is used during transaction execution (block creation), the results can later be queried from the block.
 // This method will only be called on the added tracer if it implements the extended TestChainTracer interface.
 func (t *CoverageTracer) CaptureTxEndSetAdditionalResults(results *types.MessageResults) {
+	// Record any milestones reached with the cover cheat code as coverage. The cheat code tracer is attached when the
+	// chain is created, prior to this tracer, so its results are already set.
+	for _, milestone := range chain.GetCheatCodeMilestones(results) {
+		t.coverageMaps.UpdateMilestone(milestone)
+	}
+
 	// Store our tracer results.
 	results.AdditionalResults[coverageTracerResultsKey] = t.coverageMaps
 }
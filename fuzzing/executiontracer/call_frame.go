@@ -70,6 +70,10 @@ type CallFrame struct {
 	// ReturnError refers to any error returned by the EVM in the current call frame.
 	ReturnError error
 
+	// ExpectationFailures refers to messages describing cheat code expectations (e.g. expectRevert, expectEmit) which
+	// were not met when the current call frame exited.
+	ExpectationFailures []string
+
 	// ParentCallFrame refers to the call frame which entered this call frame directly. It may be nil if the current
 	// call frame is a top level call frame.
 	ParentCallFrame *CallFrame
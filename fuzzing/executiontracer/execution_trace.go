@@ -358,6 +358,11 @@ func (t *ExecutionTrace) generateElementsAndLogsForCallFrame(currentDepth int, c
 
 	}
 
+	// If any cheat code expectations were not met when this call frame exited, add a message for each.
+	for _, expectationFailure := range callFrame.ExpectationFailures {
+		elements = append(elements, prefix, colors.RedBold, fmt.Sprintf("[expectation failed ('%v')]", expectationFailure), colors.Reset, "\n")
+	}
+
 	// Return our elements
 	return elements, consoleLogs
 }
@@ -65,6 +65,10 @@ type ExecutionTracer struct {
 	// using this structure to execute code later once the log is committed).
 	onNextCaptureState []func()
 
+	// reportedExpectationFailures describes the count of cheat code expectation failures in the current transaction
+	// which were already attributed to a call frame.
+	reportedExpectationFailures int
+
 	nativeTracer *chain.TestChainTracer
 }
 
@@ -125,6 +129,7 @@ func (t *ExecutionTracer) OnTxStart(vm *tracing.VMContext, tx *coretypes.Transac
 	t.trace = newExecutionTrace(t.contractDefinitions)
 	t.currentCallFrame = nil
 	t.onNextCaptureState = nil
+	t.reportedExpectationFailures = 0
 	t.traceMap = make(map[common.Hash]*ExecutionTrace)
 
 	// Store our evm reference
@@ -213,6 +218,7 @@ func (t *ExecutionTracer) captureEnteredCallFrame(fromAddress common.Address, to
 		ExecutedCode:        false,
 		CallValue:           value,
 		ReturnError:         nil,
+		ExpectationFailures: nil,
 		ParentCallFrame:     t.currentCallFrame,
 	}
 
@@ -256,10 +262,29 @@ func (t *ExecutionTracer) captureExitedCallFrame(output []byte, err error) {
 	t.currentCallFrame.ReturnData = slices.Clone(output)
 	t.currentCallFrame.ReturnError = err
 
+	// Attribute any cheat code expectation failures which occurred since the last call frame exited to this one.
+	// Expectations are verified by the cheat code tracer as call frames exit, prior to this tracer being called.
+	expectationFailures := t.cheatCodeExpectationFailures()
+	if len(expectationFailures) > t.reportedExpectationFailures {
+		t.currentCallFrame.ExpectationFailures = slices.Clone(expectationFailures[t.reportedExpectationFailures:])
+		t.reportedExpectationFailures = len(expectationFailures)
+	}
+
 	// We're exiting the current frame, so set our current call frame to the parent
 	t.currentCallFrame = t.currentCallFrame.ParentCallFrame
 }
 
+// cheatCodeExpectationFailures obtains messages describing each cheat code expectation which was not met so far during
+// the current transaction.
+// Returns the messages, or nil if there are none or no cheat code contracts are installed.
+func (t *ExecutionTracer) cheatCodeExpectationFailures() []string {
+	// Every cheat code contract shares the same tracer, so we only need to query one.
+	for _, cheatCodeContract := range t.cheatCodeContracts {
+		return cheatCodeContract.ExpectationFailures()
+	}
+	return nil
+}
+
 // OnEnter initializes the tracing operation for the top of a call frame, as defined by tracers.Tracer.
 func (t *ExecutionTracer) OnEnter(depth int, typ byte, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
 	// Capture that a new call frame was entered.
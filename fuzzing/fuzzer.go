@@ -15,7 +15,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/core/tracing"
+	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth/tracers"
 
 	"github.com/crytic/medusa/fuzzing/executiontracer"
 
@@ -29,6 +32,7 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/crytic/medusa/chain"
+	chainTypes "github.com/crytic/medusa/chain/types"
 	compilationTypes "github.com/crytic/medusa/compilation/types"
 	"github.com/crytic/medusa/fuzzing/config"
 	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
@@ -38,6 +42,7 @@ import (
 	"github.com/crytic/medusa/utils"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/slices"
 )
 
@@ -62,6 +67,12 @@ type Fuzzer struct {
 	contractDefinitions fuzzerTypes.Contracts
 	// baseValueSet represents a valuegeneration.ValueSet containing input values for our fuzz tests.
 	baseValueSet *valuegeneration.ValueSet
+	// dictionaryValueSet represents a valuegeneration.ValueSet containing only the values added to the value
+	// dictionary by cheat codes at runtime, which are also added to baseValueSet. It is used to persist them.
+	dictionaryValueSet *valuegeneration.ValueSet
+	// baseValueSetLock provides thread synchronization for baseValueSet and dictionaryValueSet, as workers add values
+	// to them at runtime.
+	baseValueSetLock sync.Mutex
 
 	// workers represents the work threads created by this Fuzzer when Start invokes a fuzz operation.
 	workers []*FuzzerWorker
@@ -81,6 +92,17 @@ type Fuzzer struct {
 	// testCasesFinished describes test cases already reported as having been finalized.
 	testCasesFinished map[string]TestCase
 
+	// milestones describes the milestones reached with the cover cheat code during the fuzzing campaign, by name.
+	milestones map[string]*fuzzerMilestone
+	// milestonesLock provides thread-synchronization to avoid race conditions when accessing or updating milestones.
+	milestonesLock sync.Mutex
+
+	// gasSnapshots describes the gas snapshots taken with cheat codes during the fuzzing campaign, by label.
+	gasSnapshots map[string]*fuzzerGasSnapshot
+	// gasSnapshotsLock provides thread-synchronization to avoid race conditions when accessing or updating gas
+	// snapshots.
+	gasSnapshotsLock sync.Mutex
+
 	// Events describes the event system for the Fuzzer.
 	Events FuzzerEvents
 
@@ -150,9 +172,12 @@ func NewFuzzer(config config.ProjectConfig) (*Fuzzer, error) {
 		senders:             senders,
 		deployer:            deployer,
 		baseValueSet:        valuegeneration.NewValueSet(),
+		dictionaryValueSet:  valuegeneration.NewValueSet(),
 		contractDefinitions: make(fuzzerTypes.Contracts, 0),
 		testCases:           make([]TestCase, 0),
 		testCasesFinished:   make(map[string]TestCase),
+		milestones:          make(map[string]*fuzzerMilestone),
+		gasSnapshots:        make(map[string]*fuzzerGasSnapshot),
 		Hooks: FuzzerHooks{
 			NewCallSequenceGeneratorConfigFunc: defaultCallSequenceGeneratorConfigFunc,
 			NewShrinkingValueMutatorFunc:       defaultShrinkingValueMutatorFunc,
@@ -339,21 +364,74 @@ func (f *Fuzzer) AddCompilationTargets(compilations []compilationTypes.Compilati
 
 // createTestChain creates a test chain with the account balance allocations specified by the config.
 func (f *Fuzzer) createTestChain() (*chain.TestChain, error) {
-	// Create our genesis allocations.
+	// Verify no contracts were compiled for a newer EVM version than the configured one, as they may use opcodes which
+	// are not supported by the chain and fail to execute. Contracts without compiler metadata cannot be checked.
+	for _, contract := range f.contractDefinitions {
+		compiledEVMVersion := contract.CompiledContract().EVMVersion
+		if compiledEVMVersion != "" && !f.config.Fuzzing.TestChainConfig.SupportsCompilerEVMVersion(compiledEVMVersion) {
+			return nil, fmt.Errorf("contract %s was compiled for EVM version %v, which is newer than the configured EVM version, "+
+				"compile it for an older EVM version or update the evmVersion in the chain configuration", contract.Name(), compiledEVMVersion)
+		}
+	}
+
+	// Create our genesis allocations, starting with any accounts provided by our initial state file.
 	// NOTE: Sharing GenesisAlloc between chains will result in some accounts not being funded for some reason.
 	genesisAlloc := make(types.GenesisAlloc)
+	if f.config.Fuzzing.InitialStateFile != "" {
+		initialStateAlloc, err := chain.LoadGenesisAllocFromFile(f.config.Fuzzing.InitialStateFile)
+		if err != nil {
+			return nil, err
+		}
+		genesisAlloc = initialStateAlloc
+	}
 
-	// Fund all of our sender addresses in the genesis block
+	// Fund all of our sender addresses in the genesis block. If they already exist in our initial state, we only
+	// update their balance.
 	initBalance := new(big.Int).Div(abi.MaxInt256, big.NewInt(2)) // TODO: make this configurable
 	for _, sender := range f.senders {
-		genesisAlloc[sender] = types.Account{
-			Balance: initBalance,
-		}
+		account := genesisAlloc[sender]
+		account.Balance = initBalance
+		genesisAlloc[sender] = account
 	}
 
 	// Fund our deployer address in the genesis block
-	genesisAlloc[f.deployer] = types.Account{
-		Balance: initBalance,
+	deployerAccount := genesisAlloc[f.deployer]
+	deployerAccount.Balance = initBalance
+	genesisAlloc[f.deployer] = deployerAccount
+
+	// Construct any contracts which implement precompiles and place their runtime bytecode and storage at their
+	// precompile addresses, so their code is executed when the precompile is called.
+	precompileContracts := make([]*chainTypes.DeployedContractBytecode, 0)
+	for _, precompileConfig := range f.config.Fuzzing.TestChainConfig.Precompiles {
+		if precompileConfig.ContractName == "" {
+			continue
+		}
+		var precompileContract *fuzzerTypes.Contract
+		for _, contract := range f.contractDefinitions {
+			if contract.Name() == precompileConfig.ContractName {
+				precompileContract = contract
+				break
+			}
+		}
+		if precompileContract == nil {
+			return nil, fmt.Errorf("%v was specified as a precompile but was not found in the compilation artifacts", precompileConfig.ContractName)
+		}
+		constructedAccount, err := f.constructPrecompileContract(genesisAlloc, precompileContract)
+		if err != nil {
+			return nil, fmt.Errorf("failed to construct %v for its precompile: %v", precompileConfig.ContractName, err)
+		}
+		account := genesisAlloc[precompileConfig.Address]
+		if account.Balance == nil {
+			account.Balance = big.NewInt(0)
+		}
+		account.Code = constructedAccount.Code
+		account.Storage = constructedAccount.Storage
+		genesisAlloc[precompileConfig.Address] = account
+		precompileContracts = append(precompileContracts, &chainTypes.DeployedContractBytecode{
+			Address:         precompileConfig.Address,
+			InitBytecode:    precompileContract.CompiledContract().InitBytecode,
+			RuntimeBytecode: constructedAccount.Code,
+		})
 	}
 
 	// Identify which contracts need to be predeployed to a deterministic address by iterating across the mapping
@@ -370,7 +448,11 @@ func (f *Fuzzer) createTestChain() (*chain.TestChain, error) {
 				if err != nil {
 					return nil, fmt.Errorf("invalid address provided for a predeployed contract: %v", contract.Name())
 				}
-				contractAddressOverrides[initBytecodeHash] = contractAddr
+
+				// If the contract already exists in our initial state, it will not be deployed, so it needs no override.
+				if len(genesisAlloc[contractAddr].Code) == 0 {
+					contractAddressOverrides[initBytecodeHash] = contractAddr
+				}
 				found = true
 				break
 			}
@@ -387,10 +469,125 @@ func (f *Fuzzer) createTestChain() (*chain.TestChain, error) {
 
 	// Create our test chain with our basic allocations and passed medusa's chain configuration
 	testChain, err := chain.NewTestChain(genesisAlloc, &f.config.Fuzzing.TestChainConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	// Set our block gas limit
 	testChain.BlockGasLimit = f.config.Fuzzing.BlockGasLimit
-	return testChain, err
+
+	// Register our precompile contracts with the chain, so they are tracked as any other deployed contract.
+	for _, precompileContract := range precompileContracts {
+		err = testChain.RegisterGenesisContract(precompileContract)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Register our contract definitions with the chain, so cheat codes can refer to them by name.
+	for _, contract := range f.contractDefinitions {
+		testChain.RegisterContractArtifact(&chain.ContractArtifact{
+			Name:             contract.Name(),
+			SourcePath:       contract.SourcePath(),
+			CompiledContract: contract.CompiledContract(),
+		})
+	}
+
+	// If multi-chain mode is enabled, create the chains managed alongside our test chain and link them to it.
+	if f.config.Fuzzing.MultiChain.Enabled {
+		err = f.createLinkedChains(testChain, genesisAlloc)
+		if err != nil {
+			testChain.Close()
+			return nil, err
+		}
+	}
+	return testChain, nil
+}
+
+// constructPrecompileContract deploys the provided contract to a temporary chain created with the provided genesis
+// allocations, so its constructor is executed. This allows the runtime bytecode and storage it is constructed with to
+// be placed at a precompile address at genesis, where a constructor cannot be executed.
+// Returns an account with the constructed runtime bytecode and storage of the contract, or an error if one occurred.
+func (f *Fuzzer) constructPrecompileContract(genesisAlloc types.GenesisAlloc, contract *fuzzerTypes.Contract) (types.Account, error) {
+	// Create our temporary chain. No contract addresses are overridden, as no other contracts are deployed to it.
+	testChainConfig := f.config.Fuzzing.TestChainConfig
+	testChainConfig.ContractAddressOverrides = nil
+	testChain, err := chain.NewTestChain(maps.Clone(genesisAlloc), &testChainConfig)
+	if err != nil {
+		return types.Account{}, err
+	}
+	defer testChain.Close()
+	testChain.BlockGasLimit = f.config.Fuzzing.BlockGasLimit
+
+	// Record the storage slots written by each account while the contract is constructed, so we can copy its storage.
+	writtenSlots := make(map[common.Address][]common.Hash)
+	storageTracer := &chain.TestChainTracer{Tracer: &tracers.Tracer{Hooks: &tracing.Hooks{
+		OnOpcode: func(pc uint64, op byte, gas, cost uint64, scope tracing.OpContext, rData []byte, depth int, err error) {
+			if vm.OpCode(op) == vm.SSTORE && err == nil && len(scope.StackData()) > 0 {
+				slot := scope.StackData()[len(scope.StackData())-1]
+				writtenSlots[scope.Address()] = append(writtenSlots[scope.Address()], slot.Bytes32())
+			}
+		},
+	}}}
+	testChain.AddTracer(storageTracer, true, false)
+
+	// Deploy our contract with the constructor arguments provided for it. No other contracts are deployed yet, so they
+	// cannot be referenced.
+	args, err := getConstructorArgs(f, contract, make(map[string]common.Address))
+	if err != nil {
+		return types.Account{}, err
+	}
+	address, _, err := deployContract(f, testChain, contract, args, big.NewInt(0))
+	if err != nil {
+		return types.Account{}, err
+	}
+
+	// Copy the runtime bytecode and storage the contract was constructed with.
+	account := types.Account{
+		Code:    testChain.State().GetCode(address),
+		Storage: make(map[common.Hash]common.Hash),
+	}
+	for _, slot := range writtenSlots[address] {
+		if value := testChain.State().GetState(address, slot); value != (common.Hash{}) {
+			account.Storage[slot] = value
+		}
+	}
+	return account, nil
+}
+
+// createLinkedChains creates the chains described by the multi-chain config with the provided genesis allocations, and
+// links them to the provided primary chain. The deployer starts each linked chain with a distinct nonce, so contracts
+// deployed to different chains never share an address, allowing them to be identified by address alone.
+// Returns an error if one occurred.
+func (f *Fuzzer) createLinkedChains(primaryChain *chain.TestChain, genesisAlloc types.GenesisAlloc) error {
+	for i, linkedChainConfig := range f.config.Fuzzing.MultiChain.Chains {
+		// Offset the nonce of our deployer on this chain.
+		linkedGenesisAlloc := maps.Clone(genesisAlloc)
+		deployerAccount := linkedGenesisAlloc[f.deployer]
+		deployerAccount.Nonce = uint64(i+1) << 32
+		linkedGenesisAlloc[f.deployer] = deployerAccount
+
+		// Derive our chain config from that of our primary chain. Predeployed contracts are only deployed to the
+		// primary chain, so no contract addresses are overridden.
+		testChainConfig := f.config.Fuzzing.TestChainConfig
+		testChainConfig.ContractAddressOverrides = nil
+		if linkedChainConfig.ChainID != 0 {
+			testChainConfig.ChainID = linkedChainConfig.ChainID
+		}
+
+		// Create our chain and link it to our primary chain.
+		linkedChain, err := chain.NewTestChain(linkedGenesisAlloc, &testChainConfig)
+		if err != nil {
+			return err
+		}
+		linkedChain.BlockGasLimit = f.config.Fuzzing.BlockGasLimit
+		err = primaryChain.LinkChain(linkedChainConfig.Name, linkedChain)
+		if err != nil {
+			linkedChain.Close()
+			return err
+		}
+	}
+	return nil
 }
 
 // chainSetupFromCompilations is a TestChainSetupFunc which sets up the base test chain state by deploying
@@ -429,6 +626,19 @@ func chainSetupFromCompilations(fuzzer *Fuzzer, testChain *chain.TestChain) (*ex
 	contractsToDeploy = append(contractsToDeploy, fuzzer.config.Fuzzing.TargetContracts...)
 	balances = append(balances, fuzzer.config.Fuzzing.TargetContractsBalances...)
 
+	// Determine which predeployed contracts already exist in the genesis state (e.g. provided by an initial state file),
+	// as these are registered with the chain rather than deployed.
+	existingContractAddr := make(map[string]common.Address)
+	for contractName, addrStr := range fuzzer.config.Fuzzing.PredeployedContracts {
+		contractAddr, err := utils.HexStringToAddress(addrStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address provided for a predeployed contract: %v", contractName)
+		}
+		if len(testChain.GenesisDefinition().Alloc[contractAddr].Code) > 0 {
+			existingContractAddr[contractName] = contractAddr
+		}
+	}
+
 	deployedContractAddr := make(map[string]common.Address)
 	// Loop for all contracts to deploy
 	for i, contractName := range contractsToDeploy {
@@ -437,30 +647,33 @@ func chainSetupFromCompilations(fuzzer *Fuzzer, testChain *chain.TestChain) (*ex
 		for _, contract := range fuzzer.contractDefinitions {
 			// If we found a contract definition that matches this definition by name, try to deploy it
 			if contract.Name() == contractName {
-				// Concatenate constructor arguments, if necessary
-				args := make([]any, 0)
-				if len(contract.CompiledContract().Abi.Constructor.Inputs) > 0 {
-					// If the contract is a predeployed contract, throw an error because they do not accept constructor
-					// args.
-					if _, ok := fuzzer.config.Fuzzing.PredeployedContracts[contractName]; ok {
-						return nil, fmt.Errorf("predeployed contracts cannot accept constructor arguments")
-					}
-					jsonArgs, ok := fuzzer.config.Fuzzing.ConstructorArgs[contractName]
-					if !ok {
-						return nil, fmt.Errorf("constructor arguments for contract %s not provided", contractName)
-					}
-					decoded, err := valuegeneration.DecodeJSONArgumentsFromMap(contract.CompiledContract().Abi.Constructor.Inputs,
-						jsonArgs, deployedContractAddr)
-					if err != nil {
-						return nil, err
+				// If the contract already exists in the genesis state, register it with the chain (once) rather than
+				// deploying it, so it is tracked as a deployed contract matching this definition.
+				if contractAddr, ok := existingContractAddr[contractName]; ok {
+					if _, registered := deployedContractAddr[contractName]; !registered {
+						err := testChain.RegisterGenesisContract(&chainTypes.DeployedContractBytecode{
+							Address:         contractAddr,
+							InitBytecode:    contract.CompiledContract().InitBytecode,
+							RuntimeBytecode: contract.CompiledContract().RuntimeBytecode,
+						})
+						if err != nil {
+							return nil, err
+						}
+						deployedContractAddr[contractName] = contractAddr
 					}
-					args = decoded
+					found = true
+					break
 				}
 
-				// Construct our deployment message/tx data field
-				msgData, err := contract.CompiledContract().GetDeploymentMessageData(args)
+				// If the contract is a predeployed contract, throw an error if it accepts constructor args.
+				if _, ok := fuzzer.config.Fuzzing.PredeployedContracts[contractName]; ok && len(contract.CompiledContract().Abi.Constructor.Inputs) > 0 {
+					return nil, fmt.Errorf("predeployed contracts cannot accept constructor arguments")
+				}
+
+				// Concatenate constructor arguments, if necessary
+				args, err := getConstructorArgs(fuzzer, contract, deployedContractAddr)
 				if err != nil {
-					return nil, fmt.Errorf("initial contract deployment failed for contract \"%v\", error: %v", contractName, err)
+					return nil, err
 				}
 
 				// If our project config has a non-zero balance for this target contract, retrieve it
@@ -469,57 +682,15 @@ func chainSetupFromCompilations(fuzzer *Fuzzer, testChain *chain.TestChain) (*ex
 					contractBalance = new(big.Int).Set(balances[i])
 				}
 
-				// Create a message to represent our contract deployment (we let deployments consume the whole block
-				// gas limit rather than use tx gas limit)
-				msg := calls.NewCallMessage(fuzzer.deployer, nil, 0, contractBalance, fuzzer.config.Fuzzing.BlockGasLimit, nil, nil, nil, msgData)
-				msg.FillFromTestChainProperties(testChain)
-
-				// Create a new pending block we'll commit to chain
-				block, err := testChain.PendingBlockCreate()
-				if err != nil {
-					return nil, err
-				}
-
-				// Add our transaction to the block
-				err = testChain.PendingBlockAddTx(msg.ToCoreMessage())
+				// Deploy our contract.
+				contractAddr, trace, err := deployContract(fuzzer, testChain, contract, args, contractBalance)
 				if err != nil {
-					return nil, err
-				}
-
-				// Commit the pending block to the chain, so it becomes the new head.
-				err = testChain.PendingBlockCommit()
-				if err != nil {
-					return nil, err
-				}
-
-				// Ensure our transaction succeeded and, if it did not, attach an execution trace to it and re-run it.
-				// The execution trace will be returned so that it can be provided to the user for debugging
-				if block.MessageResults[0].Receipt.Status != types.ReceiptStatusSuccessful {
-					// Create a call sequence element to represent the failed contract deployment tx
-					cse := calls.NewCallSequenceElement(nil, msg, 0, 0)
-					cse.ChainReference = &calls.CallSequenceElementChainReference{
-						Block:            block,
-						TransactionIndex: len(block.Messages) - 1,
-					}
-					// Revert to genesis and re-run the failed contract deployment tx.
-					// We should be able to attach an execution trace; however, if it fails, we provide the ExecutionResult at a minimum.
-					err = testChain.RevertToBlockNumber(0)
-					if err != nil {
-						return nil, fmt.Errorf("failed to reset to genesis block: %v", err)
-					} else {
-						_, err = calls.ExecuteCallSequenceWithExecutionTracer(testChain, fuzzer.contractDefinitions, []*calls.CallSequenceElement{cse}, true)
-						if err != nil {
-							return nil, fmt.Errorf("deploying %s returned a failed status: %v", contractName, block.MessageResults[0].ExecutionResult.Err)
-						}
-					}
-
-					// Return the execution error and the execution trace, if possible.
-					return cse.ExecutionTrace, fmt.Errorf("deploying %s returned a failed status: %v", contractName, block.MessageResults[0].ExecutionResult.Err)
+					return trace, err
 				}
 
 				// Record our deployed contract so the next config-specified constructor args can reference this
 				// contract by name.
-				deployedContractAddr[contractName] = block.MessageResults[0].Receipt.ContractAddress
+				deployedContractAddr[contractName] = contractAddr
 
 				// Flag that we found a matching compiled contract definition and deployed it, then exit out of this
 				// inner loop to process the next contract to deploy in the outer loop.
@@ -533,9 +704,124 @@ func chainSetupFromCompilations(fuzzer *Fuzzer, testChain *chain.TestChain) (*ex
 			return nil, fmt.Errorf("%v was specified in the target contracts but was not found in the compilation artifacts", contractName)
 		}
 	}
+
+	// If multi-chain mode is enabled, deploy the target contracts of each linked chain to it. Constructor arguments may
+	// reference any contract deployed before it by name, including those deployed to other chains.
+	if fuzzer.config.Fuzzing.MultiChain.Enabled {
+		for _, linkedChainConfig := range fuzzer.config.Fuzzing.MultiChain.Chains {
+			linkedChain := testChain.LinkedChains()[linkedChainConfig.Name]
+			if linkedChain == nil {
+				return nil, fmt.Errorf("chain %v was not linked to the test chain", linkedChainConfig.Name)
+			}
+			for _, contractName := range linkedChainConfig.TargetContracts {
+				// Look for a contract in our compiled contract definitions that matches this one
+				var contract *fuzzerTypes.Contract
+				for _, contractDefinition := range fuzzer.contractDefinitions {
+					if contractDefinition.Name() == contractName {
+						contract = contractDefinition
+						break
+					}
+				}
+				if contract == nil {
+					return nil, fmt.Errorf("%v was specified in the target contracts of chain %v but was not found in the compilation artifacts", contractName, linkedChainConfig.Name)
+				}
+
+				// Deploy our contract to the linked chain.
+				args, err := getConstructorArgs(fuzzer, contract, deployedContractAddr)
+				if err != nil {
+					return nil, err
+				}
+				contractAddr, trace, err := deployContract(fuzzer, linkedChain, contract, args, big.NewInt(0))
+				if err != nil {
+					return trace, err
+				}
+				deployedContractAddr[contractName] = contractAddr
+			}
+		}
+	}
 	return nil, nil
 }
 
+// getConstructorArgs obtains the constructor arguments for the provided contract from the fuzzer's config, resolving
+// references to previously deployed contracts using the provided mapping of contract names to addresses.
+// Returns the constructor arguments, or an error if one occurred.
+func getConstructorArgs(fuzzer *Fuzzer, contract *fuzzerTypes.Contract, deployedContractAddr map[string]common.Address) ([]any, error) {
+	// If the constructor accepts no arguments, there is nothing to obtain.
+	if len(contract.CompiledContract().Abi.Constructor.Inputs) == 0 {
+		return make([]any, 0), nil
+	}
+
+	// Decode the arguments provided for the contract in our config.
+	jsonArgs, ok := fuzzer.config.Fuzzing.ConstructorArgs[contract.Name()]
+	if !ok {
+		return nil, fmt.Errorf("constructor arguments for contract %s not provided", contract.Name())
+	}
+	return valuegeneration.DecodeJSONArgumentsFromMap(contract.CompiledContract().Abi.Constructor.Inputs, jsonArgs, deployedContractAddr)
+}
+
+// deployContract deploys the provided contract to the provided chain in a new block, using the provided constructor
+// arguments and balance.
+// Returns the address of the deployed contract, or an error if one occurred. If the deployment failed, an execution
+// trace of it is returned alongside the error, if one could be obtained.
+func deployContract(fuzzer *Fuzzer, testChain *chain.TestChain, contract *fuzzerTypes.Contract, args []any, contractBalance *big.Int) (common.Address, *executiontracer.ExecutionTrace, error) {
+	contractName := contract.Name()
+
+	// Construct our deployment message/tx data field
+	msgData, err := contract.CompiledContract().GetDeploymentMessageData(args)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("initial contract deployment failed for contract \"%v\", error: %v", contractName, err)
+	}
+
+	// Create a message to represent our contract deployment (we let deployments consume the whole block
+	// gas limit rather than use tx gas limit)
+	msg := calls.NewCallMessage(fuzzer.deployer, nil, 0, contractBalance, fuzzer.config.Fuzzing.BlockGasLimit, nil, nil, nil, msgData)
+	msg.FillFromTestChainProperties(testChain)
+
+	// Create a new pending block we'll commit to chain
+	block, err := testChain.PendingBlockCreate()
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	// Add our transaction to the block
+	err = testChain.PendingBlockAddTx(msg.ToCoreMessage())
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	// Commit the pending block to the chain, so it becomes the new head.
+	err = testChain.PendingBlockCommit()
+	if err != nil {
+		return common.Address{}, nil, err
+	}
+
+	// Ensure our transaction succeeded and, if it did not, attach an execution trace to it and re-run it.
+	// The execution trace will be returned so that it can be provided to the user for debugging
+	if block.MessageResults[0].Receipt.Status != types.ReceiptStatusSuccessful {
+		// Create a call sequence element to represent the failed contract deployment tx
+		cse := calls.NewCallSequenceElement(nil, msg, 0, 0)
+		cse.ChainReference = &calls.CallSequenceElementChainReference{
+			Block:            block,
+			TransactionIndex: len(block.Messages) - 1,
+		}
+		// Revert to genesis and re-run the failed contract deployment tx.
+		// We should be able to attach an execution trace; however, if it fails, we provide the ExecutionResult at a minimum.
+		err = testChain.RevertToBlockNumber(0)
+		if err != nil {
+			return common.Address{}, nil, fmt.Errorf("failed to reset to genesis block: %v", err)
+		} else {
+			_, err = calls.ExecuteCallSequenceWithExecutionTracer(testChain, fuzzer.contractDefinitions, []*calls.CallSequenceElement{cse}, true)
+			if err != nil {
+				return common.Address{}, nil, fmt.Errorf("deploying %s returned a failed status: %v", contractName, block.MessageResults[0].ExecutionResult.Err)
+			}
+		}
+
+		// Return the execution error and the execution trace, if possible.
+		return common.Address{}, cse.ExecutionTrace, fmt.Errorf("deploying %s returned a failed status: %v", contractName, block.MessageResults[0].ExecutionResult.Err)
+	}
+	return block.MessageResults[0].Receipt.ContractAddress, nil, nil
+}
+
 // defaultCallSequenceGeneratorConfigFunc is a NewCallSequenceGeneratorConfigFunc which creates a
 // CallSequenceGeneratorConfig with a default configuration. Returns the config or an error, if one occurs.
 func defaultCallSequenceGeneratorConfigFunc(fuzzer *Fuzzer, valueSet *valuegeneration.ValueSet, randomProvider *rand.Rand) (*CallSequenceGeneratorConfig, error) {
@@ -740,6 +1026,12 @@ func (f *Fuzzer) Start() error {
 	f.testCases = make([]TestCase, 0)
 	f.testCasesFinished = make(map[string]TestCase)
 	f.testCasesLock.Unlock()
+	f.milestonesLock.Lock()
+	f.milestones = make(map[string]*fuzzerMilestone)
+	f.milestonesLock.Unlock()
+	f.gasSnapshotsLock.Lock()
+	f.gasSnapshots = make(map[string]*fuzzerGasSnapshot)
+	f.gasSnapshotsLock.Unlock()
 
 	// Create our test chain
 	baseTestChain, err := f.createTestChain()
@@ -748,18 +1040,71 @@ func (f *Fuzzer) Start() error {
 		return err
 	}
 
-	// Set it up with our deployment/setup strategy defined by the fuzzer.
-	f.logger.Info("Setting up test chain")
-	trace, err := f.Hooks.ChainSetupFunc(f, baseTestChain)
-	if err != nil {
-		if trace != nil {
-			f.logger.Error("Failed to initialize the test chain", err, errors.New(trace.Log().ColorString()))
-		} else {
-			f.logger.Error("Failed to initialize the test chain", err)
+	// If we are caching our test chain after setup, determine where it is cached and try to restore it. The cache
+	// cannot hold chains created in fork mode or linked chains, and its key cannot account for the inputs of a custom
+	// setup function, so we do not use it for them.
+	var baseChainCachePath string
+	restoredBaseChain := false
+	if f.config.Fuzzing.CacheBaseChain && f.config.Fuzzing.CorpusDirectory != "" && !f.config.Fuzzing.TestChainConfig.ForkConfig.ForkModeEnabled && !f.config.Fuzzing.MultiChain.Enabled && f.usesDefaultChainSetup() {
+		baseChainCachePath, err = f.baseChainCachePath()
+		if err != nil {
+			f.logger.Error("Failed to determine the test chain cache path", err)
+			return err
+		}
+		restoredBaseChain, err = f.restoreBaseChain(baseTestChain, baseChainCachePath)
+		if err != nil {
+			// The chain may have been partially restored, so we recreate it and set it up instead.
+			f.logger.Warn("Failed to restore the test chain from its cache, it will be set up again", err)
+			baseTestChain.Close()
+			baseTestChain, err = f.createTestChain()
+			if err != nil {
+				f.logger.Error("Failed to create the test chain", err)
+				return err
+			}
+		} else if restoredBaseChain {
+			f.logger.Info("Restored test chain from its cache")
+		}
+	}
+
+	// If we did not restore our test chain, set it up with our deployment/setup strategy defined by the fuzzer.
+	if !restoredBaseChain {
+		// If we have coverage enabled, measure the coverage of our setup, so it can be accounted for by the corpus.
+		if f.config.Fuzzing.CoverageEnabled {
+			baseTestChain.AddTracer(coverage.NewCoverageTracer().NativeTracer(), true, false)
+			for _, linkedChain := range baseTestChain.LinkedChains() {
+				linkedChain.AddTracer(coverage.NewCoverageTracer().NativeTracer(), true, false)
+			}
+		}
+
+		f.logger.Info("Setting up test chain")
+		var trace *executiontracer.ExecutionTrace
+		trace, err = f.Hooks.ChainSetupFunc(f, baseTestChain)
+		if err != nil {
+			if trace != nil {
+				f.logger.Error("Failed to initialize the test chain", err, errors.New(trace.Log().ColorString()))
+			} else {
+				f.logger.Error("Failed to initialize the test chain", err)
+			}
+			return err
+		}
+		f.logger.Info("Finished setting up test chain")
+
+		// Cache our test chain, so it can be restored in later runs. If setup read files, environment variables or
+		// command outputs through cheat codes, they may change without our cache key changing, so we do not cache it.
+		if baseChainCachePath != "" {
+			if baseTestChain.ExternalInputsRead() {
+				f.logger.Info("Not caching the test chain, as its setup read files, environment variables or command outputs through cheat codes")
+			} else {
+				err = f.saveBaseChain(baseTestChain, baseChainCachePath)
+				if err != nil {
+					f.logger.Warn("Failed to cache the test chain", err)
+				}
+			}
 		}
-		return err
 	}
-	f.logger.Info("Finished setting up test chain")
+
+	// Add any values added to the value dictionary while setting up our test chain, so they reach every worker.
+	f.addSetupDictionaryValues(baseTestChain)
 
 	// Initialize our coverage maps by measuring the coverage we get from the corpus.
 	var corpusActiveSequences, corpusTotalSequences int
@@ -776,6 +1121,16 @@ func (f *Fuzzer) Start() error {
 		return err
 	}
 
+	// If we are persisting our value dictionary, restore any values persisted by previous runs.
+	persistDictionary := f.config.Fuzzing.PersistDictionary && f.config.Fuzzing.CorpusDirectory != ""
+	if persistDictionary {
+		err = f.loadDictionary()
+		if err != nil {
+			f.logger.Error("Failed to restore the value dictionary", err)
+			return err
+		}
+	}
+
 	// Log corpus health statistics, if we have any existing sequences.
 	if corpusTotalSequences > 0 {
 		f.logger.Info(
@@ -826,6 +1181,23 @@ func (f *Fuzzer) Start() error {
 		}
 	}
 
+	// If we are persisting our value dictionary, write it. We do this even if we had a previous error, for the same
+	// reason.
+	if persistDictionary {
+		dictionarySaveErr := f.saveDictionary()
+		if err == nil && dictionarySaveErr != nil {
+			err = dictionarySaveErr
+			f.logger.Error("Failed to persist the value dictionary", err)
+		}
+	}
+
+	// If we forked a remote chain, persist any remote state we fetched so it can be reused in future runs.
+	remoteStateFlushErr := baseTestChain.FlushRemoteStateCache()
+	if err == nil && remoteStateFlushErr != nil {
+		err = remoteStateFlushErr
+		f.logger.Error("Failed to flush the fork cache", err)
+	}
+
 	// Publish a fuzzer stopping event.
 	fuzzerStoppingErr := f.Events.FuzzerStopping.Publish(FuzzerStoppingEvent{Fuzzer: f, err: err})
 	if err == nil && fuzzerStoppingErr != nil {
@@ -836,13 +1208,24 @@ func (f *Fuzzer) Start() error {
 	// Print our results on exit.
 	f.printExitingResults()
 
+	// Write our reports to the default directory if we have no corpus directory set.
+	coverageReportDir := filepath.Join("crytic-export", "coverage")
+	if f.config.Fuzzing.CorpusDirectory != "" {
+		coverageReportDir = filepath.Join(f.config.Fuzzing.CorpusDirectory, "coverage")
+	}
+
+	// Write a report of the gas snapshots taken with cheat codes, if any were taken, next to our coverage reports.
+	if err == nil {
+		path, reportErr := f.writeGasSnapshotsReport(coverageReportDir)
+		if reportErr != nil {
+			f.logger.Error("Failed to write the gas snapshot report", reportErr)
+		} else if path != "" {
+			f.logger.Info(fmt.Sprintf("gas snapshot report saved to: %s", path), colors.Bold, colors.Reset)
+		}
+	}
+
 	// Finally, generate our coverage report if we have set a valid corpus directory.
 	if err == nil && len(f.config.Fuzzing.CoverageFormats) > 0 {
-		// Write to the default directory if we have no corpus directory set.
-		coverageReportDir := filepath.Join("crytic-export", "coverage")
-		if f.config.Fuzzing.CorpusDirectory != "" {
-			coverageReportDir = filepath.Join(f.config.Fuzzing.CorpusDirectory, "coverage")
-		}
 		sourceAnalysis, err := coverage.AnalyzeSourceCoverage(f.compilations, f.corpus.CoverageMaps())
 
 		if err != nil {
@@ -898,6 +1281,7 @@ func (f *Fuzzer) printMetricsLoop() {
 		sequencesTested := f.metrics.SequencesTested()
 		gasUsed := f.metrics.GasUsed()
 		failedSequences := f.metrics.FailedSequences()
+		callsDiscarded := f.metrics.CallsDiscarded()
 		workerStartupCount := f.metrics.WorkerStartupCount()
 		workersShrinking := f.metrics.WorkersShrinkingCount()
 
@@ -919,6 +1303,9 @@ func (f *Fuzzer) printMetricsLoop() {
 		logBuffer.Append(", coverage: ", colors.Bold, fmt.Sprintf("%d", f.corpus.CoverageMaps().UniquePCs()), colors.Reset)
 		logBuffer.Append(", corpus: ", colors.Bold, fmt.Sprintf("%d", f.corpus.ActiveMutableSequenceCount()), colors.Reset)
 		logBuffer.Append(", failures: ", colors.Bold, fmt.Sprintf("%d/%d", failedSequences, sequencesTested), colors.Reset)
+		if callsDiscarded.Sign() > 0 {
+			logBuffer.Append(", discarded: ", colors.Bold, fmt.Sprintf("%d/%d", callsDiscarded, callsTested), colors.Reset)
+		}
 		logBuffer.Append(", gas/s: ", colors.Bold, fmt.Sprintf("%d", uint64(float64(new(big.Int).Sub(gasUsed, lastGasUsed).Uint64())/secondsSinceLastUpdate)), colors.Reset)
 		if f.logger.Level() <= zerolog.DebugLevel {
 			logBuffer.Append(", shrinking: ", colors.Bold, fmt.Sprintf("%v", workersShrinking), colors.Reset)
@@ -927,6 +1314,26 @@ func (f *Fuzzer) printMetricsLoop() {
 		}
 		f.logger.Info(logBuffer.Elements()...)
 
+		// If any calls were discarded, print the rate at which calls to each method were discarded, from highest to
+		// lowest.
+		discardRates := f.metrics.MethodDiscardRates()
+		if len(discardRates) > 0 {
+			methodNames := maps.Keys(discardRates)
+			sort.Slice(methodNames, func(i, j int) bool {
+				return discardRates[methodNames[i]] > discardRates[methodNames[j]] ||
+					(discardRates[methodNames[i]] == discardRates[methodNames[j]] && methodNames[i] < methodNames[j])
+			})
+			discardBuffer := logging.NewLogBuffer()
+			discardBuffer.Append(colors.Bold, "discard rates: ", colors.Reset)
+			for i, methodName := range methodNames {
+				if i > 0 {
+					discardBuffer.Append(", ")
+				}
+				discardBuffer.Append(methodName, ": ", colors.Bold, fmt.Sprintf("%.1f%%", discardRates[methodName]*100), colors.Reset)
+			}
+			f.logger.Info(discardBuffer.Elements()...)
+		}
+
 		// Update our delta tracking metrics
 		lastPrintedTime = time.Now()
 		lastCallsTested = callsTested
@@ -991,4 +1398,7 @@ func (f *Fuzzer) printExitingResults() {
 
 	// Print our final tally of test statuses.
 	f.logger.Info("Test summary: ", colors.GreenBold, testCountPassed, colors.Reset, " test(s) passed, ", colors.RedBold, testCountFailed, colors.Reset, " test(s) failed")
+
+	// Print the milestones reached with the cover cheat code, if any.
+	f.printMilestones()
 }
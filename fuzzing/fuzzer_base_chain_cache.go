@@ -0,0 +1,181 @@
+package fuzzing
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/crytic/medusa/chain"
+	chainConfig "github.com/crytic/medusa/chain/config"
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// baseChainCacheVersion describes the version of the base chain cache format. It is included in the cache key, so
+// caches written in a different format are never restored.
+const baseChainCacheVersion = 1
+
+// baseChainCache describes the state of the base test chain after it was set up, as persisted in the corpus
+// directory so it can be restored in later runs.
+type baseChainCache struct {
+	// TargetContracts describes the target contracts after the chain was set up, which may have been inferred during
+	// setup if none were provided.
+	TargetContracts []string `json:"targetContracts"`
+
+	// Chain describes the exported base test chain.
+	Chain *chain.TestChainExport `json:"chain"`
+}
+
+// baseChainCacheKeyData describes all data which the base chain cache key is derived from. If any of it changes, the
+// chain set up by the fuzzer may differ, so a previously cached chain cannot be restored.
+type baseChainCacheKeyData struct {
+	Version                 int                          `json:"version"`
+	Contracts               []baseChainCacheKeyContract  `json:"contracts"`
+	TargetContracts         []string                     `json:"targetContracts"`
+	PredeployedContracts    map[string]string            `json:"predeployedContracts"`
+	InitialStateFileHash    string                       `json:"initialStateFileHash"`
+	TargetContractsBalances []*big.Int                   `json:"targetContractsBalances"`
+	ConstructorArgs         map[string]map[string]any    `json:"constructorArgs"`
+	DeployerAddress         string                       `json:"deployerAddress"`
+	SenderAddresses         []string                     `json:"senderAddresses"`
+	BlockGasLimit           uint64                       `json:"blockGasLimit"`
+	TestChainConfig         *chainConfig.TestChainConfig `json:"testChainConfig"`
+}
+
+// baseChainCacheKeyContract describes a compiled contract definition the base chain cache key is derived from.
+type baseChainCacheKeyContract struct {
+	Name            string `json:"name"`
+	InitBytecode    []byte `json:"initBytecode"`
+	RuntimeBytecode []byte `json:"runtimeBytecode"`
+}
+
+// usesDefaultChainSetup determines whether the fuzzer sets up its base test chain with the default setup function,
+// chainSetupFromCompilations. The base chain cache key only describes the inputs of the default setup function, so
+// the cache is not used if a custom one is provided.
+func (f *Fuzzer) usesDefaultChainSetup() bool {
+	return reflect.ValueOf(f.Hooks.ChainSetupFunc).Pointer() == reflect.ValueOf(TestChainSetupFunc(chainSetupFromCompilations)).Pointer()
+}
+
+// baseChainCacheDirectory returns the directory within the corpus directory which the base chain cache is stored in.
+func (f *Fuzzer) baseChainCacheDirectory() string {
+	return filepath.Join(f.config.Fuzzing.CorpusDirectory, "base_chain")
+}
+
+// baseChainCachePath derives the path of the base chain cache file for the current compilation artifacts and
+// configuration. The file name is a hash of all data which determines how the base chain is set up, so this must be
+// called before the chain is set up, as setup may update the configuration (e.g. by inferring target contracts).
+// Returns the path, or an error if one occurred.
+func (f *Fuzzer) baseChainCachePath() (string, error) {
+	// Collect our key data, starting with our contract definitions.
+	keyData := baseChainCacheKeyData{
+		Version:                 baseChainCacheVersion,
+		Contracts:               make([]baseChainCacheKeyContract, 0, len(f.contractDefinitions)),
+		TargetContracts:         f.config.Fuzzing.TargetContracts,
+		PredeployedContracts:    f.config.Fuzzing.PredeployedContracts,
+		TargetContractsBalances: f.config.Fuzzing.TargetContractsBalances,
+		ConstructorArgs:         f.config.Fuzzing.ConstructorArgs,
+		DeployerAddress:         f.config.Fuzzing.DeployerAddress,
+		SenderAddresses:         f.config.Fuzzing.SenderAddresses,
+		BlockGasLimit:           f.config.Fuzzing.BlockGasLimit,
+		TestChainConfig:         &f.config.Fuzzing.TestChainConfig,
+	}
+	for _, contract := range f.contractDefinitions {
+		keyData.Contracts = append(keyData.Contracts, baseChainCacheKeyContract{
+			Name:            contract.Name(),
+			InitBytecode:    contract.CompiledContract().InitBytecode,
+			RuntimeBytecode: contract.CompiledContract().RuntimeBytecode,
+		})
+	}
+
+	// The initial state file may change without its path changing, so we include a hash of its contents.
+	if f.config.Fuzzing.InitialStateFile != "" {
+		b, err := os.ReadFile(f.config.Fuzzing.InitialStateFile)
+		if err != nil {
+			return "", fmt.Errorf("could not read initial state file: %v", err)
+		}
+		keyData.InitialStateFileHash = crypto.Keccak256Hash(b).Hex()
+	}
+
+	// Hash our key data to obtain our file name.
+	b, err := json.Marshal(keyData)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(f.baseChainCacheDirectory(), crypto.Keccak256Hash(b).Hex()+".json"), nil
+}
+
+// restoreBaseChain restores the base test chain from the provided base chain cache file path, if it exists. The
+// provided chain must have been freshly created by the fuzzer.
+// Returns a boolean indicating whether the chain was restored, or an error if one occurred. If an error is returned,
+// the chain may have been partially restored and should be discarded.
+func (f *Fuzzer) restoreBaseChain(testChain *chain.TestChain, cachePath string) (bool, error) {
+	// Read our cache file, if it exists.
+	b, err := os.ReadFile(cachePath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	// Parse our cache and import our chain.
+	var cache baseChainCache
+	err = json.Unmarshal(b, &cache)
+	if err != nil {
+		return false, fmt.Errorf("could not parse base chain cache file %s: %v", cachePath, err)
+	}
+	err = testChain.Import(cache.Chain)
+	if err != nil {
+		return false, err
+	}
+
+	// Restore any target contracts inferred during setup.
+	if len(f.config.Fuzzing.TargetContracts) == 0 {
+		f.config.Fuzzing.TargetContracts = cache.TargetContracts
+	}
+	return true, nil
+}
+
+// saveBaseChain persists the base test chain to the provided base chain cache file path, so it can be restored in
+// later runs. This must be called after the chain was set up. Any base chain caches for other compilation artifacts
+// or configurations are removed.
+// Returns an error if one occurred.
+func (f *Fuzzer) saveBaseChain(testChain *chain.TestChain, cachePath string) error {
+	// Export our chain and serialize it.
+	export, err := testChain.Export()
+	if err != nil {
+		return err
+	}
+	b, err := json.Marshal(baseChainCache{
+		TargetContracts: f.config.Fuzzing.TargetContracts,
+		Chain:           export,
+	})
+	if err != nil {
+		return err
+	}
+
+	// Remove any stale caches, then write our own.
+	err = utils.MakeDirectory(f.baseChainCacheDirectory())
+	if err != nil {
+		return err
+	}
+	existingFiles, err := os.ReadDir(f.baseChainCacheDirectory())
+	if err != nil {
+		return err
+	}
+	for _, existingFile := range existingFiles {
+		existingPath := filepath.Join(f.baseChainCacheDirectory(), existingFile.Name())
+		if !existingFile.IsDir() && strings.HasSuffix(existingFile.Name(), ".json") && existingPath != cachePath {
+			err = os.Remove(existingPath)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return os.WriteFile(cachePath, b, 0644)
+}
@@ -0,0 +1,153 @@
+package fuzzing
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/valuegeneration"
+	"github.com/crytic/medusa/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"golang.org/x/exp/maps"
+)
+
+// dictionaryFileName describes the name of the file in the corpus directory which values added to the value dictionary
+// by cheat codes are persisted to.
+const dictionaryFileName = "dictionary.json"
+
+// persistedDictionary describes the values added to the value dictionary by cheat codes at runtime, as persisted in the
+// corpus directory so they can be restored in later runs.
+type persistedDictionary struct {
+	// Integers describes the integers added to the dictionary.
+	Integers []*big.Int `json:"integers"`
+
+	// Addresses describes the addresses added to the dictionary.
+	Addresses []common.Address `json:"addresses"`
+
+	// Bytes describes the byte sequences added to the dictionary.
+	Bytes []hexutil.Bytes `json:"bytes"`
+
+	// Strings describes the strings added to the dictionary.
+	Strings []string `json:"strings"`
+}
+
+// dictionaryPath returns the path of the file in the corpus directory which the value dictionary is persisted to.
+func (f *Fuzzer) dictionaryPath() string {
+	return filepath.Join(f.config.Fuzzing.CorpusDirectory, dictionaryFileName)
+}
+
+// cloneBaseValueSet obtains a copy of the fuzzer's base value set, including values added to the value dictionary at
+// runtime, for a worker to build on.
+// Returns the cloned value set.
+func (f *Fuzzer) cloneBaseValueSet() *valuegeneration.ValueSet {
+	f.baseValueSetLock.Lock()
+	defer f.baseValueSetLock.Unlock()
+	return f.baseValueSet.Clone()
+}
+
+// addDictionaryValues adds values to the value dictionary, so they are included in the base value set of workers
+// created after this point. Each value must be a *big.Int, common.Address, []byte or string, as provided by the
+// addToDictionary cheat codes. Values of any other type are ignored.
+func (f *Fuzzer) addDictionaryValues(values []any) {
+	f.baseValueSetLock.Lock()
+	defer f.baseValueSetLock.Unlock()
+	for _, value := range values {
+		addValueToValueSet(f.baseValueSet, value)
+		addValueToValueSet(f.dictionaryValueSet, value)
+	}
+}
+
+// addSetupDictionaryValues adds the values added to the value dictionary by cheat codes while setting up the provided
+// base test chain, or any chain linked to it, to the value dictionary. This must be called before any workers are
+// created, so the values are included in their base value sets.
+func (f *Fuzzer) addSetupDictionaryValues(baseTestChain *chain.TestChain) {
+	for _, setupChain := range append([]*chain.TestChain{baseTestChain}, maps.Values(baseTestChain.LinkedChains())...) {
+		for _, block := range setupChain.CommittedBlocks() {
+			for _, messageResults := range block.MessageResults {
+				f.addDictionaryValues(chain.GetCheatCodeDictionaryValues(messageResults))
+			}
+		}
+	}
+}
+
+// addValueToValueSet adds a value provided by the addToDictionary cheat codes to the provided value set, according to
+// its type. Values of unsupported types are ignored.
+func addValueToValueSet(valueSet *valuegeneration.ValueSet, value any) {
+	switch v := value.(type) {
+	case *big.Int:
+		valueSet.AddInteger(v)
+	case common.Address:
+		valueSet.AddAddress(v)
+	case []byte:
+		valueSet.AddBytes(v)
+	case string:
+		valueSet.AddString(v)
+	}
+}
+
+// loadDictionary restores the value dictionary persisted in the corpus directory by a previous run, if one exists.
+// Returns an error if the persisted dictionary could not be read.
+func (f *Fuzzer) loadDictionary() error {
+	b, err := os.ReadFile(f.dictionaryPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var dictionary persistedDictionary
+	err = json.Unmarshal(b, &dictionary)
+	if err != nil {
+		return err
+	}
+
+	// Add all of our persisted values to the dictionary.
+	values := make([]any, 0, len(dictionary.Integers)+len(dictionary.Addresses)+len(dictionary.Bytes)+len(dictionary.Strings))
+	for _, integer := range dictionary.Integers {
+		if integer != nil {
+			values = append(values, integer)
+		}
+	}
+	for _, address := range dictionary.Addresses {
+		values = append(values, address)
+	}
+	for _, b := range dictionary.Bytes {
+		values = append(values, []byte(b))
+	}
+	for _, s := range dictionary.Strings {
+		values = append(values, s)
+	}
+	f.addDictionaryValues(values)
+	return nil
+}
+
+// saveDictionary persists the values added to the value dictionary at runtime, including those restored from a
+// previous run, in the corpus directory.
+// Returns an error if the dictionary could not be written.
+func (f *Fuzzer) saveDictionary() error {
+	f.baseValueSetLock.Lock()
+	dictionary := persistedDictionary{
+		Integers:  f.dictionaryValueSet.Integers(),
+		Addresses: f.dictionaryValueSet.Addresses(),
+		Bytes:     make([]hexutil.Bytes, 0),
+		Strings:   f.dictionaryValueSet.Strings(),
+	}
+	for _, b := range f.dictionaryValueSet.Bytes() {
+		dictionary.Bytes = append(dictionary.Bytes, b)
+	}
+	f.baseValueSetLock.Unlock()
+
+	b, err := json.MarshalIndent(dictionary, "", "  ")
+	if err != nil {
+		return err
+	}
+	err = utils.MakeDirectory(f.config.Fuzzing.CorpusDirectory)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(f.dictionaryPath(), b, 0644)
+}
@@ -0,0 +1,117 @@
+package fuzzing
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/utils"
+)
+
+// gasSnapshotsReportFileName describes the name of the file in the report directory which the gas snapshots taken
+// during a fuzzing campaign are written to.
+const gasSnapshotsReportFileName = "gas_snapshots.json"
+
+// fuzzerGasSnapshot describes the gas snapshots taken with a given label during a fuzzing campaign.
+type fuzzerGasSnapshot struct {
+	// count describes the number of snapshots taken with the label.
+	count uint64
+
+	// min describes the least gas used by a snapshot taken with the label.
+	min uint64
+
+	// max describes the most gas used by a snapshot taken with the label.
+	max uint64
+
+	// total describes the sum of the gas used by all snapshots taken with the label.
+	total uint64
+}
+
+// gasSnapshotReport describes the gas snapshots taken with a given label during a fuzzing campaign, as written to the
+// gas snapshot report.
+type gasSnapshotReport struct {
+	// Label describes the label the snapshots were taken with.
+	Label string `json:"label"`
+
+	// Count describes the number of snapshots taken with the label.
+	Count uint64 `json:"count"`
+
+	// Min describes the least gas used by a snapshot taken with the label.
+	Min uint64 `json:"min"`
+
+	// Max describes the most gas used by a snapshot taken with the label.
+	Max uint64 `json:"max"`
+
+	// Mean describes the mean gas used by the snapshots taken with the label.
+	Mean float64 `json:"mean"`
+}
+
+// recordGasSnapshots records the gas snapshots taken with the startSnapshotGas and stopSnapshotGas cheat codes by the
+// last call of the provided call sequence.
+func (f *Fuzzer) recordGasSnapshots(callSequence calls.CallSequence) {
+	// Obtain the gas snapshots taken by our last call, if any.
+	lastCall := callSequence[len(callSequence)-1]
+	if lastCall.ChainReference == nil {
+		return
+	}
+	snapshots := chain.GetCheatCodeGasSnapshots(lastCall.ChainReference.MessageResults())
+	if len(snapshots) == 0 {
+		return
+	}
+
+	f.gasSnapshotsLock.Lock()
+	defer f.gasSnapshotsLock.Unlock()
+	for _, snapshot := range snapshots {
+		aggregate, ok := f.gasSnapshots[snapshot.Label]
+		if !ok {
+			aggregate = &fuzzerGasSnapshot{min: snapshot.GasUsed, max: snapshot.GasUsed}
+			f.gasSnapshots[snapshot.Label] = aggregate
+		}
+		aggregate.count++
+		aggregate.total += snapshot.GasUsed
+		aggregate.min = min(aggregate.min, snapshot.GasUsed)
+		aggregate.max = max(aggregate.max, snapshot.GasUsed)
+	}
+}
+
+// writeGasSnapshotsReport writes the gas snapshots taken during the fuzzing campaign, aggregated by label, to a report
+// in the provided directory. No report is written if no snapshots were taken.
+// Returns the path of the report, or an empty string if none was written, or an error if one occurs.
+func (f *Fuzzer) writeGasSnapshotsReport(reportDir string) (string, error) {
+	f.gasSnapshotsLock.Lock()
+	reports := make([]gasSnapshotReport, 0, len(f.gasSnapshots))
+	for label, aggregate := range f.gasSnapshots {
+		reports = append(reports, gasSnapshotReport{
+			Label: label,
+			Count: aggregate.count,
+			Min:   aggregate.min,
+			Max:   aggregate.max,
+			Mean:  float64(aggregate.total) / float64(aggregate.count),
+		})
+	}
+	f.gasSnapshotsLock.Unlock()
+
+	// If no snapshots were taken, there is nothing to report.
+	if len(reports) == 0 {
+		return "", nil
+	}
+
+	// Sort our reports by label, so the report is deterministic.
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Label < reports[j].Label
+	})
+
+	b, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	err = utils.MakeDirectory(reportDir)
+	if err != nil {
+		return "", err
+	}
+	path := filepath.Join(reportDir, gasSnapshotsReportFileName)
+	return path, os.WriteFile(path, b, 0644)
+}
@@ -1,6 +1,9 @@
 package fuzzing
 
-import "math/big"
+import (
+	"math/big"
+	"sync"
+)
 
 // FuzzerMetrics represents a struct tracking metrics for a Fuzzer run.
 type FuzzerMetrics struct {
@@ -20,16 +23,36 @@ type fuzzerWorkerMetrics struct {
 	// callsTested is the amount of transactions/calls the fuzzer executed and ran tests against.
 	callsTested *big.Int
 
+	// callsDiscarded is the amount of transactions/calls the fuzzer executed which were discarded because an assumption
+	// made with the assume cheat code was not met.
+	callsDiscarded *big.Int
+
 	// gasUsed is the amount of gas the fuzzer executed and ran tests against.
 	gasUsed *big.Int
 
 	// workerStartupCount is the amount of times the worker was generated, or re-generated for this index.
 	workerStartupCount *big.Int
 
+	// methodMetrics describes the metrics for calls to each method made by the worker, keyed by the method's contract
+	// name and signature (e.g. "Contract.method(uint256)"), with values of type *fuzzerMethodMetrics. Unlike the other
+	// metrics, methods are added to it while the fuzzer reads it, which a plain map does not permit, so a sync.Map is
+	// used.
+	methodMetrics *sync.Map
+
 	// shrinking indicates whether the fuzzer worker is currently shrinking.
 	shrinking bool
 }
 
+// fuzzerMethodMetrics represents metrics for calls to a single method made by a FuzzerWorker instance.
+type fuzzerMethodMetrics struct {
+	// callsTested is the amount of calls to the method the worker executed.
+	callsTested *big.Int
+
+	// callsDiscarded is the amount of calls to the method the worker executed which were discarded because an
+	// assumption made with the assume cheat code was not met.
+	callsDiscarded *big.Int
+}
+
 // newFuzzerMetrics obtains a new FuzzerMetrics struct for a given number of workers specified by workerCount.
 // Returns the new FuzzerMetrics object.
 func newFuzzerMetrics(workerCount int) *FuzzerMetrics {
@@ -41,8 +64,10 @@ func newFuzzerMetrics(workerCount int) *FuzzerMetrics {
 		metrics.workerMetrics[i].sequencesTested = big.NewInt(0)
 		metrics.workerMetrics[i].failedSequences = big.NewInt(0)
 		metrics.workerMetrics[i].callsTested = big.NewInt(0)
+		metrics.workerMetrics[i].callsDiscarded = big.NewInt(0)
 		metrics.workerMetrics[i].workerStartupCount = big.NewInt(0)
 		metrics.workerMetrics[i].gasUsed = big.NewInt(0)
+		metrics.workerMetrics[i].methodMetrics = &sync.Map{}
 	}
 	return &metrics
 }
@@ -74,6 +99,63 @@ func (m *FuzzerMetrics) CallsTested() *big.Int {
 	return transactionsTested
 }
 
+// CallsDiscarded returns the amount of transactions/calls the fuzzer executed which were discarded because an
+// assumption made with the assume cheat code was not met.
+func (m *FuzzerMetrics) CallsDiscarded() *big.Int {
+	callsDiscarded := big.NewInt(0)
+	for _, workerMetrics := range m.workerMetrics {
+		callsDiscarded.Add(callsDiscarded, workerMetrics.callsDiscarded)
+	}
+	return callsDiscarded
+}
+
+// MethodDiscardRates returns the fraction of calls to each method which were discarded because an assumption made with
+// the assume cheat code was not met, keyed by the method's contract name and signature (e.g.
+// "Contract.method(uint256)"). Only methods which had calls discarded are included.
+func (m *FuzzerMetrics) MethodDiscardRates() map[string]float64 {
+	// Sum the calls tested and discarded for each method across all workers.
+	callsTested := make(map[string]*big.Int)
+	callsDiscarded := make(map[string]*big.Int)
+	for _, workerMetrics := range m.workerMetrics {
+		workerMetrics.methodMetrics.Range(func(key, value any) bool {
+			methodName, methodMetrics := key.(string), value.(*fuzzerMethodMetrics)
+			if _, ok := callsTested[methodName]; !ok {
+				callsTested[methodName] = big.NewInt(0)
+				callsDiscarded[methodName] = big.NewInt(0)
+			}
+			callsTested[methodName].Add(callsTested[methodName], methodMetrics.callsTested)
+			callsDiscarded[methodName].Add(callsDiscarded[methodName], methodMetrics.callsDiscarded)
+			return true
+		})
+	}
+
+	discardRates := make(map[string]float64)
+	for methodName, discarded := range callsDiscarded {
+		if discarded.Sign() > 0 {
+			discardRates[methodName], _ = new(big.Rat).SetFrac(discarded, callsTested[methodName]).Float64()
+		}
+	}
+	return discardRates
+}
+
+// updateMethodMetrics records a call the worker made to the method with the provided name, and whether it was
+// discarded.
+func (m *fuzzerWorkerMetrics) updateMethodMetrics(methodName string, discarded bool) {
+	value, ok := m.methodMetrics.Load(methodName)
+	if !ok {
+		value, _ = m.methodMetrics.LoadOrStore(methodName, &fuzzerMethodMetrics{
+			callsTested:    big.NewInt(0),
+			callsDiscarded: big.NewInt(0),
+		})
+	}
+	methodMetrics := value.(*fuzzerMethodMetrics)
+	methodMetrics.callsTested.Add(methodMetrics.callsTested, big.NewInt(1))
+	if discarded {
+		methodMetrics.callsDiscarded.Add(methodMetrics.callsDiscarded, big.NewInt(1))
+	}
+}
+
+// GasUsed returns the amount of gas the fuzzer executed and ran tests against.
 func (m *FuzzerMetrics) GasUsed() *big.Int {
 	gasUsed := big.NewInt(0)
 	for _, workerMetrics := range m.workerMetrics {
@@ -0,0 +1,82 @@
+package fuzzing
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/logging"
+	"github.com/crytic/medusa/logging/colors"
+	"golang.org/x/exp/maps"
+)
+
+// fuzzerMilestone describes a named milestone reached with the cover cheat code during a fuzzing campaign.
+type fuzzerMilestone struct {
+	// hitCount describes the number of times the milestone was reached.
+	hitCount uint64
+
+	// shortestSequence describes the shortest call sequence observed to reach the milestone, ending with the call
+	// which reached it.
+	shortestSequence calls.CallSequence
+}
+
+// recordMilestones records the milestones reached with the cover cheat code by the last call of the provided call
+// sequence, retaining a copy of the sequence for each milestone it is the shortest observed sequence to reach.
+// Returns an error if one occurs.
+func (f *Fuzzer) recordMilestones(callSequence calls.CallSequence) error {
+	// Obtain the milestones reached by our last call, if any.
+	lastCall := callSequence[len(callSequence)-1]
+	if lastCall.ChainReference == nil {
+		return nil
+	}
+	milestones := chain.GetCheatCodeMilestones(lastCall.ChainReference.MessageResults())
+	if len(milestones) == 0 {
+		return nil
+	}
+
+	f.milestonesLock.Lock()
+	defer f.milestonesLock.Unlock()
+	for _, name := range milestones {
+		milestone, ok := f.milestones[name]
+		if !ok {
+			milestone = &fuzzerMilestone{}
+			f.milestones[name] = milestone
+		}
+		milestone.hitCount++
+
+		// If this is the shortest sequence to reach the milestone, retain a copy of it.
+		if milestone.shortestSequence == nil || len(callSequence) < len(milestone.shortestSequence) {
+			shortestSequence, err := callSequence.Clone()
+			if err != nil {
+				return err
+			}
+			milestone.shortestSequence = shortestSequence
+		}
+	}
+	return nil
+}
+
+// printMilestones prints the milestones reached with the cover cheat code during the fuzzing campaign, how often they
+// were reached, and the shortest call sequence observed to reach each of them.
+func (f *Fuzzer) printMilestones() {
+	f.milestonesLock.Lock()
+	defer f.milestonesLock.Unlock()
+
+	// If no milestones were reached, there is nothing to report.
+	if len(f.milestones) == 0 {
+		return
+	}
+
+	// Print each milestone, sorted by name.
+	names := maps.Keys(f.milestones)
+	sort.Strings(names)
+	f.logger.Info("Milestones reached: ", colors.Bold, len(names), colors.Reset)
+	for _, name := range names {
+		milestone := f.milestones[name]
+		buffer := logging.NewLogBuffer()
+		buffer.Append(colors.Bold, name, colors.Reset, ": ", fmt.Sprintf("reached %d time(s), shortest sequence (%d call(s)):\n", milestone.hitCount, len(milestone.shortestSequence)))
+		buffer.Append(milestone.shortestSequence.Log().Elements()...)
+		f.logger.Info(buffer.Elements()...)
+	}
+}
@@ -0,0 +1,202 @@
+package fuzzing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/calls"
+	fuzzerTypes "github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/executiontracer"
+	"github.com/crytic/medusa/fuzzing/rpcserver"
+	"github.com/crytic/medusa/logging/colors"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Serve sets up a test chain in the same way Start does, replays the call sequence stored at the provided path on it,
+// and serves the resulting chain over a JSON-RPC server listening on the provided address, so its state can be
+// inspected with external tooling. If no call sequence path is provided, the most recent test result in the corpus
+// directory is replayed, if any exist. This operation will not return until an error is encountered or it is cancelled
+// using the Stop method.
+// Returns an error if one is encountered.
+func (f *Fuzzer) Serve(address string, callSequencePath string) error {
+	// Create our running context (allows us to cancel across threads)
+	f.ctx, f.ctxCancelFunc = context.WithCancel(context.Background())
+
+	// Create our test chain
+	testChain, err := f.createTestChain()
+	if err != nil {
+		f.logger.Error("Failed to create the test chain", err)
+		return err
+	}
+	defer testChain.Close()
+
+	// Track the contracts deployed to our chain, so we can resolve the contracts targeted by our call sequence.
+	deployedContracts := make(map[common.Address]*fuzzerTypes.Contract)
+	testChain.Events.ContractDeploymentAddedEventEmitter.Subscribe(func(event chain.ContractDeploymentsAddedEvent) error {
+		matchedContract := f.contractDefinitions.MatchBytecode(event.Contract.InitBytecode, event.Contract.RuntimeBytecode)
+		if matchedContract != nil {
+			deployedContracts[event.Contract.Address] = matchedContract
+		}
+		return nil
+	})
+	testChain.Events.ContractDeploymentRemovedEventEmitter.Subscribe(func(event chain.ContractDeploymentsRemovedEvent) error {
+		delete(deployedContracts, event.Contract.Address)
+		return nil
+	})
+
+	// Create our server prior to setting up our chain, so every transaction executed on it is traced.
+	server, err := rpcserver.NewServer(testChain, f.contractDefinitions, f.senders)
+	if err != nil {
+		f.logger.Error("Failed to create the JSON-RPC server", err)
+		return err
+	}
+
+	// Set up our chain with our deployment/setup strategy defined by the fuzzer.
+	f.logger.Info("Setting up test chain")
+	var trace *executiontracer.ExecutionTrace
+	trace, err = f.Hooks.ChainSetupFunc(f, testChain)
+	if err != nil {
+		if trace != nil {
+			f.logger.Error("Failed to initialize the test chain", err, errors.New(trace.Log().ColorString()))
+		} else {
+			f.logger.Error("Failed to initialize the test chain", err)
+		}
+		return err
+	}
+	f.logger.Info("Finished setting up test chain")
+
+	// If no call sequence was provided, use the latest test result in our corpus.
+	if callSequencePath == "" {
+		callSequencePath, err = f.latestTestResultPath()
+		if err != nil {
+			f.logger.Error("Failed to find a test result to replay", err)
+			return err
+		}
+	}
+
+	// Replay our call sequence, if we have one.
+	if callSequencePath != "" {
+		f.logger.Info("Replaying call sequence at ", colors.Bold, callSequencePath, colors.Reset)
+		err = f.replayCallSequenceFile(testChain, deployedContracts, callSequencePath)
+		if err != nil {
+			f.logger.Error("Failed to replay the call sequence", err)
+			return err
+		}
+	} else {
+		f.logger.Info("No call sequence was provided or found in the corpus, serving the test chain after setup")
+	}
+
+	// Close our server once our context is cancelled.
+	go func() {
+		<-f.ctx.Done()
+		_ = server.Close()
+	}()
+
+	// Serve our chain until we are stopped.
+	f.logger.Info("Serving JSON-RPC at ", colors.Bold, "http://", address, colors.Reset)
+	err = server.ListenAndServe(address)
+	if err != nil {
+		f.logger.Error("Failed to serve JSON-RPC", err)
+	}
+	return err
+}
+
+// latestTestResultPath obtains the path of the most recently modified test result call sequence in the corpus
+// directory.
+// Returns the path, an empty string if no corpus directory is set or no test results exist, or an error if one
+// occurred.
+func (f *Fuzzer) latestTestResultPath() (string, error) {
+	// If we have no corpus directory, there are no test results.
+	if f.config.Fuzzing.CorpusDirectory == "" {
+		return "", nil
+	}
+
+	// Read our test results directory, if it exists.
+	testResultsDirectory := filepath.Join(f.config.Fuzzing.CorpusDirectory, "test_results")
+	dirEntries, err := os.ReadDir(testResultsDirectory)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	// Find the most recently modified test result.
+	latestPath := ""
+	var latestModTime int64
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), ".json") {
+			continue
+		}
+		info, err := dirEntry.Info()
+		if err != nil {
+			return "", err
+		}
+		if latestPath == "" || info.ModTime().UnixNano() > latestModTime {
+			latestPath = filepath.Join(testResultsDirectory, dirEntry.Name())
+			latestModTime = info.ModTime().UnixNano()
+		}
+	}
+	return latestPath, nil
+}
+
+// replayCallSequenceFile reads the call sequence stored at the provided path and executes it on the provided chain,
+// resolving the contracts targeted by each call from the provided deployed contracts.
+// Returns an error if one occurred.
+func (f *Fuzzer) replayCallSequenceFile(testChain *chain.TestChain, deployedContracts map[common.Address]*fuzzerTypes.Contract, callSequencePath string) error {
+	// Read and parse our call sequence.
+	b, err := os.ReadFile(callSequencePath)
+	if err != nil {
+		return err
+	}
+	var callSequence calls.CallSequence
+	err = json.Unmarshal(b, &callSequence)
+	if err != nil {
+		return fmt.Errorf("could not parse call sequence file %s: %v", callSequencePath, err)
+	}
+
+	// Resolve the contract each call targets as it executes, as contracts may be deployed by earlier calls.
+	fetchElementFunc := func(currentIndex int) (*calls.CallSequenceElement, error) {
+		// If we are at the end of our sequence, return nil indicating we should stop executing.
+		if currentIndex >= len(callSequence) {
+			return nil, nil
+		}
+
+		// If we are deploying a contract and not targeting one with this call, there should be no work to do.
+		callSequenceElement := callSequence[currentIndex]
+		if callSequenceElement.Call.To == nil {
+			return callSequenceElement, nil
+		}
+
+		// Resolve the contract our call targets, and its method if our call data uses ABI values.
+		resolvedContract, resolvedContractExists := deployedContracts[*callSequenceElement.Call.To]
+		if !resolvedContractExists {
+			return nil, fmt.Errorf("contract at address '%v' could not be resolved", callSequenceElement.Call.To.String())
+		}
+		callSequenceElement.Contract = resolvedContract
+		if callSequenceElement.Call.DataAbiValues != nil {
+			err := callSequenceElement.Call.DataAbiValues.Resolve(resolvedContract.CompiledContract().Abi)
+			if err != nil {
+				return nil, fmt.Errorf("error resolving method in contract '%v': %v", resolvedContract.Name(), err)
+			}
+		}
+		return callSequenceElement, nil
+	}
+
+	// Execute our call sequence and report each call executed.
+	executedCallSequence, err := calls.ExecuteCallSequenceIteratively(testChain, fetchElementFunc, nil)
+	if err != nil {
+		return err
+	}
+	for _, callSequenceElement := range executedCallSequence {
+		txHash := callSequenceElement.ChainReference.MessageResults().Receipt.TxHash
+		f.logger.Info(callSequenceElement.String(), " ", colors.Bold, "tx=", txHash.String(), colors.Reset)
+	}
+	return nil
+}
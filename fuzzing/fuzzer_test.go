@@ -2,18 +2,28 @@ package fuzzing
 
 import (
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"math/big"
 	"math/rand"
+	"os"
+	"path/filepath"
 	"reflect"
+	"sync"
 	"testing"
 
 	"github.com/crytic/medusa/fuzzing/executiontracer"
 
 	"github.com/crytic/medusa/chain"
+	chainConfig "github.com/crytic/medusa/chain/config"
+	"github.com/crytic/medusa/compilation"
+	"github.com/crytic/medusa/compilation/platforms"
 	"github.com/crytic/medusa/events"
 	"github.com/crytic/medusa/fuzzing/calls"
 	"github.com/crytic/medusa/fuzzing/valuegeneration"
 	"github.com/crytic/medusa/utils"
+	"github.com/crytic/medusa/utils/testutils"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/crytic/medusa/fuzzing/config"
@@ -207,6 +217,67 @@ func TestChainBehaviour(t *testing.T) {
 	})
 }
 
+// TestContractPrecompile runs a test to ensure contracts installed as precompiles are constructed before their runtime
+// bytecode and storage are placed at the precompile address.
+func TestContractPrecompile(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/chain/contract_precompile.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.TargetContracts = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 1_000
+			config.Fuzzing.TestChainConfig.Precompiles = []chainConfig.PrecompileConfig{
+				{Address: common.HexToAddress("0x1000"), ContractName: "ConstructedPrecompile"},
+			}
+			config.Fuzzing.Testing.AssertionTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Assert that we should not have failures.
+			assertFailedTestsExpected(f, false)
+		},
+	})
+}
+
+// TestMultiChainRelaying runs a test to ensure messages emitted on a linked chain are relayed to the primary chain, and
+// that property tests can query contracts on linked chains.
+func TestMultiChainRelaying(t *testing.T) {
+	// Deploy our escrow to a linked chain, relaying its messages to our minter on the primary chain.
+	linkedChains := []config.LinkedChainConfig{
+		{Name: "l1", ChainID: 5, TargetContracts: []string{"Escrow"}},
+	}
+	relays := []config.RelayConfig{
+		{SourceChain: "l1", DestinationChain: chain.PrimaryChainName, EmitterContract: "Escrow", Event: "MessageSent"},
+	}
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/chain/multi_chain_relay.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.TargetContracts = []string{"Minter"}
+			config.Fuzzing.ConstructorArgs = map[string]map[string]any{
+				"Escrow": {
+					"_minter": "DeployedContract:Minter",
+				},
+			}
+			config.Fuzzing.MultiChain.Enabled = true
+			config.Fuzzing.MultiChain.Chains = linkedChains
+			config.Fuzzing.MultiChain.Relays = relays
+			config.Fuzzing.Testing.AssertionTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for failed property tests.
+			assertFailedTestsExpected(f, true)
+		},
+	})
+}
+
 // TestCheatCodes runs tests to ensure that vm extensions ("cheat codes") are working as intended.
 func TestCheatCodes(t *testing.T) {
 	filePaths := []string{
@@ -214,15 +285,26 @@ func TestCheatCodes(t *testing.T) {
 		"testdata/contracts/cheat_codes/utils/to_string.sol",
 		"testdata/contracts/cheat_codes/utils/sign.sol",
 		"testdata/contracts/cheat_codes/utils/parse.sol",
+		"testdata/contracts/cheat_codes/utils/wallets.sol",
 		"testdata/contracts/cheat_codes/vm/snapshot_and_revert_to.sol",
 		"testdata/contracts/cheat_codes/vm/coinbase.sol",
+		"testdata/contracts/cheat_codes/vm/blobs.sol",
 		"testdata/contracts/cheat_codes/vm/chain_id.sol",
 		"testdata/contracts/cheat_codes/vm/deal.sol",
+		"testdata/contracts/cheat_codes/vm/deploy_code.sol",
 		"testdata/contracts/cheat_codes/vm/difficulty.sol",
 		"testdata/contracts/cheat_codes/vm/etch.sol",
+		"testdata/contracts/cheat_codes/vm/expect_call.sol",
+		"testdata/contracts/cheat_codes/vm/expect_emit.sol",
+		"testdata/contracts/cheat_codes/vm/expect_revert.sol",
 		"testdata/contracts/cheat_codes/vm/fee.sol",
+		"testdata/contracts/cheat_codes/vm/json.sol",
+		"testdata/contracts/cheat_codes/vm/mock_call.sol",
 		"testdata/contracts/cheat_codes/vm/prank.sol",
+		"testdata/contracts/cheat_codes/vm/record.sol",
+		"testdata/contracts/cheat_codes/vm/record_logs.sol",
 		"testdata/contracts/cheat_codes/vm/roll.sol",
+		"testdata/contracts/cheat_codes/vm/start_prank.sol",
 		"testdata/contracts/cheat_codes/vm/store_load.sol",
 		"testdata/contracts/cheat_codes/vm/warp.sol",
 	}
@@ -269,6 +351,308 @@ func TestCheatCodes(t *testing.T) {
 	}
 }
 
+// TestCheatCodeAccessControl runs tests to ensure that cheat codes can only be called by the callers allowed by the
+// chain configuration, and that the ffi cheat code only executes the commands it allows.
+func TestCheatCodeAccessControl(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/cheat_codes/vm/allowed_callers.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.TargetContracts = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 1_000
+			config.Fuzzing.PredeployedContracts = map[string]string{"TrustedContract": "0x1234"}
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+			config.Fuzzing.TestChainConfig.CheatCodeConfig.CheatCodesEnabled = true
+			config.Fuzzing.TestChainConfig.CheatCodeConfig.AllowedCallers = []common.Address{common.HexToAddress("0x1234")}
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for failed assertion tests.
+			assertFailedTestsExpected(f, false)
+		},
+	})
+
+	// FFI tests rely on "echo", which is a shell command on Windows, so we only run them on other platforms.
+	if utils.IsWindowsEnvironment() {
+		return
+	}
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/cheat_codes/utils/ffi_allowlist_unix.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.TargetContracts = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 1_000
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+			config.Fuzzing.TestChainConfig.CheatCodeConfig.CheatCodesEnabled = true
+			config.Fuzzing.TestChainConfig.CheatCodeConfig.EnableFFI = true
+			config.Fuzzing.TestChainConfig.CheatCodeConfig.AllowedFFICommands = []chainConfig.FFICommandConfig{
+				{Executable: "echo", Arguments: []string{"-n", "h[a-z]+"}},
+			}
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for failed assertion tests.
+			assertFailedTestsExpected(f, false)
+		},
+	})
+}
+
+// TestCheatCodesFileSystemAndEnvironment runs tests to ensure that cheat codes which access the file system and
+// environment variables behave as expected, and only access the paths allowed by the chain configuration.
+func TestCheatCodesFileSystemAndEnvironment(t *testing.T) {
+	t.Setenv("MEDUSA_TEST_UINT", "0x2a")
+	t.Setenv("MEDUSA_TEST_INT", "-42")
+	t.Setenv("MEDUSA_TEST_ADDRESS", "0x7109709ECfa91a80626fF3989D68f67F5b1DD12D")
+	t.Setenv("MEDUSA_TEST_BOOL", "true")
+	t.Setenv("MEDUSA_TEST_BYTES32", "0x1234")
+	t.Setenv("MEDUSA_TEST_STRING", "medusa")
+
+	filePaths := []string{
+		"testdata/contracts/cheat_codes/vm/env.sol",
+		"testdata/contracts/cheat_codes/vm/file_system.sol",
+	}
+	for _, filePath := range filePaths {
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: filePath,
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.TargetContracts = []string{"TestContract"}
+
+				// Use a single worker, so files are not written while another worker reads them.
+				config.Fuzzing.Workers = 1
+				config.Fuzzing.TestLimit = uint64(config.Fuzzing.CallSequenceLength) * 3
+
+				// enable assertion testing only
+				config.Fuzzing.Testing.PropertyTesting.Enabled = false
+				config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+				config.Fuzzing.Testing.AssertionTesting.Enabled = true
+
+				config.Fuzzing.TestChainConfig.CheatCodeConfig.CheatCodesEnabled = true
+				config.Fuzzing.TestChainConfig.CheatCodeConfig.ReadablePaths = []string{"fixtures"}
+				config.Fuzzing.TestChainConfig.CheatCodeConfig.WritablePaths = []string{"out"}
+			},
+			method: func(f *fuzzerTestContext) {
+				// Create a fixture for the fuzzer to read.
+				err := os.MkdirAll("fixtures", 0755)
+				assert.NoError(t, err)
+				err = os.WriteFile(filepath.Join("fixtures", "fixture.txt"), []byte("fixture"), 0644)
+				assert.NoError(t, err)
+
+				// Start the fuzzer
+				err = f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// Check for failed assertion tests.
+				assertFailedTestsExpected(f, false)
+			},
+		})
+	}
+}
+
+// TestCheatCodeAssume runs a test to ensure that calls whose assumptions made with the assume cheat code are not met are
+// discarded, rather than reported as failures, and are reflected in the fuzzer's discard metrics.
+func TestCheatCodeAssume(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/cheat_codes/vm/assume.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.TargetContracts = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 1_000
+			config.Fuzzing.CorpusDirectory = "corpus"
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+			config.Fuzzing.TestChainConfig.CheatCodeConfig.CheatCodesEnabled = true
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for failed assertion tests.
+			assertFailedTestsExpected(f, false)
+
+			// Verify some calls were discarded, and attributed to the method which discarded them.
+			assert.Positive(t, f.fuzzer.metrics.CallsDiscarded().Sign())
+			assert.Positive(t, f.fuzzer.metrics.MethodDiscardRates()["TestContract.test(uint256)"])
+
+			// Obtain the ABI of our target contract, so we can decode the input values of saved call sequences.
+			var contractAbi *abi.ABI
+			for _, contract := range f.fuzzer.contractDefinitions {
+				if contract.Name() == "TestContract" {
+					contractAbi = &contract.CompiledContract().Abi
+				}
+			}
+			if !assert.NotNil(t, contractAbi) {
+				return
+			}
+
+			// Verify no discarded calls were saved in the corpus, as part of any call sequence.
+			sequenceFiles, err := filepath.Glob(filepath.Join("corpus", "call_sequences", "*.json"))
+			assert.NoError(t, err)
+			assert.NotEmpty(t, sequenceFiles)
+			for _, sequenceFile := range sequenceFiles {
+				b, err := os.ReadFile(sequenceFile)
+				assert.NoError(t, err)
+				var sequence calls.CallSequence
+				err = json.Unmarshal(b, &sequence)
+				assert.NoError(t, err)
+				for _, element := range sequence {
+					err = element.Call.DataAbiValues.Resolve(*contractAbi)
+					assert.NoError(t, err)
+					x := element.Call.DataAbiValues.InputValues[0].(*big.Int)
+					assert.EqualValues(t, 0, x.Bit(0), "discarded call saved in %v", sequenceFile)
+				}
+			}
+		},
+	})
+}
+
+// TestCheatCodeAddToDictionary runs a test to ensure that values added to the value dictionary with cheat codes are
+// used as inputs by the fuzzer, and are persisted in the corpus directory if configured.
+func TestCheatCodeAddToDictionary(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/cheat_codes/vm/add_to_dictionary.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.TargetContracts = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 10_000
+			config.Fuzzing.CorpusDirectory = "corpus"
+			config.Fuzzing.PersistDictionary = true
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+			config.Fuzzing.TestChainConfig.CheatCodeConfig.CheatCodesEnabled = true
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for failed assertion tests.
+			assertFailedTestsExpected(f, true)
+
+			// Verify the value added to the dictionary was persisted.
+			b, err := os.ReadFile(filepath.Join("corpus", dictionaryFileName))
+			assert.NoError(t, err)
+			var dictionary persistedDictionary
+			err = json.Unmarshal(b, &dictionary)
+			assert.NoError(t, err)
+			assert.Len(t, dictionary.Integers, 1)
+		},
+	})
+}
+
+// TestCheatCodeCover runs a test to ensure that milestones reached with the cover cheat code are recorded as coverage
+// and reported by the fuzzer, alongside the shortest sequence which reached them, unless the call reverts.
+func TestCheatCodeCover(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/cheat_codes/vm/cover.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.TargetContracts = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 5_000
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+			config.Fuzzing.TestChainConfig.CheatCodeConfig.CheatCodesEnabled = true
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Verify both stages were reached, and the second stage required at least two calls.
+			assert.Contains(t, f.fuzzer.milestones, "stage one")
+			assert.Contains(t, f.fuzzer.milestones, "stage two")
+			if milestone, ok := f.fuzzer.milestones["stage two"]; ok {
+				assert.Positive(t, milestone.hitCount)
+				assert.GreaterOrEqual(t, len(milestone.shortestSequence), 2)
+			}
+
+			// Verify milestones were recorded as coverage, and milestones reached by reverted calls were not recorded.
+			assert.Contains(t, f.fuzzer.corpus.CoverageMaps().Milestones(), "stage two")
+			assert.NotContains(t, f.fuzzer.milestones, "reverted")
+			assert.NotContains(t, f.fuzzer.corpus.CoverageMaps().Milestones(), "reverted")
+		},
+	})
+}
+
+// TestCheatCodeGasSnapshots runs a test to ensure that gas snapshots taken with cheat codes are aggregated by label
+// across the fuzzing campaign and written to a report.
+func TestCheatCodeGasSnapshots(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/cheat_codes/vm/gas_snapshots.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.TargetContracts = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 1_000
+			config.Fuzzing.CorpusDirectory = "corpus"
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+			config.Fuzzing.Testing.AssertionTesting.Enabled = true
+			config.Fuzzing.TestChainConfig.CheatCodeConfig.CheatCodesEnabled = true
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for any failed tests and verify coverage was captured
+			assertFailedTestsExpected(f, false)
+			assertCorpusCallSequencesCollected(f, true)
+
+			// Verify our snapshots were aggregated by label, and writing values varied the gas used.
+			assert.Contains(t, f.fuzzer.gasSnapshots, "writeValuesPaused")
+			assert.Contains(t, f.fuzzer.gasSnapshots, "misuse")
+			if snapshot, ok := f.fuzzer.gasSnapshots["writeValues"]; assert.True(t, ok) {
+				assert.Greater(t, snapshot.count, uint64(1))
+				assert.Less(t, snapshot.min, snapshot.max)
+			}
+
+			// Verify the report was written next to our coverage reports.
+			b, err := os.ReadFile(filepath.Join("corpus", "coverage", gasSnapshotsReportFileName))
+			assert.NoError(t, err)
+			var reports []gasSnapshotReport
+			assert.NoError(t, json.Unmarshal(b, &reports))
+			assert.Len(t, reports, 3)
+		},
+	})
+}
+
+// TestCheatCodeExpectationFailures runs tests to ensure that cheat code expectations which are not met are reported as
+// assertion failures.
+func TestCheatCodeExpectationFailures(t *testing.T) {
+	filePaths := []string{
+		"testdata/contracts/cheat_codes/vm/expect_call_failure.sol",
+		"testdata/contracts/cheat_codes/vm/expect_emit_failure.sol",
+		"testdata/contracts/cheat_codes/vm/expect_revert_failure.sol",
+	}
+	for _, filePath := range filePaths {
+		runFuzzerTest(t, &fuzzerSolcFileTest{
+			filePath: filePath,
+			configUpdates: func(config *config.ProjectConfig) {
+				config.Fuzzing.TargetContracts = []string{"TestContract"}
+				config.Fuzzing.Testing.PropertyTesting.Enabled = false
+				config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+				config.Fuzzing.Testing.AssertionTesting.Enabled = true
+				config.Fuzzing.TestChainConfig.CheatCodeConfig.CheatCodesEnabled = true
+			},
+			method: func(f *fuzzerTestContext) {
+				// Start the fuzzer
+				err := f.fuzzer.Start()
+				assert.NoError(t, err)
+
+				// Check for failed assertion tests.
+				assertFailedTestsExpected(f, true)
+			},
+		})
+	}
+}
+
 // TestConsoleLog tests the console.log precompile contract by logging a variety of different primitive types and
 // then failing. The execution trace for the failing call sequence should hold the various logs.
 func TestConsoleLog(t *testing.T) {
@@ -494,6 +878,7 @@ func TestDeploymentsSelfDestruct(t *testing.T) {
 func TestExecutionTraces(t *testing.T) {
 	expectedMessagesPerTest := map[string][]string{
 		"testdata/contracts/execution_tracing/call_and_deployment_args.sol": {"Hello from deployment args!", "Hello from call args!"},
+		"testdata/contracts/execution_tracing/cheatcode_expectations.sol":   {"[expectation failed ('expectEmit: expected event("},
 		"testdata/contracts/execution_tracing/cheatcodes.sol":               {"StdCheats.toString(bool)(true)"},
 		"testdata/contracts/execution_tracing/event_emission.sol":           {"TestEvent", "TestIndexedEvent", "TestMixedEvent", "Hello from event args!", "Hello from library event args!"},
 		"testdata/contracts/execution_tracing/proxy_call.sol":               {"TestContract -> InnerDeploymentContract.setXY", "Hello from proxy call args!"},
@@ -800,6 +1185,51 @@ func TestVMCorrectness(t *testing.T) {
 	})
 }
 
+// TestFeeAndRandomnessFuzzing runs tests to ensure gas prices, block base fees, and block prevrandao values are fuzzed
+// within their configured ranges.
+func TestFeeAndRandomnessFuzzing(t *testing.T) {
+	// Test all values are fuzzed when enabled.
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/vm_tests/block_fee_values_fuzzed.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.TargetContracts = []string{"TestContract"}
+			config.Fuzzing.FuzzGasPrice = true
+			config.Fuzzing.FuzzBlockBaseFee = true
+			config.Fuzzing.FuzzBlockPrevRandao = true
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check for any failed tests and verify coverage was captured
+			assertFailedTestsExpected(f, true)
+			assertCorpusCallSequencesCollected(f, true)
+		},
+	})
+
+	// Test values remain at their defaults when they are not fuzzed, as is the default.
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/vm_tests/block_fee_values_fuzzed.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.TargetContracts = []string{"TestContract"}
+			config.Fuzzing.TestLimit = 1_000
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+
+			// Check that no tests failed
+			assertFailedTestsExpected(f, false)
+		},
+	})
+}
+
 // TestCorpusReplayability will test whether the corpus, when replayed, will end up with the same coverage.
 // Additionally, check if the second run is solved with sequences executed being less or equal to the total corpus
 // call sequences. This should occur as the corpus call sequences should be executed unmodified first (including
@@ -858,6 +1288,56 @@ func TestCorpusReplayability(t *testing.T) {
 	})
 }
 
+// TestBaseChainCaching will test whether the base chain is cached in the corpus directory after setup, and whether it
+// is restored in a subsequent run rather than being set up again.
+func TestBaseChainCaching(t *testing.T) {
+	runFuzzerTest(t, &fuzzerSolcFileTest{
+		filePath: "testdata/contracts/assertions/assert_immediate.sol",
+		configUpdates: func(config *config.ProjectConfig) {
+			config.Fuzzing.TargetContracts = []string{"TestContract"}
+			config.Fuzzing.CorpusDirectory = "corpus"
+			config.Fuzzing.CacheBaseChain = true
+			config.Fuzzing.Testing.PropertyTesting.Enabled = false
+			config.Fuzzing.Testing.OptimizationTesting.Enabled = false
+		},
+		method: func(f *fuzzerTestContext) {
+			// Start the fuzzer and ensure our test failed.
+			err := f.fuzzer.Start()
+			assert.NoError(t, err)
+			assertFailedTestsExpected(f, true)
+
+			// Verify our base chain was cached.
+			cacheFiles, err := os.ReadDir(f.fuzzer.baseChainCacheDirectory())
+			assert.NoError(t, err)
+			assert.Len(t, cacheFiles, 1)
+
+			// Append whitespace to our cache file. It is only rewritten if the chain is set up again, so it should
+			// remain if the chain is restored from it. Our test should fail again, as the contract is deployed in the
+			// restored chain.
+			cachePath := filepath.Join(f.fuzzer.baseChainCacheDirectory(), cacheFiles[0].Name())
+			b, err := os.ReadFile(cachePath)
+			assert.NoError(t, err)
+			b = append(b, '\n')
+			err = os.WriteFile(cachePath, b, 0644)
+			assert.NoError(t, err)
+			err = f.fuzzer.Start()
+			assert.NoError(t, err)
+			assertFailedTestsExpected(f, true)
+			restoredBytes, err := os.ReadFile(cachePath)
+			assert.NoError(t, err)
+			assert.EqualValues(t, b, restoredBytes)
+
+			// Replace our chain setup with a custom one. As the cache key cannot describe its inputs, the chain should
+			// be set up again rather than being restored.
+			f.fuzzer.Hooks.ChainSetupFunc = func(fuzzer *Fuzzer, testChain *chain.TestChain) (*executiontracer.ExecutionTrace, error) {
+				return nil, errors.New("custom chain setup was performed")
+			}
+			err = f.fuzzer.Start()
+			assert.ErrorContains(t, err, "custom chain setup was performed")
+		},
+	})
+}
+
 // TestDeploymentOrderWithCoverage will ensure that changing the order of deployment for the target contracts does not
 // lead to the same coverage. This is also proof that changing the order changes the addresses of the contracts leading
 // to the coverage not being useful.
@@ -946,3 +1426,136 @@ func TestExcludeFunctionSignatures(t *testing.T) {
 			}
 		}})
 }
+
+// BenchmarkShrinkCallSequence measures the rate at which calls are tested while shrinking a call sequence by removing
+// each of its calls in turn, comparing re-executing every shrunken sequence from the testing base against restoring a
+// snapshot of the prefix it shares with the sequence it was derived from. It is measured across contracts exercising
+// different features, with sequences whose calls are each included in their own block, and with sequences whose calls
+// share blocks, so snapshots are restored within pending blocks.
+func BenchmarkShrinkCallSequence(b *testing.B) {
+	corpus := []struct {
+		filePath       string
+		targetContract string
+	}{
+		{"testdata/contracts/value_generation/match_uints_xy.sol", "TestContract"},
+		{"testdata/contracts/corpus_mutation/specific_call_sequence.sol", "TestContract"},
+		{"testdata/contracts/optimizations/optimize.sol", "TestContract"},
+		{"testdata/contracts/deployments/inner_deployment.sol", "InnerDeploymentFactory"},
+	}
+	for _, entry := range corpus {
+		b.Run(filepath.Base(entry.filePath), func(b *testing.B) {
+			benchmarkShrinkCallSequence(b, entry.filePath, entry.targetContract)
+		})
+	}
+}
+
+// benchmarkShrinkCallSequence runs BenchmarkShrinkCallSequence for the provided contract file and target contract.
+func benchmarkShrinkCallSequence(b *testing.B, filePath string, targetContract string) {
+	const callSequenceLength = 100
+	const callsPerSharedBlock = 4
+
+	// Copy our target file to our test directory and run our benchmark there to avoid artifact pollution.
+	contractTestPath := testutils.CopyToTestDirectory(b, filePath)
+	testutils.ExecuteInDirectory(b, contractTestPath, func() {
+		// Create a fuzzer with a single worker.
+		compilationConfig, err := compilation.NewCompilationConfigFromPlatformConfig(platforms.NewCryticCompilationConfig(contractTestPath))
+		assert.NoError(b, err)
+		projectConfig := getFuzzerTestingProjectConfig(b, compilationConfig)
+		projectConfig.Fuzzing.Workers = 1
+		projectConfig.Fuzzing.TargetContracts = []string{targetContract}
+		fuzzer, err := NewFuzzer(*projectConfig)
+		assert.NoError(b, err)
+
+		// Once our worker has set up its chain and is about to test its first call sequence, hand it to our benchmark
+		// and block it until our benchmark has concluded.
+		workerReady := make(chan *FuzzerWorker)
+		benchmarkDone := make(chan struct{})
+		var handOff sync.Once
+		fuzzer.Events.WorkerCreated.Subscribe(func(event FuzzerWorkerCreatedEvent) error {
+			event.Worker.Events.CallSequenceTesting.Subscribe(func(event FuzzerWorkerCallSequenceTestingEvent) error {
+				handOff.Do(func() {
+					workerReady <- event.Worker
+					<-benchmarkDone
+				})
+				return nil
+			})
+			return nil
+		})
+
+		// Start our fuzzer and wait for our worker.
+		fuzzerErr := make(chan error, 1)
+		go func() {
+			fuzzerErr <- fuzzer.Start()
+		}()
+		var worker *FuzzerWorker
+		select {
+		case worker = <-workerReady:
+		case err = <-fuzzerErr:
+			b.Fatalf("fuzzer exited before benchmarking: %v", err)
+		}
+
+		// Define a shrink request which is never satisfied, so each call removal is tested against the same sequence.
+		shrinkRequest := ShrinkCallSequenceRequest{
+			VerifierFunction: func(worker *FuzzerWorker, callSequence calls.CallSequence) (bool, error) {
+				return false, nil
+			},
+		}
+
+		for _, sharedBlocks := range []bool{false, true} {
+			// Generate a call sequence where every call is included in its own block, or where calls share blocks.
+			callSequence := make(calls.CallSequence, callSequenceLength)
+			for i := 0; i < len(callSequence); i++ {
+				callSequence[i], err = worker.sequenceGenerator.generateNewElement()
+				assert.NoError(b, err)
+				callSequence[i].BlockNumberDelay = 1
+				callSequence[i].BlockTimestampDelay = 1
+				if sharedBlocks && i%callsPerSharedBlock != 0 {
+					callSequence[i].BlockNumberDelay = 0
+					callSequence[i].BlockTimestampDelay = 0
+				}
+			}
+			blockLayout := "separate_blocks"
+			if sharedBlocks {
+				blockLayout = "shared_blocks"
+			}
+
+			for _, prefixSnapshotsEnabled := range []bool{false, true} {
+				benchmarkName := blockLayout + "/reexecute"
+				if prefixSnapshotsEnabled {
+					benchmarkName = blockLayout + "/prefix_snapshots"
+				}
+				b.Run(benchmarkName, func(b *testing.B) {
+					prefixSnapshots := newCallSequencePrefixSnapshots(worker.testingBaseSnapshot)
+					callsTested := 0
+					b.ResetTimer()
+					for n := 0; n < b.N; n++ {
+						// Remove a call from our sequence, starting from the end, as the first pass of shrinking does.
+						i := len(callSequence) - 1 - (n % len(callSequence))
+						possibleShrunkSequence, err := callSequence.Clone()
+						assert.NoError(b, err)
+						possibleShrunkSequence = append(possibleShrunkSequence[:i], possibleShrunkSequence[i+1:]...)
+
+						// Test the shrunken sequence, only sharing its prefix if snapshots are enabled.
+						sharedPrefixLength := 0
+						if prefixSnapshotsEnabled {
+							sharedPrefixLength = i
+						}
+						_, err = worker.testShrunkenCallSequence(possibleShrunkSequence, shrinkRequest, prefixSnapshots, sharedPrefixLength)
+						assert.NoError(b, err)
+						callsTested += len(possibleShrunkSequence)
+					}
+					b.ReportMetric(float64(callsTested)/b.Elapsed().Seconds(), "calls/s")
+
+					// Reset our worker's chain to its testing base.
+					err := worker.chain.RevertToSnapshot(worker.testingBaseSnapshot)
+					assert.NoError(b, err)
+				})
+			}
+		}
+
+		// Release our worker and stop our fuzzer.
+		close(benchmarkDone)
+		fuzzer.Stop()
+		assert.NoError(b, <-fuzzerErr)
+	})
+}
@@ -51,7 +51,7 @@ func executeFuzzerTestMethodInternal(t *testing.T, config *config.ProjectConfig,
 }
 
 // getFuzzerTestingProjectConfig creates a default project configuration used for testing the Fuzzer.
-func getFuzzerTestingProjectConfig(t *testing.T, compilationConfig *compilation.CompilationConfig) *config.ProjectConfig {
+func getFuzzerTestingProjectConfig(t testing.TB, compilationConfig *compilation.CompilationConfig) *config.ProjectConfig {
 	projectConfig, err := config.GetDefaultProjectConfig("")
 	assert.NoError(t, err)
 	projectConfig.Compilation = compilationConfig
@@ -12,6 +12,7 @@ import (
 	"github.com/crytic/medusa/fuzzing/valuegeneration"
 	"github.com/crytic/medusa/utils"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
 	"golang.org/x/exp/maps"
 )
 
@@ -23,18 +24,26 @@ type FuzzerWorker struct {
 	// fuzzer describes the Fuzzer instance which this worker belongs to.
 	fuzzer *Fuzzer
 
-	// chain describes a test chain created by the FuzzerWorker to deploy contracts and run tests against.
+	// chain describes a test chain created by the FuzzerWorker to deploy contracts and run tests against. In
+	// multi-chain mode, this is the primary chain, and any other chains the worker manages are linked to it.
 	chain *chain.TestChain
 	// coverageTracer describes the tracer used to collect coverage maps during fuzzing campaigns.
 	coverageTracer *coverage.CoverageTracer
 
-	// testingBaseBlockNumber refers to the block number at which all contracts for testing have been deployed, prior
-	// to any fuzzing activity. This block number is reverted to after testing each call sequence to reset state.
-	testingBaseBlockNumber uint64
+	// testingBaseSnapshot refers to a snapshot of the chain once all contracts for testing have been deployed, prior
+	// to any fuzzing activity. This snapshot is reverted to after testing each call sequence to reset state.
+	testingBaseSnapshot *chain.TestChainSnapshot
 
 	// deployedContracts describes a mapping of deployed contractDefinitions and the addresses they were deployed to.
 	deployedContracts map[common.Address]*fuzzerTypes.Contract
 
+	// deployedContractChains describes a mapping of addresses of deployed contracts to the chains they were deployed
+	// to.
+	deployedContractChains map[common.Address]*chain.TestChain
+
+	// relayer delivers messages emitted on one of the worker's chains to another in multi-chain mode.
+	relayer *crossChainRelayer
+
 	// stateChangingMethods is a list of contract functions which are suspected of changing contract state
 	// (non-read-only). A sequence of calls is generated by the FuzzerWorker, targeting stateChangingMethods
 	// before executing tests.
@@ -66,7 +75,7 @@ type FuzzerWorker struct {
 // Returns the new FuzzerWorker
 func newFuzzerWorker(fuzzer *Fuzzer, workerIndex int, randomProvider *rand.Rand) (*FuzzerWorker, error) {
 	// Clone the fuzzer's base value set, so we can build on it with runtime values.
-	valueSet := fuzzer.baseValueSet.Clone()
+	valueSet := fuzzer.cloneBaseValueSet()
 
 	// Create a config for our call sequence generator for this new worker.
 	callSequenceGenConfig, err := fuzzer.Hooks.NewCallSequenceGeneratorConfigFunc(fuzzer, valueSet, randomProvider)
@@ -82,14 +91,15 @@ func newFuzzerWorker(fuzzer *Fuzzer, workerIndex int, randomProvider *rand.Rand)
 
 	// Create a new worker with the data provided.
 	worker := &FuzzerWorker{
-		workerIndex:          workerIndex,
-		fuzzer:               fuzzer,
-		deployedContracts:    make(map[common.Address]*fuzzerTypes.Contract),
-		stateChangingMethods: make([]fuzzerTypes.DeployedContractMethod, 0),
-		pureMethods:          make([]fuzzerTypes.DeployedContractMethod, 0),
-		coverageTracer:       nil,
-		randomProvider:       randomProvider,
-		valueSet:             valueSet,
+		workerIndex:            workerIndex,
+		fuzzer:                 fuzzer,
+		deployedContracts:      make(map[common.Address]*fuzzerTypes.Contract),
+		deployedContractChains: make(map[common.Address]*chain.TestChain),
+		stateChangingMethods:   make([]fuzzerTypes.DeployedContractMethod, 0),
+		pureMethods:            make([]fuzzerTypes.DeployedContractMethod, 0),
+		coverageTracer:         nil,
+		randomProvider:         randomProvider,
+		valueSet:               valueSet,
 	}
 	worker.sequenceGenerator = NewCallSequenceGenerator(worker, callSequenceGenConfig)
 	worker.shrinkingValueMutator = shrinkingValueMutator
@@ -132,6 +142,15 @@ func (fw *FuzzerWorker) DeployedContract(address common.Address) *fuzzerTypes.Co
 	return nil
 }
 
+// ContractChain obtains the chain the contract at the given address was deployed to. If the address does not refer to
+// a deployed contract tracked by the worker, the worker's primary chain is returned.
+func (fw *FuzzerWorker) ContractChain(address common.Address) *chain.TestChain {
+	if contractChain, ok := fw.deployedContractChains[address]; ok {
+		return contractChain
+	}
+	return fw.chain
+}
+
 // ValueSet obtains the value set used to power the value generator for this worker.
 func (fw *FuzzerWorker) ValueSet() *valuegeneration.ValueSet {
 	return fw.valueSet
@@ -180,6 +199,7 @@ func (fw *FuzzerWorker) onChainContractDeploymentAddedEvent(event chain.Contract
 
 	// Set our deployed contract address in our deployed contract lookup, so we can reference it later.
 	fw.deployedContracts[event.Contract.Address] = matchedDefinition
+	fw.deployedContractChains[event.Contract.Address] = event.Chain
 
 	// Update our methods
 	fw.updateMethods()
@@ -211,6 +231,7 @@ func (fw *FuzzerWorker) onChainContractDeploymentRemovedEvent(event chain.Contra
 
 	// Remove the contract from our deployed contracts mapping the worker maintains.
 	delete(fw.deployedContracts, event.Contract.Address)
+	delete(fw.deployedContractChains, event.Contract.Address)
 
 	// Update our methods
 	fw.updateMethods()
@@ -227,6 +248,21 @@ func (fw *FuzzerWorker) onChainContractDeploymentRemovedEvent(event chain.Contra
 	return nil
 }
 
+// onChainPendingBlockAddedTxEvent is the event callback used when a transaction is added to a pending block on one of
+// the worker's chains. It adds any values the transaction added to the value dictionary with cheat codes to the
+// worker's value set, and to the fuzzer's base value set, so they survive worker resets.
+func (fw *FuzzerWorker) onChainPendingBlockAddedTxEvent(event chain.PendingBlockAddedTxEvent) error {
+	values := chain.GetCheatCodeDictionaryValues(event.Block.MessageResults[event.TransactionIndex-1])
+	if len(values) == 0 {
+		return nil
+	}
+	for _, value := range values {
+		addValueToValueSet(fw.valueSet, value)
+	}
+	fw.fuzzer.addDictionaryValues(values)
+	return nil
+}
+
 // updateMethods updates the list of methods used by the worker by re-evaluating them
 // from the deployedContracts lookup.
 func (fw *FuzzerWorker) updateMethods() {
@@ -261,7 +297,7 @@ func (fw *FuzzerWorker) testNextCallSequence() (calls.CallSequence, []ShrinkCall
 	var err error
 	defer func() {
 		if err == nil {
-			err = fw.chain.RevertToBlockNumber(fw.testingBaseBlockNumber)
+			err = fw.chain.RevertToSnapshot(fw.testingBaseSnapshot)
 		}
 	}()
 
@@ -291,6 +327,15 @@ func (fw *FuzzerWorker) testNextCallSequence() (calls.CallSequence, []ShrinkCall
 			return true, err
 		}
 
+		// Record any milestones our last call reached.
+		err = fw.fuzzer.recordMilestones(currentlyExecutedSequence)
+		if err != nil {
+			return true, err
+		}
+
+		// Record any gas snapshots our last call took.
+		fw.fuzzer.recordGasSnapshots(currentlyExecutedSequence)
+
 		// Loop through each test function, signal our worker tested a call, and collect any requests to shrink
 		// this call sequence.
 		for _, callSequenceTestFunc := range fw.fuzzer.Hooks.CallSequenceTestFuncs {
@@ -305,6 +350,13 @@ func (fw *FuzzerWorker) testNextCallSequence() (calls.CallSequence, []ShrinkCall
 		fw.workerMetrics().callsTested.Add(fw.workerMetrics().callsTested, big.NewInt(1))
 		lastCallSequenceElement := currentlyExecutedSequence[len(currentlyExecutedSequence)-1]
 		fw.workerMetrics().gasUsed.Add(fw.workerMetrics().gasUsed, new(big.Int).SetUint64(lastCallSequenceElement.ChainReference.Block.MessageResults[lastCallSequenceElement.ChainReference.TransactionIndex].Receipt.GasUsed))
+		discarded := lastCallSequenceElement.Discarded()
+		if discarded {
+			fw.workerMetrics().callsDiscarded.Add(fw.workerMetrics().callsDiscarded, big.NewInt(1))
+		}
+		if method, err := lastCallSequenceElement.Method(); err == nil && method != nil {
+			fw.workerMetrics().updateMethodMetrics(lastCallSequenceElement.Contract.Name()+"."+method.Sig, discarded)
+		}
 
 		// If our fuzzer context is done, exit out immediately without results.
 		if utils.CheckContextDone(fw.fuzzer.ctx) {
@@ -340,34 +392,61 @@ func (fw *FuzzerWorker) testNextCallSequence() (calls.CallSequence, []ShrinkCall
 }
 
 // testShrunkenCallSequence tests a provided shrunken call sequence to verify it continues to satisfy the provided
-// shrink verifier. Chain state is reverted to the testing base prior to returning.
+// shrink verifier. The shrunken call sequence shares a prefix of the provided length with the sequence it was derived
+// from, so rather than executing it from the testing base, the chain is reverted to the closest valid snapshot in the
+// provided prefix snapshots and only the calls which follow it are executed. Snapshots are recorded for any new prefixes
+// executed within the shared prefix. Chain state is not reverted to the testing base prior to returning.
 // Returns a boolean indicating if the shrunken call sequence is valid for a given shrink request, or an error if one occurred.
-func (fw *FuzzerWorker) testShrunkenCallSequence(possibleShrunkSequence calls.CallSequence, shrinkRequest ShrinkCallSequenceRequest) (bool, error) {
-	// After testing the sequence, we'll want to rollback changes to reset our testing state.
-	var err error
-	defer func() {
-		if err == nil {
-			err = fw.chain.RevertToBlockNumber(fw.testingBaseBlockNumber)
-		}
-	}()
+func (fw *FuzzerWorker) testShrunkenCallSequence(possibleShrunkSequence calls.CallSequence, shrinkRequest ShrinkCallSequenceRequest, prefixSnapshots callSequencePrefixSnapshots, sharedPrefixLength int) (bool, error) {
+	// Restore the closest prefix of our sequence we have a snapshot for, so we don't need to re-execute it.
+	prefixLength := prefixSnapshots.closestPrefixLength(possibleShrunkSequence, sharedPrefixLength)
+	err := prefixSnapshots.restore(fw.chain, possibleShrunkSequence, prefixLength)
+	if err != nil {
+		return false, err
+	}
 
 	// Our "fetch next call method" method will simply fetch and fix the call message in case any fields are not correct due to shrinking.
+	relayedMessageMissing := false
 	fetchElementFunc := func(currentIndex int) (*calls.CallSequenceElement, error) {
-		// If we are at the end of our sequence, return nil indicating we should stop executing.
+		// Offset our index by the prefix we restored. If we are at the end of our sequence, return nil indicating we
+		// should stop executing.
+		currentIndex += prefixLength
 		if currentIndex >= len(possibleShrunkSequence) {
 			return nil, nil
 		}
 
-		possibleShrunkSequence[currentIndex].Call.FillFromTestChainProperties(fw.chain)
-		return possibleShrunkSequence[currentIndex], nil
+		// If this call is within our shared prefix, record a snapshot of the prefix before it.
+		if _, cached := prefixSnapshots[currentIndex]; !cached && currentIndex <= sharedPrefixLength {
+			err := prefixSnapshots.record(fw.chain, possibleShrunkSequence, currentIndex)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		// If this call delivers a relayed message which is no longer pending (e.g. the call which emitted it was
+		// removed), the shrunken sequence is invalid, so we stop executing it.
+		element := possibleShrunkSequence[currentIndex]
+		if element.Relayed && !fw.relayer.isPending(element) {
+			relayedMessageMissing = true
+			return nil, nil
+		}
+
+		// Update the element with the current nonce for the chain it is executed on.
+		elementChain, err := element.ResolveChain(fw.chain)
+		if err != nil {
+			return nil, err
+		}
+		element.Call.FillFromTestChainProperties(elementChain)
+		return element, nil
 	}
 
 	// Our "post-execution check" method will check coverage and call all testing functions. If one returns a
 	// request for a shrunk call sequence, we exit our call sequence execution immediately to go fulfill the shrink
 	// request.
 	executionCheckFunc := func(currentlyExecutedSequence calls.CallSequence) (bool, error) {
-		// Check for updates to coverage and corpus (using only the section of the sequence we tested so far).
-		// If we detect coverage changes, add this sequence.
+		// Check for updates to coverage and corpus (using only the section of the sequence we tested so far, including
+		// the prefix we restored). If we detect coverage changes, add this sequence.
+		currentlyExecutedSequence = append(possibleShrunkSequence[:prefixLength:prefixLength], currentlyExecutedSequence...)
 		seqErr := fw.fuzzer.corpus.CheckSequenceCoverageAndUpdate(currentlyExecutedSequence, fw.getNewCorpusCallSequenceWeight(), true)
 		if seqErr != nil {
 			return true, seqErr
@@ -394,7 +473,7 @@ func (fw *FuzzerWorker) testShrunkenCallSequence(possibleShrunkSequence calls.Ca
 
 	// Check if our verifier signalled that we met our conditions
 	validShrunkSequence := false
-	if len(possibleShrunkSequence) > 0 {
+	if len(possibleShrunkSequence) > 0 && !relayedMessageMissing {
 		validShrunkSequence, err = shrinkRequest.VerifierFunction(fw, possibleShrunkSequence)
 		if err != nil {
 			return false, err
@@ -423,6 +502,10 @@ func (fw *FuzzerWorker) shrinkCallSequence(callSequence calls.CallSequence, shri
 		return shrinkIteration >= shrinkLimit || utils.CheckContextDone(fw.fuzzer.ctx)
 	}
 	if shrinkLimit > 0 {
+		// Every shrunken sequence we test shares a prefix with our optimized sequence, so we cache snapshots of our
+		// chain after executing its prefixes, to avoid re-executing them for each shrunken sequence.
+		prefixSnapshots := newCallSequencePrefixSnapshots(fw.testingBaseSnapshot)
+
 		// The first pass of shrinking is greedy towards trying to remove any unnecessary calls.
 		// For each call in the sequence, the following removal strategies are used:
 		// 1) Plain removal (lower block/time gap between surrounding blocks, maintain properties of max delay)
@@ -441,7 +524,9 @@ func (fw *FuzzerWorker) shrinkCallSequence(callSequence calls.CallSequence, shri
 				}
 				possibleShrunkSequence = append(possibleShrunkSequence[:i], possibleShrunkSequence[i+1:]...)
 
-				// Exercise the next removal strategy for this call.
+				// Exercise the next removal strategy for this call, tracking the prefix it shares with our optimized
+				// sequence.
+				sharedPrefixLength := i
 				if removalStrategy == 0 {
 					// Case 1: Plain removal.
 				} else if removalStrategy == 1 {
@@ -449,11 +534,12 @@ func (fw *FuzzerWorker) shrinkCallSequence(callSequence calls.CallSequence, shri
 					if i > 0 {
 						possibleShrunkSequence[i-1].BlockNumberDelay += removedCall.BlockNumberDelay
 						possibleShrunkSequence[i-1].BlockTimestampDelay += removedCall.BlockTimestampDelay
+						sharedPrefixLength = i - 1
 					}
 				}
 
 				// Test the shrunken sequence.
-				validShrunkSequence, err := fw.testShrunkenCallSequence(possibleShrunkSequence, shrinkRequest)
+				validShrunkSequence, err := fw.testShrunkenCallSequence(possibleShrunkSequence, shrinkRequest, prefixSnapshots, sharedPrefixLength)
 				shrinkIteration++
 				if err != nil {
 					return nil, err
@@ -486,8 +572,21 @@ func (fw *FuzzerWorker) shrinkCallSequence(callSequence calls.CallSequence, shri
 				// Re-encode the message's calldata
 				possibleShrunkSequence[i].Call.WithDataAbiValues(abiValuesMsgData)
 
+				// Move the gas price of the call, and the base fee and blob base fee of the block it may create toward
+				// the defaults used when they are not fuzzed, and reset its prevrandao value to its default.
+				if possibleShrunkSequence[i].Call.GasPrice != nil && !possibleShrunkSequence[i].Relayed {
+					possibleShrunkSequence[i].Call.GasPrice = fw.shrinkIntegerTowards(possibleShrunkSequence[i].Call.GasPrice, 1, fw.fuzzer.config.Fuzzing.MinGasPrice, fw.fuzzer.config.Fuzzing.MaxGasPrice)
+				}
+				if possibleShrunkSequence[i].BlockBaseFee != nil {
+					possibleShrunkSequence[i].BlockBaseFee = fw.shrinkIntegerTowards(possibleShrunkSequence[i].BlockBaseFee, params.InitialBaseFee, fw.fuzzer.config.Fuzzing.MinBlockBaseFee, fw.fuzzer.config.Fuzzing.MaxBlockBaseFee)
+				}
+				possibleShrunkSequence[i].BlockPrevRandao = nil
+				if possibleShrunkSequence[i].BlockBlobBaseFee != nil {
+					possibleShrunkSequence[i].BlockBlobBaseFee = fw.shrinkIntegerTowards(possibleShrunkSequence[i].BlockBlobBaseFee, params.BlobTxMinBlobGasprice, fw.fuzzer.config.Fuzzing.MinBlockBlobBaseFee, fw.fuzzer.config.Fuzzing.MaxBlockBlobBaseFee)
+				}
+
 				// Test the shrunken sequence.
-				validShrunkSequence, err := fw.testShrunkenCallSequence(possibleShrunkSequence, shrinkRequest)
+				validShrunkSequence, err := fw.testShrunkenCallSequence(possibleShrunkSequence, shrinkRequest, prefixSnapshots, i)
 				shrinkIteration++
 				if err != nil {
 					return nil, err
@@ -511,7 +610,7 @@ func (fw *FuzzerWorker) shrinkCallSequence(callSequence calls.CallSequence, shri
 	}
 
 	// Reset our state before running tracing in FinishedCallback.
-	err := fw.chain.RevertToBlockNumber(fw.testingBaseBlockNumber)
+	err := fw.chain.RevertToSnapshot(fw.testingBaseSnapshot)
 	if err != nil {
 		return nil, err
 	}
@@ -525,24 +624,60 @@ func (fw *FuzzerWorker) shrinkCallSequence(callSequence calls.CallSequence, shri
 	}
 
 	// After testing the sequence, we'll want to rollback changes to reset our testing state.
-	if err = fw.chain.RevertToBlockNumber(fw.testingBaseBlockNumber); err != nil {
+	if err = fw.chain.RevertToSnapshot(fw.testingBaseSnapshot); err != nil {
 		return nil, err
 	}
 	return optimizedSequence, err
 }
 
+// shrinkIntegerTowards moves the provided integer a random distance toward the provided default value, which is first
+// clamped to the provided inclusive range. The integer is left unchanged if it already equals the clamped default.
+// Returns the shrunk integer.
+func (fw *FuzzerWorker) shrinkIntegerTowards(value *big.Int, defaultValue uint64, min uint64, max uint64) *big.Int {
+	// Clamp our default value to our range, so we never shrink outside of it.
+	target := new(big.Int).SetUint64(defaultValue)
+	if defaultValue < min {
+		target.SetUint64(min)
+	} else if defaultValue > max {
+		target.SetUint64(max)
+	}
+
+	// Select a value between the target (inclusive) and the current value (exclusive).
+	distance := new(big.Int).Sub(value, target)
+	if distance.Sign() == 0 {
+		return new(big.Int).Set(value)
+	}
+	offset := new(big.Int).Rand(fw.randomProvider, new(big.Int).Abs(distance))
+	if distance.Sign() < 0 {
+		return target.Sub(target, offset)
+	}
+	return target.Add(target, offset)
+}
+
 // run takes a base Chain in a setup state ready for testing, clones it, and begins executing fuzzed transaction calls
 // and asserting properties are upheld. This runs until Fuzzer.ctx cancels the operation.
 // Returns a boolean indicating whether Fuzzer.ctx has indicated we cancel the operation, and an error if one occurred.
 func (fw *FuzzerWorker) run(baseTestChain *chain.TestChain) (bool, error) {
-	// Clone our chain, attaching our necessary components for fuzzing post-genesis, prior to all blocks being copied.
-	// This means any tracers added or events subscribed to within this inner function are done so prior to chain
-	// setup (initial contract deployments), so data regarding that can be tracked as well.
+	// Create our relayer, and if we have coverage-guided fuzzing enabled, a tracer to collect coverage. These are
+	// shared by all of our chains.
 	var err error
+	fw.relayer, err = newCrossChainRelayer(fw)
+	if err != nil {
+		return false, err
+	}
+	if fw.fuzzer.config.Fuzzing.CoverageEnabled {
+		fw.coverageTracer = coverage.NewCoverageTracer()
+	}
+
+	// Clone our chain, attaching our necessary components for fuzzing post-genesis, prior to all blocks being copied.
+	// This means any events subscribed to within this inner function are done so prior to chain setup (initial
+	// contract deployments), so data regarding that can be tracked as well. This is invoked for each linked chain too.
 	fw.chain, err = baseTestChain.Clone(func(initializedChain *chain.TestChain) error {
 		// Subscribe our chain event handlers
 		initializedChain.Events.ContractDeploymentAddedEventEmitter.Subscribe(fw.onChainContractDeploymentAddedEvent)
 		initializedChain.Events.ContractDeploymentRemovedEventEmitter.Subscribe(fw.onChainContractDeploymentRemovedEvent)
+		initializedChain.Events.PendingBlockAddedTx.Subscribe(fw.relayer.onPendingBlockAddedTx)
+		initializedChain.Events.PendingBlockAddedTx.Subscribe(fw.onChainPendingBlockAddedTxEvent)
 
 		// Emit an event indicating the worker has created its chain.
 		err = fw.Events.FuzzerWorkerChainCreated.Publish(FuzzerWorkerChainCreatedEvent{
@@ -553,9 +688,8 @@ func (fw *FuzzerWorker) run(baseTestChain *chain.TestChain) (bool, error) {
 			return fmt.Errorf("error returned by an event handler when emitting a worker chain created event: %v", err)
 		}
 
-		// If we have coverage-guided fuzzing enabled, create a tracer to collect coverage and connect it to the chain.
-		if fw.fuzzer.config.Fuzzing.CoverageEnabled {
-			fw.coverageTracer = coverage.NewCoverageTracer()
+		// If we have coverage-guided fuzzing enabled, connect our coverage tracer to the chain.
+		if fw.coverageTracer != nil {
 			initializedChain.AddTracer(fw.coverageTracer.NativeTracer(), true, false)
 		}
 		return nil
@@ -581,9 +715,12 @@ func (fw *FuzzerWorker) run(baseTestChain *chain.TestChain) (bool, error) {
 	// Increase our generation metric as we successfully generated a test node
 	fw.workerMetrics().workerStartupCount.Add(fw.workerMetrics().workerStartupCount, big.NewInt(1))
 
-	// Save the current block number as all contracts have been deployed at this point, and we'll want to revert
-	// to this state between testing.
-	fw.testingBaseBlockNumber = fw.chain.HeadBlockNumber()
+	// Take a snapshot of the current chain state as all contracts have been deployed at this point, and we'll want
+	// to revert to this state between testing.
+	fw.testingBaseSnapshot, err = fw.chain.Snapshot()
+	if err != nil {
+		return false, err
+	}
 
 	// Enter the main fuzzing loop, restricting our memory database size based on our config variable.
 	// When the limit is reached, we exit this method gracefully, which will cause the fuzzing to recreate
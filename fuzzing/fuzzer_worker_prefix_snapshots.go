@@ -0,0 +1,93 @@
+package fuzzing
+
+import (
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/calls"
+)
+
+// callSequencePrefixSnapshot describes a chain snapshot taken after executing a prefix of a call sequence.
+type callSequencePrefixSnapshot struct {
+	// snapshot describes the chain snapshot taken after the prefix was executed.
+	snapshot *chain.TestChainSnapshot
+
+	// chainReferences describes the chain references of each call sequence element in the prefix, so they can be
+	// restored for elements which are not re-executed.
+	chainReferences []*calls.CallSequenceElementChainReference
+}
+
+// callSequencePrefixSnapshots caches chain snapshots taken after executing prefixes of a call sequence, keyed by the
+// length of the prefix. It is used when shrinking a call sequence, where every shrunken sequence tested shares a
+// prefix with the sequence it was derived from, so only the calls which follow the shared prefix need to be
+// re-executed. Snapshots capture any pending blocks, so a prefix snapshot is valid for any sequence which shares the
+// prefix, whether or not the call which follows it begins a new block.
+type callSequencePrefixSnapshots map[int]*callSequencePrefixSnapshot
+
+// newCallSequencePrefixSnapshots creates a new callSequencePrefixSnapshots, caching the provided base snapshot as the
+// empty prefix.
+func newCallSequencePrefixSnapshots(baseSnapshot *chain.TestChainSnapshot) callSequencePrefixSnapshots {
+	return callSequencePrefixSnapshots{
+		0: {
+			snapshot:        baseSnapshot,
+			chainReferences: nil,
+		},
+	}
+}
+
+// closestPrefixLength obtains the length of the longest cached prefix which is valid for the provided call sequence,
+// given the length of the prefix it shares with the sequence the snapshots were taken for.
+// Returns the length of the prefix. The empty prefix is always valid.
+func (s callSequencePrefixSnapshots) closestPrefixLength(callSequence calls.CallSequence, sharedPrefixLength int) int {
+	closest := 0
+	for prefixLength := range s {
+		if prefixLength > closest && prefixLength <= sharedPrefixLength && prefixLength <= len(callSequence) {
+			closest = prefixLength
+		}
+	}
+	return closest
+}
+
+// restore reverts the chain to the snapshot of the cached prefix with the provided length, and restores the chain
+// references of the prefix elements in the provided call sequence. Any cached snapshots for longer prefixes are
+// invalidated by the revert, so they are removed.
+// Returns an error if one occurred.
+func (s callSequencePrefixSnapshots) restore(testChain *chain.TestChain, callSequence calls.CallSequence, prefixLength int) error {
+	// Revert our chain to the prefix snapshot.
+	err := testChain.RevertToSnapshot(s[prefixLength].snapshot)
+	if err != nil {
+		return err
+	}
+
+	// Remove any snapshots which refer to blocks we just removed.
+	for cachedPrefixLength := range s {
+		if cachedPrefixLength > prefixLength {
+			delete(s, cachedPrefixLength)
+		}
+	}
+
+	// Restore the chain references for our prefix.
+	for i := 0; i < prefixLength; i++ {
+		callSequence[i].ChainReference = s[prefixLength].chainReferences[i]
+	}
+	return nil
+}
+
+// record caches a snapshot of the chain as the prefix of the provided call sequence with the provided length. This must
+// only be called once every call in the prefix has been executed, and before any call which follows it.
+// Returns an error if one occurred.
+func (s callSequencePrefixSnapshots) record(testChain *chain.TestChain, callSequence calls.CallSequence, prefixLength int) error {
+	snapshot, err := testChain.Snapshot()
+	if err != nil {
+		return err
+	}
+
+	// Cache our snapshot alongside the chain references for each element in the prefix.
+	chainReferences := make([]*calls.CallSequenceElementChainReference, prefixLength)
+	for i := 0; i < prefixLength; i++ {
+		chainReferences[i] = callSequence[i].ChainReference
+	}
+	s[prefixLength] = &callSequencePrefixSnapshot{
+		snapshot:        snapshot,
+		chainReferences: chainReferences,
+	}
+	return nil
+}
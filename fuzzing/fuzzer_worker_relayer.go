@@ -0,0 +1,272 @@
+package fuzzing
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/calls"
+	"github.com/crytic/medusa/fuzzing/config"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// relayedMessage describes a message emitted on one of a FuzzerWorker's chains, which can be delivered to another.
+type relayedMessage struct {
+	// destinationChain describes the name of the chain the message should be delivered to.
+	destinationChain string
+
+	// sender describes the address of the contract which emitted the message. It is used as the sender of the call
+	// which delivers it.
+	sender common.Address
+
+	// target describes the address of the contract the message should be delivered to.
+	target common.Address
+
+	// data describes the call data to deliver to the target.
+	data []byte
+
+	// delivered indicates whether the message was delivered to its destination chain.
+	delivered bool
+}
+
+// crossChainRelay describes a configured relay, alongside the event it delivers messages for.
+type crossChainRelay struct {
+	// config describes the relay configuration.
+	config config.RelayConfig
+
+	// event describes the event emitted by the relay's emitter contract for each message to deliver.
+	event *abi.Event
+}
+
+// crossChainRelayer tracks messages emitted on a FuzzerWorker's chains by configured relay events, so they can be
+// delivered to their destination chain by the worker's call sequence generator. Messages are tracked as transactions
+// are added to the worker's chains, and are restored by hooks when those transactions are reverted. Messages emitted
+// during chain setup are tracked as the worker clones its chains, as cloning emits transaction events for each setup
+// transaction it copies.
+type crossChainRelayer struct {
+	// worker describes the FuzzerWorker which owns the chains messages are relayed between.
+	worker *FuzzerWorker
+
+	// relays describes the configured relays to track messages for.
+	relays []crossChainRelay
+
+	// messages describes all messages emitted on the worker's chains which have not been reverted, in the order they
+	// were emitted.
+	messages []*relayedMessage
+}
+
+// newCrossChainRelayer creates a crossChainRelayer for the provided FuzzerWorker, resolving the event of each relay
+// configured for multi-chain fuzzing from the fuzzer's contract definitions.
+// Returns the relayer, or an error if a relay could not be resolved.
+func newCrossChainRelayer(worker *FuzzerWorker) (*crossChainRelayer, error) {
+	relayer := &crossChainRelayer{
+		worker:   worker,
+		relays:   make([]crossChainRelay, 0),
+		messages: make([]*relayedMessage, 0),
+	}
+
+	// If multi-chain fuzzing is not enabled, there is nothing to relay.
+	multiChainConfig := worker.fuzzer.config.Fuzzing.MultiChain
+	if !multiChainConfig.Enabled {
+		return relayer, nil
+	}
+
+	for _, relayConfig := range multiChainConfig.Relays {
+		// Resolve the event from the emitter contract's definition.
+		var event *abi.Event
+		for _, contractDefinition := range worker.fuzzer.contractDefinitions {
+			if contractDefinition.Name() == relayConfig.EmitterContract {
+				if contractEvent, ok := contractDefinition.CompiledContract().Abi.Events[relayConfig.Event]; ok {
+					event = &contractEvent
+				}
+				break
+			}
+		}
+		if event == nil {
+			return nil, fmt.Errorf("could not resolve relay event '%s' in contract '%s'", relayConfig.Event, relayConfig.EmitterContract)
+		}
+
+		// Verify the event describes a target address and call data to deliver.
+		if event.Anonymous {
+			return nil, fmt.Errorf("relay event '%s' in contract '%s' cannot be anonymous", relayConfig.Event, relayConfig.EmitterContract)
+		}
+		if relayEventTargetIndex(event) < 0 || relayEventDataIndex(event) < 0 {
+			return nil, fmt.Errorf("relay event '%s' in contract '%s' must have an address parameter and a non-indexed bytes parameter", relayConfig.Event, relayConfig.EmitterContract)
+		}
+
+		relayer.relays = append(relayer.relays, crossChainRelay{
+			config: relayConfig,
+			event:  event,
+		})
+	}
+	return relayer, nil
+}
+
+// relayEventTargetIndex obtains the index of the input of a relay event which describes the target of a message, which
+// is its first address parameter.
+// Returns the index of the input, or -1 if none exists.
+func relayEventTargetIndex(event *abi.Event) int {
+	for i, input := range event.Inputs {
+		if input.Type.T == abi.AddressTy {
+			return i
+		}
+	}
+	return -1
+}
+
+// relayEventDataIndex obtains the index of the input of a relay event which describes the call data of a message, which
+// is its first non-indexed bytes parameter.
+// Returns the index of the input, or -1 if none exists.
+func relayEventDataIndex(event *abi.Event) int {
+	for i, input := range event.Inputs {
+		if input.Type.T == abi.BytesTy && !input.Indexed {
+			return i
+		}
+	}
+	return -1
+}
+
+// onPendingBlockAddedTx is the event handler triggered when a transaction is added to a pending block on one of the
+// FuzzerWorker's chains. It marks any message the transaction delivered as such, and tracks any messages emitted by
+// the transaction.
+// Returns an error if one occurs.
+func (r *crossChainRelayer) onPendingBlockAddedTx(event chain.PendingBlockAddedTxEvent) error {
+	// If there are no relays, there is nothing to track.
+	if len(r.relays) == 0 {
+		return nil
+	}
+
+	// Obtain the transaction which was added, ignoring it if it failed.
+	messageIndex := event.TransactionIndex - 1
+	message := event.Block.Messages[messageIndex]
+	messageResult := event.Block.MessageResults[messageIndex]
+	if messageResult.Receipt.Status != types.ReceiptStatusSuccessful {
+		return nil
+	}
+
+	// If the transaction delivered a pending message, mark it as delivered.
+	chainName := event.Chain.Name()
+	if message.To != nil {
+		for _, pendingMessage := range r.messages {
+			if !pendingMessage.delivered && pendingMessage.destinationChain == chainName && pendingMessage.sender == message.From &&
+				pendingMessage.target == *message.To && bytes.Equal(pendingMessage.data, message.Data) {
+				pendingMessage.delivered = true
+				messageResult.OnRevertHookFuncs.Push(func() {
+					pendingMessage.delivered = false
+				})
+				break
+			}
+		}
+	}
+
+	// Track any messages emitted by the transaction on this chain.
+	for _, log := range messageResult.Receipt.Logs {
+		for _, relay := range r.relays {
+			if relay.config.SourceChain != chainName || len(log.Topics) == 0 || log.Topics[0] != relay.event.ID {
+				continue
+			}
+			if emitter, ok := r.worker.deployedContracts[log.Address]; !ok || emitter.Name() != relay.config.EmitterContract {
+				continue
+			}
+
+			// Decode the message from the event, ignoring it if it is malformed.
+			emittedMessage, err := decodeRelayedMessage(relay, log)
+			if err != nil {
+				continue
+			}
+			r.messages = append(r.messages, emittedMessage)
+			messageResult.OnRevertHookFuncs.Push(func() {
+				r.removeMessage(emittedMessage)
+			})
+		}
+	}
+	return nil
+}
+
+// decodeRelayedMessage decodes a message emitted by the provided relay in the provided log.
+// Returns the message, or an error if the log could not be decoded.
+func decodeRelayedMessage(relay crossChainRelay, log *types.Log) (*relayedMessage, error) {
+	// Unpack the non-indexed values of the event.
+	values, err := relay.event.Inputs.Unpack(log.Data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Obtain our target and call data from the event values, which are split across topics and data depending on
+	// whether they are indexed.
+	var target common.Address
+	var data []byte
+	targetIndex, dataIndex := relayEventTargetIndex(relay.event), relayEventDataIndex(relay.event)
+	topicIndex, valueIndex := 1, 0
+	for i, input := range relay.event.Inputs {
+		if input.Indexed {
+			if i == targetIndex {
+				if topicIndex >= len(log.Topics) {
+					return nil, fmt.Errorf("relay event is missing indexed topics")
+				}
+				target = common.BytesToAddress(log.Topics[topicIndex].Bytes())
+			}
+			topicIndex++
+			continue
+		}
+		if valueIndex >= len(values) {
+			return nil, fmt.Errorf("relay event is missing non-indexed values")
+		}
+		if i == targetIndex {
+			target = values[valueIndex].(common.Address)
+		} else if i == dataIndex {
+			data = values[valueIndex].([]byte)
+		}
+		valueIndex++
+	}
+
+	return &relayedMessage{
+		destinationChain: relay.config.DestinationChain,
+		sender:           log.Address,
+		target:           target,
+		data:             data,
+	}, nil
+}
+
+// removeMessage removes the provided message from the messages tracked by the relayer.
+func (r *crossChainRelayer) removeMessage(message *relayedMessage) {
+	for i, trackedMessage := range r.messages {
+		if trackedMessage == message {
+			r.messages = append(r.messages[:i], r.messages[i+1:]...)
+			return
+		}
+	}
+}
+
+// pendingMessages obtains the messages which were emitted and not yet delivered to their destination chain.
+// Returns the pending messages.
+func (r *crossChainRelayer) pendingMessages() []*relayedMessage {
+	pendingMessages := make([]*relayedMessage, 0)
+	for _, message := range r.messages {
+		if !message.delivered {
+			pendingMessages = append(pendingMessages, message)
+		}
+	}
+	return pendingMessages
+}
+
+// isPending indicates whether the provided call sequence element delivers a message which is pending.
+// Returns a boolean indicating whether a matching message is pending.
+func (r *crossChainRelayer) isPending(element *calls.CallSequenceElement) bool {
+	if element.Call == nil || element.Call.To == nil {
+		return false
+	}
+	elementChain := element.Chain
+	if elementChain == "" {
+		elementChain = chain.PrimaryChainName
+	}
+	for _, message := range r.pendingMessages() {
+		if message.destinationChain == elementChain && message.sender == element.Call.From &&
+			message.target == *element.Call.To && bytes.Equal(message.data, element.Call.Data) {
+			return true
+		}
+	}
+	return false
+}
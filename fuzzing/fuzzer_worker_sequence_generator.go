@@ -4,11 +4,13 @@ import (
 	"fmt"
 	"math/big"
 
+	"github.com/crytic/medusa/chain"
 	"github.com/crytic/medusa/fuzzing/calls"
 	"github.com/crytic/medusa/fuzzing/contracts"
 	"github.com/crytic/medusa/fuzzing/valuegeneration"
 	"github.com/crytic/medusa/utils"
 	"github.com/crytic/medusa/utils/randomutils"
+	"github.com/ethereum/go-ethereum/common"
 )
 
 // CallSequenceGenerator generates call sequences iteratively per element, for use in fuzzing campaigns. It is attached
@@ -260,10 +262,23 @@ func (g *CallSequenceGenerator) PopSequenceElement() (*calls.CallSequenceElement
 				return nil, err
 			}
 		}
+
+		// If the element delivers a relayed message which is not pending in this sequence, we replace it with a
+		// newly generated call.
+		if element.Relayed && !g.worker.relayer.isPending(element) {
+			element, err = g.generateNewElement()
+			if err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	// Update the element with the current nonce for the associated chain.
-	element.Call.FillFromTestChainProperties(g.worker.chain)
+	elementChain, err := element.ResolveChain(g.worker.chain)
+	if err != nil {
+		return nil, err
+	}
+	element.Call.FillFromTestChainProperties(elementChain)
 
 	// Update our base sequence, advance our position, and return the processed element from this round.
 	g.baseSequence[g.fetchIndex] = element
@@ -272,9 +287,16 @@ func (g *CallSequenceGenerator) PopSequenceElement() (*calls.CallSequenceElement
 }
 
 // generateNewElement generates a new call sequence element which targets a method in a contract
-// deployed to the CallSequenceGenerator's parent FuzzerWorker chain, with fuzzed call data.
+// deployed to the CallSequenceGenerator's parent FuzzerWorker chain, with fuzzed call data. In multi-chain mode, the
+// element may instead deliver a message which is pending between the worker's chains.
 // Returns the call sequence element, or an error if one was encountered.
 func (g *CallSequenceGenerator) generateNewElement() (*calls.CallSequenceElement, error) {
+	// If there are messages pending delivery between chains, there is a 1/2 chance we deliver one of them instead.
+	pendingMessages := g.worker.relayer.pendingMessages()
+	if len(pendingMessages) > 0 && g.worker.randomProvider.Intn(2) == 0 {
+		return g.generateRelayedElement(pendingMessages[g.worker.randomProvider.Intn(len(pendingMessages))]), nil
+	}
+
 	// Check to make sure that we have any functions to call
 	if len(g.worker.stateChangingMethods) == 0 && len(g.worker.pureMethods) == 0 {
 		return nil, fmt.Errorf("cannot generate fuzzed call as there are no methods to call")
@@ -313,10 +335,15 @@ func (g *CallSequenceGenerator) generateNewElement() (*calls.CallSequenceElement
 		value = g.config.ValueGenerator.GenerateInteger(false, 64)
 	}
 
+	// If gas price fuzzing is enabled, generate a gas price within our configured range. Otherwise, the default is used.
+	var gasPrice *big.Int
+	if g.worker.fuzzer.config.Fuzzing.FuzzGasPrice {
+		gasPrice = g.generateIntegerInRange(g.worker.fuzzer.config.Fuzzing.MinGasPrice, g.worker.fuzzer.config.Fuzzing.MaxGasPrice)
+	}
+
 	// Create our message using the provided parameters.
 	// We fill out some fields and populate the rest from our TestChain properties.
-	// TODO: We likely want to make gasPrice fluctuate within some sensible range here.
-	msg := calls.NewCallMessageWithAbiValueData(selectedSender, &selectedMethod.Address, 0, value, g.worker.fuzzer.config.Fuzzing.TransactionGasLimit, nil, nil, nil, &calls.CallMessageDataAbiValues{
+	msg := calls.NewCallMessageWithAbiValueData(selectedSender, &selectedMethod.Address, 0, value, g.worker.fuzzer.config.Fuzzing.TransactionGasLimit, gasPrice, nil, nil, &calls.CallMessageDataAbiValues{
 		Method:      &selectedMethod.Method,
 		InputValues: args,
 	})
@@ -325,7 +352,69 @@ func (g *CallSequenceGenerator) generateNewElement() (*calls.CallSequenceElement
 		msg.SkipAccountChecks = true
 	}
 
+	// If the chain supports blobs (EIP-4844), attach a random number of blob versioned hashes to our message.
+	methodChain := g.worker.ContractChain(selectedMethod.Address)
+	head := methodChain.Head().Header
+	if g.worker.fuzzer.config.Fuzzing.MaxBlobHashes > 0 && methodChain.ChainConfig().IsCancun(head.Number, head.Time) {
+		blobHashCount := g.config.ValueGenerator.GenerateInteger(false, 64).Uint64() % (g.worker.fuzzer.config.Fuzzing.MaxBlobHashes + 1)
+		for i := uint64(0); i < blobHashCount; i++ {
+			msg.BlobHashes = append(msg.BlobHashes, toBlobHash(g.config.ValueGenerator.GenerateFixedBytes(common.HashLength)))
+		}
+	}
+
 	// Determine our delay values for this element
+	blockNumberDelay, blockTimestampDelay := g.generateBlockDelays()
+
+	// Create our call sequence element, along with the base fee, prevrandao, and blob base fee values to use if it
+	// creates a new block. If the method's contract was deployed to a linked chain, the call is executed there.
+	element := calls.NewCallSequenceElement(selectedMethod.Contract, msg, blockNumberDelay, blockTimestampDelay)
+	if methodChain != g.worker.chain {
+		element.Chain = methodChain.Name()
+	}
+	if g.worker.fuzzer.config.Fuzzing.FuzzBlockBaseFee {
+		element.BlockBaseFee = g.generateIntegerInRange(g.worker.fuzzer.config.Fuzzing.MinBlockBaseFee, g.worker.fuzzer.config.Fuzzing.MaxBlockBaseFee)
+	}
+	if g.worker.fuzzer.config.Fuzzing.FuzzBlockPrevRandao {
+		blockPrevRandao := common.BytesToHash(g.config.ValueGenerator.GenerateFixedBytes(common.HashLength))
+		element.BlockPrevRandao = &blockPrevRandao
+	}
+	if g.worker.fuzzer.config.Fuzzing.FuzzBlockBlobBaseFee {
+		element.BlockBlobBaseFee = g.generateIntegerInRange(g.worker.fuzzer.config.Fuzzing.MinBlockBlobBaseFee, g.worker.fuzzer.config.Fuzzing.MaxBlockBlobBaseFee)
+	}
+
+	// Return our call sequence element.
+	return element, nil
+}
+
+// generateRelayedElement generates a new call sequence element which delivers the provided message to its
+// destination chain. The message is delivered by a call from the contract which emitted it, with no value or gas fees.
+// Returns the call sequence element.
+func (g *CallSequenceGenerator) generateRelayedElement(message *relayedMessage) *calls.CallSequenceElement {
+	// Create our message, skipping account checks as it is sent from a contract rather than an externally owned
+	// account.
+	target := message.target
+	msg := calls.NewCallMessage(message.sender, &target, 0, big.NewInt(0), g.worker.fuzzer.config.Fuzzing.TransactionGasLimit, big.NewInt(0), nil, nil, message.data)
+	msg.SkipAccountChecks = true
+
+	// Create our call sequence element on the destination chain.
+	blockNumberDelay, blockTimestampDelay := g.generateBlockDelays()
+	element := calls.NewCallSequenceElement(g.worker.deployedContracts[target], msg, blockNumberDelay, blockTimestampDelay)
+	if message.destinationChain != chain.PrimaryChainName {
+		element.Chain = message.destinationChain
+	}
+	element.Relayed = true
+	if g.worker.fuzzer.config.Fuzzing.FuzzBlockBaseFee {
+		element.BlockBaseFee = g.generateIntegerInRange(g.worker.fuzzer.config.Fuzzing.MinBlockBaseFee, g.worker.fuzzer.config.Fuzzing.MaxBlockBaseFee)
+	}
+	if g.worker.fuzzer.config.Fuzzing.FuzzBlockBlobBaseFee {
+		element.BlockBlobBaseFee = g.generateIntegerInRange(g.worker.fuzzer.config.Fuzzing.MinBlockBlobBaseFee, g.worker.fuzzer.config.Fuzzing.MaxBlockBlobBaseFee)
+	}
+	return element
+}
+
+// generateBlockDelays generates the block number and timestamp delays to use for a new call sequence element.
+// Returns the block number delay and block timestamp delay.
+func (g *CallSequenceGenerator) generateBlockDelays() (uint64, uint64) {
 	blockNumberDelay := uint64(0)
 	blockTimestampDelay := uint64(0)
 	if g.worker.fuzzer.config.Fuzzing.MaxBlockNumberDelay > 0 {
@@ -344,9 +433,43 @@ func (g *CallSequenceGenerator) generateNewElement() (*calls.CallSequenceElement
 			blockNumberDelay %= blockTimestampDelay
 		}
 	}
+	return blockNumberDelay, blockTimestampDelay
+}
 
-	// Return our call sequence element.
-	return calls.NewCallSequenceElement(selectedMethod.Contract, msg, blockNumberDelay, blockTimestampDelay), nil
+// generateIntegerInRange generates an integer within the provided inclusive range using the CallSequenceGenerator's
+// value generator.
+// Returns the generated integer.
+func (g *CallSequenceGenerator) generateIntegerInRange(min uint64, max uint64) *big.Int {
+	rangeSize := new(big.Int).SetUint64(max - min)
+	rangeSize.Add(rangeSize, big.NewInt(1))
+	value := new(big.Int).Mod(g.config.ValueGenerator.GenerateInteger(false, 64), rangeSize)
+	return value.Add(value, new(big.Int).SetUint64(min))
+}
+
+// mutateIntegerInRange mutates the provided integer using the provided value mutator. If the mutated integer falls
+// outside the provided inclusive range, it is clamped to it.
+// Returns the mutated integer.
+func mutateIntegerInRange(mutator valuegeneration.ValueMutator, value *big.Int, min uint64, max uint64) *big.Int {
+	mutatedValue := mutator.MutateInteger(new(big.Int).Set(value), false, 64)
+	if mutatedValue.Cmp(new(big.Int).SetUint64(min)) < 0 {
+		return new(big.Int).SetUint64(min)
+	} else if mutatedValue.Cmp(new(big.Int).SetUint64(max)) > 0 {
+		return new(big.Int).SetUint64(max)
+	}
+	return mutatedValue
+}
+
+// blobCommitmentVersionKZG describes the version byte of blob versioned hashes for KZG commitments, as defined by
+// EIP-4844.
+const blobCommitmentVersionKZG uint8 = 0x01
+
+// toBlobHash converts the provided bytes to a blob versioned hash, setting its version byte to the one defined by
+// EIP-4844 so it is considered valid by the EVM.
+// Returns the blob versioned hash.
+func toBlobHash(b []byte) common.Hash {
+	blobHash := common.BytesToHash(b)
+	blobHash[0] = blobCommitmentVersionKZG
+	return blobHash
 }
 
 // callSeqGenFuncCorpusHead is a CallSequenceGeneratorFunc which prepares a CallSequenceGenerator to generate a sequence
@@ -462,8 +585,34 @@ func callSeqGenFuncInterleaveAtRandom(sequenceGenerator *CallSequenceGenerator,
 // to a call sequence element, prior to it being fetched.
 // Returns an error if one occurs.
 func prefetchModifyCallFuncMutate(sequenceGenerator *CallSequenceGenerator, element *calls.CallSequenceElement) error {
+	// If this element has no call, exit early.
+	if element.Call == nil {
+		return nil
+	}
+
+	// Mutate the gas price and blob hashes of the call, as well as the base fee, prevrandao, and blob base fee values
+	// to use if it creates a new block.
+	// Relayed messages are delivered without gas fees, so their gas price is left unchanged.
+	fuzzingConfig := &sequenceGenerator.worker.fuzzer.config.Fuzzing
+	if fuzzingConfig.FuzzGasPrice && element.Call.GasPrice != nil && !element.Relayed {
+		element.Call.GasPrice = mutateIntegerInRange(sequenceGenerator.config.ValueMutator, element.Call.GasPrice, fuzzingConfig.MinGasPrice, fuzzingConfig.MaxGasPrice)
+	}
+	if fuzzingConfig.FuzzBlockBaseFee && element.BlockBaseFee != nil {
+		element.BlockBaseFee = mutateIntegerInRange(sequenceGenerator.config.ValueMutator, element.BlockBaseFee, fuzzingConfig.MinBlockBaseFee, fuzzingConfig.MaxBlockBaseFee)
+	}
+	if fuzzingConfig.FuzzBlockPrevRandao && element.BlockPrevRandao != nil {
+		blockPrevRandao := common.BytesToHash(sequenceGenerator.config.ValueMutator.MutateFixedBytes(element.BlockPrevRandao.Bytes()))
+		element.BlockPrevRandao = &blockPrevRandao
+	}
+	for i, blobHash := range element.Call.BlobHashes {
+		element.Call.BlobHashes[i] = toBlobHash(sequenceGenerator.config.ValueMutator.MutateFixedBytes(blobHash.Bytes()))
+	}
+	if fuzzingConfig.FuzzBlockBlobBaseFee && element.BlockBlobBaseFee != nil {
+		element.BlockBlobBaseFee = mutateIntegerInRange(sequenceGenerator.config.ValueMutator, element.BlockBlobBaseFee, fuzzingConfig.MinBlockBlobBaseFee, fuzzingConfig.MaxBlockBlobBaseFee)
+	}
+
 	// If this element has no ABI value based call data, exit early.
-	if element.Call == nil || element.Call.DataAbiValues == nil {
+	if element.Call.DataAbiValues == nil {
 		return nil
 	}
 
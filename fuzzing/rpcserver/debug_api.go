@@ -0,0 +1,135 @@
+package rpcserver
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/crytic/medusa/fuzzing/executiontracer"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// debugAPI provides the methods served by a Server under the "debug" namespace.
+type debugAPI struct {
+	// server describes the Server the methods are served by.
+	server *Server
+}
+
+// callFrameTrace describes a call frame of an execution trace, as returned by debug_traceTransaction. It follows the
+// layout of the call traces produced by the "callTracer" of other Ethereum clients, adding the contract and method
+// resolved for the call frame.
+type callFrameTrace struct {
+	// Type describes the type of the call frame: CREATE, DELEGATECALL, or CALL.
+	Type string `json:"type"`
+
+	// From describes the address which made the call.
+	From common.Address `json:"from"`
+
+	// To describes the address which was called, or the address of the contract which was created.
+	To common.Address `json:"to"`
+
+	// CodeAddress describes the address of the code executed, if it differs from To (e.g. under a DELEGATECALL).
+	CodeAddress *common.Address `json:"codeAddress,omitempty"`
+
+	// Contract describes the name of the contract resolved for the code executed, if it was resolved.
+	Contract string `json:"contract,omitempty"`
+
+	// Method describes the signature of the method resolved for the call, if it was resolved.
+	Method string `json:"method,omitempty"`
+
+	// Value describes the value sent with the call.
+	Value *hexutil.Big `json:"value"`
+
+	// Input describes the data the call was made with.
+	Input hexutil.Bytes `json:"input"`
+
+	// Output describes the data the call returned or reverted with.
+	Output hexutil.Bytes `json:"output"`
+
+	// Error describes the error the call failed with, if it failed.
+	Error string `json:"error,omitempty"`
+
+	// RevertReason describes the reason the call reverted with, if it reverted with an Error(string).
+	RevertReason string `json:"revertReason,omitempty"`
+
+	// Logs describes the events emitted directly by the call, in the order they were emitted.
+	Logs []*types.Log `json:"logs,omitempty"`
+
+	// Calls describes the calls made directly by the call, in the order they were made.
+	Calls []*callFrameTrace `json:"calls,omitempty"`
+}
+
+// newCallFrameTrace creates a callFrameTrace from the provided execution trace call frame and its children.
+func newCallFrameTrace(callFrame *executiontracer.CallFrame) *callFrameTrace {
+	value := callFrame.CallValue
+	if value == nil {
+		value = big.NewInt(0)
+	}
+	trace := &callFrameTrace{
+		Type:   "CALL",
+		From:   callFrame.SenderAddress,
+		To:     callFrame.ToAddress,
+		Value:  (*hexutil.Big)(value),
+		Input:  callFrame.InputData,
+		Output: callFrame.ReturnData,
+	}
+	if callFrame.IsContractCreation() {
+		trace.Type = "CREATE"
+	} else if callFrame.IsProxyCall() {
+		trace.Type = "DELEGATECALL"
+		codeAddress := callFrame.CodeAddress
+		trace.CodeAddress = &codeAddress
+	}
+
+	// Resolve our contract and method names.
+	if callFrame.CodeContractAbi != nil {
+		trace.Contract = callFrame.CodeContractName
+		if callFrame.IsContractCreation() {
+			trace.Method = "constructor"
+		} else if method, err := callFrame.CodeContractAbi.MethodById(callFrame.InputData); err == nil {
+			trace.Method = method.Sig
+		}
+	}
+
+	// Record our error, and the reason we reverted with if we did.
+	if callFrame.ReturnError != nil {
+		trace.Error = callFrame.ReturnError.Error()
+		if reason, err := abi.UnpackRevert(callFrame.ReturnData); err == nil {
+			trace.RevertReason = reason
+		}
+	}
+
+	// Record our logs and child call frames.
+	for _, operation := range callFrame.Operations {
+		switch operation := operation.(type) {
+		case *types.Log:
+			trace.Logs = append(trace.Logs, operation)
+		case *executiontracer.CallFrame:
+			trace.Calls = append(trace.Calls, newCallFrameTrace(operation))
+		}
+	}
+	return trace
+}
+
+// TraceTransaction returns the execution trace recorded for the committed transaction with the provided hash, as a
+// tree of call frames.
+// Returns the top level call frame of the execution trace, or an error if one occurred.
+func (api *debugAPI) TraceTransaction(txHash common.Hash) (*callFrameTrace, error) {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+
+	// Verify the transaction is committed to the chain, as traces of reverted transactions are not removed.
+	block, _ := api.server.findTransaction(txHash)
+	if block == nil {
+		return nil, fmt.Errorf("transaction %s not found", txHash.String())
+	}
+
+	// Return the trace recorded for it.
+	trace := api.server.executionTraces[txHash]
+	if trace == nil || trace.TopLevelCallFrame == nil {
+		return nil, fmt.Errorf("no execution trace was recorded for transaction %s", txHash.String())
+	}
+	return newCallFrameTrace(trace.TopLevelCallFrame), nil
+}
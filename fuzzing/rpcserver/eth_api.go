@@ -0,0 +1,585 @@
+package rpcserver
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	chainTypes "github.com/crytic/medusa/chain/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// maxFeeHistoryBlockCount describes the maximum number of blocks eth_feeHistory reports the fee history of.
+const maxFeeHistoryBlockCount = 1024
+
+// ethAPI provides the methods served by a Server under the "eth" namespace.
+type ethAPI struct {
+	// server describes the Server the methods are served by.
+	server *Server
+}
+
+// transactionArgs describes the transaction arguments provided to methods such as eth_call and eth_sendTransaction.
+type transactionArgs struct {
+	From     *common.Address `json:"from"`
+	To       *common.Address `json:"to"`
+	Gas      *hexutil.Uint64 `json:"gas"`
+	GasPrice *hexutil.Big    `json:"gasPrice"`
+	Value    *hexutil.Big    `json:"value"`
+	Nonce    *hexutil.Uint64 `json:"nonce"`
+	Data     *hexutil.Bytes  `json:"data"`
+	Input    *hexutil.Bytes  `json:"input"`
+}
+
+// toMessage converts the transactionArgs into a message which can be executed on the provided state, filling any
+// unset fields with defaults. Account checks are skipped, so transactions can be sent from any account.
+func (args *transactionArgs) toMessage(stateDB *state.StateDB, defaultGasLimit uint64) *core.Message {
+	msg := &core.Message{
+		To:                args.To,
+		GasLimit:          defaultGasLimit,
+		GasPrice:          big.NewInt(0),
+		Value:             big.NewInt(0),
+		SkipAccountChecks: true,
+	}
+	if args.From != nil {
+		msg.From = *args.From
+	}
+	if args.Gas != nil {
+		msg.GasLimit = uint64(*args.Gas)
+	}
+	if args.GasPrice != nil {
+		msg.GasPrice = args.GasPrice.ToInt()
+	}
+	if args.Value != nil {
+		msg.Value = args.Value.ToInt()
+	}
+	if args.Nonce != nil {
+		msg.Nonce = uint64(*args.Nonce)
+	} else {
+		msg.Nonce = stateDB.GetNonce(msg.From)
+	}
+	if args.Input != nil {
+		msg.Data = *args.Input
+	} else if args.Data != nil {
+		msg.Data = *args.Data
+	}
+	msg.GasFeeCap = new(big.Int).Set(msg.GasPrice)
+	msg.GasTipCap = new(big.Int).Set(msg.GasPrice)
+	return msg
+}
+
+// revertError describes an error returned when a call reverts, which includes the data it reverted with.
+type revertError struct {
+	// reason describes the reason the call reverted.
+	reason string
+
+	// data describes the hex encoded data the call reverted with.
+	data string
+}
+
+// newRevertError creates a revertError from the provided execution result of a reverted call.
+func newRevertError(result *core.ExecutionResult) *revertError {
+	reason := "execution reverted"
+	if unpackedReason, err := abi.UnpackRevert(result.Revert()); err == nil {
+		reason = fmt.Sprintf("execution reverted: %v", unpackedReason)
+	}
+	return &revertError{
+		reason: reason,
+		data:   hexutil.Encode(result.Revert()),
+	}
+}
+
+// Error implements the error interface.
+func (e *revertError) Error() string {
+	return e.reason
+}
+
+// ErrorCode returns the JSON-RPC error code for a reverted call, as used by other Ethereum clients.
+func (e *revertError) ErrorCode() int {
+	return 3
+}
+
+// ErrorData returns the hex encoded data the call reverted with.
+func (e *revertError) ErrorData() any {
+	return e.data
+}
+
+// ChainId returns the chain ID of the chain.
+func (api *ethAPI) ChainId() *hexutil.Big {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	return (*hexutil.Big)(api.server.chain.ChainConfig().ChainID)
+}
+
+// BlockNumber returns the block number of the chain head.
+func (api *ethAPI) BlockNumber() hexutil.Uint64 {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	return hexutil.Uint64(api.server.chain.HeadBlockNumber())
+}
+
+// Accounts returns the accounts transactions are expected to be sent from.
+func (api *ethAPI) Accounts() []common.Address {
+	return api.server.accounts
+}
+
+// GasPrice returns the suggested gas price, which is always zero as the chain does not charge a base fee.
+func (api *ethAPI) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(0))
+}
+
+// MaxPriorityFeePerGas returns the suggested priority fee, which is always zero as the chain does not charge a base
+// fee.
+func (api *ethAPI) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(big.NewInt(0))
+}
+
+// feeHistoryResult describes the fee history returned by eth_feeHistory.
+type feeHistoryResult struct {
+	// OldestBlock describes the block number of the first block in the history.
+	OldestBlock *hexutil.Big `json:"oldestBlock"`
+
+	// Reward describes the priority fees paid at each of the requested percentiles, for each block in the history.
+	Reward [][]*hexutil.Big `json:"reward,omitempty"`
+
+	// BaseFee describes the base fee of each block in the history, followed by the base fee of the next block.
+	BaseFee []*hexutil.Big `json:"baseFeePerGas"`
+
+	// GasUsedRatio describes the ratio of gas used to the gas limit of each block in the history.
+	GasUsedRatio []float64 `json:"gasUsedRatio"`
+}
+
+// FeeHistory returns the fee history of up to the provided number of blocks, ending with the provided block. Priority
+// fees are reported at each of the provided percentiles of the gas used in each block.
+// Returns the fee history, or an error if one occurred.
+func (api *ethAPI) FeeHistory(blockCount hexutil.Uint64, lastBlock rpc.BlockNumber, rewardPercentiles []float64) (*feeHistoryResult, error) {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+
+	// Verify our percentiles are valid.
+	for i, percentile := range rewardPercentiles {
+		if percentile < 0 || percentile > 100 || (i > 0 && percentile < rewardPercentiles[i-1]) {
+			return nil, fmt.Errorf("invalid reward percentile %v", percentile)
+		}
+	}
+
+	// Resolve our range of blocks, which cannot extend past our head or before genesis.
+	lastBlockNumber, err := api.server.blockNumber(lastBlock)
+	if err != nil {
+		return nil, err
+	}
+	if blockCount > maxFeeHistoryBlockCount {
+		blockCount = maxFeeHistoryBlockCount
+	}
+	if uint64(blockCount) > lastBlockNumber+1 {
+		blockCount = hexutil.Uint64(lastBlockNumber + 1)
+	}
+	oldestBlockNumber := lastBlockNumber + 1 - uint64(blockCount)
+
+	// Report the fees of each block.
+	result := &feeHistoryResult{
+		OldestBlock:  (*hexutil.Big)(new(big.Int).SetUint64(oldestBlockNumber)),
+		BaseFee:      make([]*hexutil.Big, 0, blockCount+1),
+		GasUsedRatio: make([]float64, 0, blockCount),
+	}
+	if len(rewardPercentiles) > 0 {
+		result.Reward = make([][]*hexutil.Big, 0, blockCount)
+	}
+	for blockNumber := oldestBlockNumber; blockNumber <= lastBlockNumber; blockNumber++ {
+		block, err := api.server.chain.BlockFromNumber(blockNumber)
+		if err != nil {
+			return nil, err
+		}
+		result.BaseFee = append(result.BaseFee, (*hexutil.Big)(block.Header.BaseFee))
+		result.GasUsedRatio = append(result.GasUsedRatio, float64(block.Header.GasUsed)/float64(block.Header.GasLimit))
+		if len(rewardPercentiles) > 0 {
+			result.Reward = append(result.Reward, blockRewards(block, rewardPercentiles))
+		}
+	}
+
+	// The base fee does not change between blocks unless specified, so we report the next block's as the last one's.
+	if len(result.BaseFee) > 0 {
+		result.BaseFee = append(result.BaseFee, result.BaseFee[len(result.BaseFee)-1])
+	}
+	return result, nil
+}
+
+// GetBlockByNumber returns the block with the provided number, or nil if no such block exists. If fullTx is true,
+// the block's transactions are returned in full, otherwise only their hashes are returned.
+// Returns the block, or an error if one occurred.
+func (api *ethAPI) GetBlockByNumber(blockNr rpc.BlockNumber, fullTx bool) (map[string]any, error) {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	// Blocks past our head do not exist.
+	blockNumber, err := api.server.blockNumber(blockNr)
+	if err != nil {
+		return nil, nil
+	}
+	block, err := api.server.chain.BlockFromNumber(blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return marshalBlock(block, fullTx), nil
+}
+
+// GetBlockByHash returns the committed block with the provided hash, or nil if no such block exists. If fullTx is
+// true, the block's transactions are returned in full, otherwise only their hashes are returned.
+func (api *ethAPI) GetBlockByHash(blockHash common.Hash, fullTx bool) map[string]any {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	for _, block := range api.server.chain.CommittedBlocks() {
+		if block.Hash == blockHash {
+			return marshalBlock(block, fullTx)
+		}
+	}
+	return nil
+}
+
+// GetBalance returns the balance of the provided account at the provided block.
+func (api *ethAPI) GetBalance(address common.Address, blockNrOrHash *rpc.BlockNumberOrHash) (*hexutil.Big, error) {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	stateDB, err := api.server.stateAt(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(stateDB.GetBalance(address).ToBig()), nil
+}
+
+// GetCode returns the code of the provided account at the provided block.
+func (api *ethAPI) GetCode(address common.Address, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	stateDB, err := api.server.stateAt(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return stateDB.GetCode(address), nil
+}
+
+// GetTransactionCount returns the nonce of the provided account at the provided block.
+func (api *ethAPI) GetTransactionCount(address common.Address, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Uint64, error) {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	stateDB, err := api.server.stateAt(blockNrOrHash)
+	if err != nil {
+		return 0, err
+	}
+	return hexutil.Uint64(stateDB.GetNonce(address)), nil
+}
+
+// GetStorageAt returns the value of the provided storage slot of the provided account at the provided block.
+func (api *ethAPI) GetStorageAt(address common.Address, slot string, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	key, err := decodeStorageKey(slot)
+	if err != nil {
+		return nil, err
+	}
+	stateDB, err := api.server.stateAt(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	value := stateDB.GetState(address, key)
+	return value[:], nil
+}
+
+// Call executes a call at the provided block without committing any changes.
+// Returns the data returned by the call, or an error if one occurred. If the call reverted, a revertError is returned.
+func (api *ethAPI) Call(args transactionArgs, blockNrOrHash *rpc.BlockNumberOrHash) (hexutil.Bytes, error) {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	result, err := api.server.call(args, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	return result.Return(), nil
+}
+
+// EstimateGas estimates the gas required to execute the provided transaction on the chain head.
+// Returns the estimated gas, or an error if one occurred. If the call reverted, a revertError is returned.
+func (api *ethAPI) EstimateGas(args transactionArgs) (hexutil.Uint64, error) {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	result, err := api.server.call(args, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	// The gas used by a call excludes refunds, which must still be available during execution.
+	return hexutil.Uint64(result.UsedGas + result.RefundedGas), nil
+}
+
+// SendTransaction executes the provided transaction in a new block, which is committed to the chain.
+// Returns the transaction hash, or an error if one occurred.
+func (api *ethAPI) SendTransaction(args transactionArgs) (common.Hash, error) {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	testChain := api.server.chain
+
+	// Create a new block with our transaction and commit it.
+	block, err := testChain.PendingBlockCreate()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	err = testChain.PendingBlockAddTx(args.toMessage(testChain.State(), block.Header.GasLimit))
+	if err != nil {
+		discardErr := testChain.PendingBlockDiscard()
+		if discardErr != nil {
+			return common.Hash{}, discardErr
+		}
+		return common.Hash{}, err
+	}
+	err = testChain.PendingBlockCommit()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return block.MessageResults[len(block.MessageResults)-1].Receipt.TxHash, nil
+}
+
+// GetTransactionReceipt returns the receipt of the transaction with the provided hash, or nil if no such transaction
+// was committed to the chain.
+func (api *ethAPI) GetTransactionReceipt(txHash common.Hash) (map[string]any, error) {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	block, txIndex := api.server.findTransaction(txHash)
+	if block == nil {
+		return nil, nil
+	}
+	return marshalReceipt(block, txIndex), nil
+}
+
+// blockNumber resolves the provided block number to the number of a block in the chain. Any tags refer to the chain
+// head, except for the earliest block.
+// Returns the block number, or an error if it exceeds the chain head.
+func (s *Server) blockNumber(blockNr rpc.BlockNumber) (uint64, error) {
+	if blockNr == rpc.EarliestBlockNumber {
+		return 0, nil
+	}
+	if blockNr < 0 {
+		return s.chain.HeadBlockNumber(), nil
+	}
+	if uint64(blockNr) > s.chain.HeadBlockNumber() {
+		return 0, fmt.Errorf("block %d not found", blockNr)
+	}
+	return uint64(blockNr), nil
+}
+
+// stateAt obtains the state of the chain after the provided block. If no block is provided, or it refers to the
+// latest, pending, safe or finalized block, the current state is returned.
+// Returns the state, or an error if one occurred.
+func (s *Server) stateAt(blockNrOrHash *rpc.BlockNumberOrHash) (*state.StateDB, error) {
+	// If no block was provided, use our current state.
+	if blockNrOrHash == nil {
+		return s.chain.State(), nil
+	}
+
+	// If a block hash was provided, find the block with that hash.
+	if blockHash, ok := blockNrOrHash.Hash(); ok {
+		for _, block := range s.chain.CommittedBlocks() {
+			if block.Hash == blockHash {
+				return s.chain.StateFromRoot(block.Header.Root)
+			}
+		}
+		return nil, fmt.Errorf("block %s not found", blockHash.String())
+	}
+
+	// Otherwise, obtain the state after the provided block number. Any tags refer to the current state, except for the
+	// earliest block.
+	blockNumber, _ := blockNrOrHash.Number()
+	if blockNumber == rpc.EarliestBlockNumber {
+		blockNumber = 0
+	}
+	if blockNumber < 0 {
+		return s.chain.State(), nil
+	}
+	return s.chain.StateAfterBlockNumber(uint64(blockNumber))
+}
+
+// call executes the provided transaction as a call at the provided block without committing any changes.
+// Returns the execution result, or an error if one occurred. If the call reverted, a revertError is returned.
+func (s *Server) call(args transactionArgs, blockNrOrHash *rpc.BlockNumberOrHash) (*core.ExecutionResult, error) {
+	stateDB, err := s.stateAt(blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	result, err := s.chain.CallContract(args.toMessage(stateDB, s.chain.BlockGasLimit), stateDB)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Revert()) > 0 {
+		return nil, newRevertError(result)
+	}
+	if result.Err != nil {
+		return nil, result.Err
+	}
+	return result, nil
+}
+
+// findTransaction finds the committed block containing the transaction with the provided hash.
+// Returns the block and the index of the transaction within it, or a nil block if the transaction was not found.
+func (s *Server) findTransaction(txHash common.Hash) (*chainTypes.Block, int) {
+	blocks := s.chain.CommittedBlocks()
+	for i := len(blocks) - 1; i >= 0; i-- {
+		for txIndex, messageResults := range blocks[i].MessageResults {
+			if messageResults.Receipt.TxHash == txHash {
+				return blocks[i], txIndex
+			}
+		}
+	}
+	return nil, 0
+}
+
+// marshalBlock creates the JSON-RPC representation of the provided block. If fullTx is true, the block's transactions
+// are included in full, otherwise only their hashes are included.
+func marshalBlock(block *chainTypes.Block, fullTx bool) map[string]any {
+	transactions := make([]any, 0, len(block.Messages))
+	for txIndex, messageResults := range block.MessageResults {
+		if fullTx {
+			transactions = append(transactions, marshalTransaction(block, txIndex))
+		} else {
+			transactions = append(transactions, messageResults.Receipt.TxHash)
+		}
+	}
+	header := block.Header
+	return map[string]any{
+		"number":           (*hexutil.Big)(header.Number),
+		"hash":             block.Hash,
+		"parentHash":       header.ParentHash,
+		"nonce":            header.Nonce,
+		"mixHash":          header.MixDigest,
+		"sha3Uncles":       header.UncleHash,
+		"logsBloom":        header.Bloom,
+		"stateRoot":        header.Root,
+		"miner":            header.Coinbase,
+		"difficulty":       (*hexutil.Big)(header.Difficulty),
+		"extraData":        hexutil.Bytes(header.Extra),
+		"gasLimit":         hexutil.Uint64(header.GasLimit),
+		"gasUsed":          hexutil.Uint64(header.GasUsed),
+		"timestamp":        hexutil.Uint64(header.Time),
+		"transactionsRoot": header.TxHash,
+		"receiptsRoot":     header.ReceiptHash,
+		"baseFeePerGas":    (*hexutil.Big)(header.BaseFee),
+		"transactions":     transactions,
+		"uncles":           []common.Hash{},
+	}
+}
+
+// marshalTransaction creates the JSON-RPC representation of the transaction at the provided index in the provided
+// block.
+func marshalTransaction(block *chainTypes.Block, txIndex int) map[string]any {
+	message := block.Messages[txIndex]
+	return map[string]any{
+		"blockHash":        block.Hash,
+		"blockNumber":      (*hexutil.Big)(block.Header.Number),
+		"hash":             block.MessageResults[txIndex].Receipt.TxHash,
+		"transactionIndex": hexutil.Uint64(txIndex),
+		"type":             hexutil.Uint64(block.MessageResults[txIndex].Receipt.Type),
+		"from":             message.From,
+		"to":               message.To,
+		"nonce":            hexutil.Uint64(message.Nonce),
+		"gas":              hexutil.Uint64(message.GasLimit),
+		"gasPrice":         (*hexutil.Big)(message.GasPrice),
+		"value":            (*hexutil.Big)(message.Value),
+		"input":            hexutil.Bytes(message.Data),
+	}
+}
+
+// blockRewards obtains the priority fees paid by the transactions in the provided block at each of the provided
+// percentiles of the gas used in the block, as reported by eth_feeHistory.
+func blockRewards(block *chainTypes.Block, percentiles []float64) []*hexutil.Big {
+	// Obtain the priority fee each transaction paid, alongside the gas it used, sorted by priority fee.
+	type transactionReward struct {
+		reward  *big.Int
+		gasUsed uint64
+	}
+	transactionRewards := make([]transactionReward, 0, len(block.Messages))
+	for i, message := range block.Messages {
+		gasFeeCap, gasTipCap := message.GasFeeCap, message.GasTipCap
+		if gasFeeCap == nil || gasTipCap == nil {
+			gasFeeCap, gasTipCap = message.GasPrice, message.GasPrice
+		}
+		reward := new(big.Int).Sub(gasFeeCap, block.Header.BaseFee)
+		if reward.Cmp(gasTipCap) > 0 {
+			reward.Set(gasTipCap)
+		}
+		if reward.Sign() < 0 {
+			reward.SetUint64(0)
+		}
+		transactionRewards = append(transactionRewards, transactionReward{reward, block.MessageResults[i].Receipt.GasUsed})
+	}
+	sort.SliceStable(transactionRewards, func(i, j int) bool {
+		return transactionRewards[i].reward.Cmp(transactionRewards[j].reward) < 0
+	})
+
+	// Report the priority fee of the transaction the gas used at each percentile falls within.
+	rewards := make([]*hexutil.Big, len(percentiles))
+	txIndex, cumulativeGasUsed := 0, uint64(0)
+	for i, percentile := range percentiles {
+		if len(transactionRewards) == 0 {
+			rewards[i] = (*hexutil.Big)(big.NewInt(0))
+			continue
+		}
+		threshold := uint64(float64(block.Header.GasUsed) * percentile / 100)
+		for txIndex < len(transactionRewards)-1 && cumulativeGasUsed+transactionRewards[txIndex].gasUsed < threshold {
+			cumulativeGasUsed += transactionRewards[txIndex].gasUsed
+			txIndex++
+		}
+		rewards[i] = (*hexutil.Big)(transactionRewards[txIndex].reward)
+	}
+	return rewards
+}
+
+// marshalReceipt creates the JSON-RPC representation of the receipt for the transaction at the provided index in
+// the provided block.
+func marshalReceipt(block *chainTypes.Block, txIndex int) map[string]any {
+	message := block.Messages[txIndex]
+	receipt := block.MessageResults[txIndex].Receipt
+	fields := map[string]any{
+		"blockHash":         block.Hash,
+		"blockNumber":       (*hexutil.Big)(block.Header.Number),
+		"transactionHash":   receipt.TxHash,
+		"transactionIndex":  hexutil.Uint64(txIndex),
+		"from":              message.From,
+		"to":                message.To,
+		"gasUsed":           hexutil.Uint64(receipt.GasUsed),
+		"cumulativeGasUsed": hexutil.Uint64(receipt.CumulativeGasUsed),
+		"contractAddress":   nil,
+		"logs":              receipt.Logs,
+		"logsBloom":         receipt.Bloom,
+		"type":              hexutil.Uint(receipt.Type),
+		"effectiveGasPrice": (*hexutil.Big)(message.GasPrice),
+		"status":            hexutil.Uint(receipt.Status),
+	}
+	if receipt.Logs == nil {
+		fields["logs"] = []*types.Log{}
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		fields["contractAddress"] = receipt.ContractAddress
+	}
+	return fields
+}
+
+// decodeStorageKey decodes a hex encoded storage slot key of up to 32 bytes, which may omit leading zeros.
+// Returns the storage key, or an error if one occurred.
+func decodeStorageKey(key string) (common.Hash, error) {
+	key = strings.TrimPrefix(strings.TrimPrefix(key, "0x"), "0X")
+	if len(key)%2 == 1 {
+		key = "0" + key
+	}
+	b, err := hex.DecodeString(key)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("invalid storage key: %v", err)
+	}
+	if len(b) > common.HashLength {
+		return common.Hash{}, fmt.Errorf("invalid storage key: exceeds %d bytes", common.HashLength)
+	}
+	return common.BytesToHash(b), nil
+}
@@ -0,0 +1,42 @@
+package rpcserver
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// evmAPI provides the methods served by a Server under the "evm" namespace, which are commonly offered by development
+// chains to manipulate the chain.
+type evmAPI struct {
+	// server describes the Server the methods are served by.
+	server *Server
+}
+
+// Snapshot records the current chain head, so the chain can later be reverted to it with evm_revert.
+// Returns the identifier of the snapshot.
+func (api *evmAPI) Snapshot() hexutil.Uint64 {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	api.server.snapshots = append(api.server.snapshots, api.server.chain.HeadBlockNumber())
+	return hexutil.Uint64(len(api.server.snapshots))
+}
+
+// Revert reverts the chain to the head recorded by the snapshot with the provided identifier. The snapshot, and any
+// snapshots taken after it, can no longer be reverted to afterward.
+// Returns a boolean indicating whether the snapshot existed and was reverted to, or an error if one occurred.
+func (api *evmAPI) Revert(snapshotId hexutil.Uint64) (bool, error) {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+
+	// If the snapshot does not exist, there is nothing to revert to.
+	if snapshotId == 0 || uint64(snapshotId) > uint64(len(api.server.snapshots)) {
+		return false, nil
+	}
+
+	// Revert to our snapshot and remove it, along with any later snapshots.
+	err := api.server.chain.RevertToBlockNumber(api.server.snapshots[snapshotId-1])
+	if err != nil {
+		return false, err
+	}
+	api.server.snapshots = api.server.snapshots[:snapshotId-1]
+	return true, nil
+}
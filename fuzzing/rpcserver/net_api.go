@@ -0,0 +1,14 @@
+package rpcserver
+
+// netAPI provides the methods served by a Server under the "net" namespace.
+type netAPI struct {
+	// server describes the Server the methods are served by.
+	server *Server
+}
+
+// Version returns the network identifier of the chain, which is its chain ID.
+func (api *netAPI) Version() string {
+	api.server.lock.Lock()
+	defer api.server.lock.Unlock()
+	return api.server.chain.ChainConfig().ChainID.String()
+}
@@ -0,0 +1,132 @@
+package rpcserver
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/crytic/medusa/fuzzing/contracts"
+	"github.com/crytic/medusa/fuzzing/executiontracer"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Server serves a chain.TestChain over JSON-RPC, exposing a subset of the standard Ethereum JSON-RPC methods so the
+// chain's state can be inspected and interacted with using external tooling. Every transaction sent to the server is
+// included in its own block, which is committed immediately.
+type Server struct {
+	// chain describes the test chain which is served.
+	chain *chain.TestChain
+
+	// accounts describes the accounts reported by the server as being available to send transactions from.
+	accounts []common.Address
+
+	// executionTracer describes the tracer attached to the chain to record execution traces for every transaction.
+	executionTracer *executiontracer.ExecutionTracer
+
+	// executionTraces describes the execution traces recorded for each transaction, keyed by transaction hash.
+	executionTraces map[common.Hash]*executiontracer.ExecutionTrace
+
+	// snapshots describes the head block numbers recorded by evm_snapshot, where a snapshot's identifier is its index
+	// in the slice plus one.
+	snapshots []uint64
+
+	// rpcServer describes the underlying JSON-RPC server which methods are registered with.
+	rpcServer *rpc.Server
+
+	// httpServer describes the HTTP server the JSON-RPC server is served over, once it has started listening.
+	httpServer *http.Server
+
+	// closed indicates whether Close was called, in which case the server can no longer start listening.
+	closed bool
+
+	// lock describes a thread lock used to serialize access to the chain, as JSON-RPC requests are handled
+	// concurrently.
+	lock sync.Mutex
+}
+
+// NewServer creates a new Server for the provided chain. The provided contract definitions are used to resolve
+// contracts in execution traces, and the provided accounts are reported as the accounts available to send
+// transactions from. Execution traces are recorded for every transaction added to the chain after the server is
+// created, so it should be created before any transactions of interest are executed.
+// Returns the server, or an error if one occurred.
+func NewServer(testChain *chain.TestChain, contractDefinitions contracts.Contracts, accounts []common.Address) (*Server, error) {
+	// Create our server.
+	s := &Server{
+		chain:           testChain,
+		accounts:        accounts,
+		executionTracer: executiontracer.NewExecutionTracer(contractDefinitions, testChain.CheatCodeContracts()),
+		executionTraces: make(map[common.Hash]*executiontracer.ExecutionTrace),
+		snapshots:       make([]uint64, 0),
+		rpcServer:       rpc.NewServer(),
+	}
+
+	// Attach our execution tracer to the chain and record its trace for every transaction added to it.
+	testChain.AddTracer(s.executionTracer.NativeTracer(), true, false)
+	testChain.Events.PendingBlockAddedTx.Subscribe(s.onPendingBlockAddedTx)
+
+	// Register our JSON-RPC methods.
+	apis := map[string]any{
+		"eth":   &ethAPI{server: s},
+		"debug": &debugAPI{server: s},
+		"evm":   &evmAPI{server: s},
+		"net":   &netAPI{server: s},
+	}
+	for namespace, api := range apis {
+		err := s.rpcServer.RegisterName(namespace, api)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// ListenAndServe serves the JSON-RPC server over HTTP on the provided address. This operation will not return until
+// an error is encountered or the server is closed using Close. If the server was already closed, it returns immediately.
+// Returns an error if one occurred.
+func (s *Server) ListenAndServe(address string) error {
+	// Create our HTTP server, unless we were closed before we started listening.
+	s.lock.Lock()
+	if s.closed {
+		s.lock.Unlock()
+		return nil
+	}
+	httpServer := &http.Server{
+		Addr:    address,
+		Handler: s.rpcServer,
+	}
+	s.httpServer = httpServer
+	s.lock.Unlock()
+
+	// Serve our requests until we are closed.
+	err := httpServer.ListenAndServe()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// Close stops the server, causing any ongoing ListenAndServe operation to return.
+// Returns an error if one occurred.
+func (s *Server) Close() error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// Stop our JSON-RPC server and close our HTTP server if it was started. If it was not, it will not be started.
+	s.closed = true
+	s.rpcServer.Stop()
+	if s.httpServer != nil {
+		return s.httpServer.Close()
+	}
+	return nil
+}
+
+// onPendingBlockAddedTx is the event handler triggered when a transaction is added to the chain's pending block. It
+// records the execution trace for the transaction. This is invoked while the server lock is held, or before the server
+// is serving requests.
+func (s *Server) onPendingBlockAddedTx(event chain.PendingBlockAddedTxEvent) error {
+	txHash := event.Block.MessageResults[event.TransactionIndex-1].Receipt.TxHash
+	s.executionTraces[txHash] = s.executionTracer.GetTrace(txHash)
+	return nil
+}
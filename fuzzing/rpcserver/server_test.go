@@ -0,0 +1,166 @@
+package rpcserver
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/crytic/medusa/chain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestServerMethods creates a Server for a TestChain and tests its JSON-RPC methods by deploying a contract which
+// writes to storage, inspecting the result, and reverting it with a snapshot.
+func TestServerMethods(t *testing.T) {
+	// Create a test chain with a funded sender.
+	sender := common.HexToAddress("0x10000")
+	testChain, err := chain.NewTestChain(types.GenesisAlloc{
+		sender: {Balance: new(big.Int).Exp(big.NewInt(10), big.NewInt(30), nil)},
+	}, nil)
+	assert.NoError(t, err)
+	defer testChain.Close()
+
+	// Create our server and connect a client to it.
+	server, err := NewServer(testChain, nil, []common.Address{sender})
+	assert.NoError(t, err)
+	defer server.Close()
+	client := rpc.DialInProc(server.rpcServer)
+	defer client.Close()
+
+	// Verify our chain ID and accounts are reported.
+	var chainId hexutil.Big
+	err = client.Call(&chainId, "eth_chainId")
+	assert.NoError(t, err)
+	assert.EqualValues(t, testChain.ChainConfig().ChainID, chainId.ToInt())
+	var accounts []common.Address
+	err = client.Call(&accounts, "eth_accounts")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []common.Address{sender}, accounts)
+
+	// Take a snapshot before we deploy our contract.
+	var snapshotId hexutil.Uint64
+	err = client.Call(&snapshotId, "evm_snapshot")
+	assert.NoError(t, err)
+
+	// Deploy a contract whose init code stores 0x2a in slot zero (PUSH1 0x2a, PUSH1 0x00, SSTORE, STOP).
+	var txHash common.Hash
+	err = client.Call(&txHash, "eth_sendTransaction", map[string]any{
+		"from": sender,
+		"data": hexutil.Bytes(common.FromHex("0x602a60005500")),
+	})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, testChain.HeadBlockNumber())
+
+	// Verify our receipt reports the contract deployment.
+	var receipt map[string]any
+	err = client.Call(&receipt, "eth_getTransactionReceipt", txHash)
+	assert.NoError(t, err)
+	contractAddress := crypto.CreateAddress(sender, 0)
+	assert.EqualValues(t, "0x1", receipt["status"])
+	assert.EqualValues(t, txHash.String(), receipt["transactionHash"])
+	assert.EqualValues(t, contractAddress, common.HexToAddress(receipt["contractAddress"].(string)))
+
+	// Verify the slot was written, and the nonce of our sender was incremented.
+	var value hexutil.Bytes
+	err = client.Call(&value, "eth_getStorageAt", contractAddress, "0x0", "latest")
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.BigToHash(big.NewInt(0x2a)).Bytes(), []byte(value))
+	var nonce hexutil.Uint64
+	err = client.Call(&nonce, "eth_getTransactionCount", sender, "latest")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, nonce)
+
+	// Verify the slot was not written prior to our deployment.
+	err = client.Call(&value, "eth_getStorageAt", contractAddress, "0x0", "0x0")
+	assert.NoError(t, err)
+	assert.EqualValues(t, common.Hash{}.Bytes(), []byte(value))
+
+	// Verify we can trace our deployment.
+	var trace map[string]any
+	err = client.Call(&trace, "debug_traceTransaction", txHash)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "CREATE", trace["type"])
+	assert.EqualValues(t, contractAddress, common.HexToAddress(trace["to"].(string)))
+	assert.EqualValues(t, "0x602a60005500", trace["input"])
+
+	// Verify our block is reported by number and hash, with our transaction.
+	var block map[string]any
+	err = client.Call(&block, "eth_getBlockByNumber", "latest", false)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "0x1", block["number"])
+	assert.EqualValues(t, []any{txHash.String()}, block["transactions"])
+	var blockByHash map[string]any
+	err = client.Call(&blockByHash, "eth_getBlockByHash", block["hash"], true)
+	assert.NoError(t, err)
+	assert.EqualValues(t, block["hash"], blockByHash["hash"])
+	assert.EqualValues(t, txHash.String(), blockByHash["transactions"].([]any)[0].(map[string]any)["hash"])
+	block = nil
+	err = client.Call(&block, "eth_getBlockByNumber", "0x2", false)
+	assert.NoError(t, err)
+	assert.Nil(t, block)
+
+	// Verify our fee history and network version are reported.
+	var feeHistory map[string]any
+	err = client.Call(&feeHistory, "eth_feeHistory", "0x4", "latest", []float64{50})
+	assert.NoError(t, err)
+	assert.EqualValues(t, "0x0", feeHistory["oldestBlock"])
+	assert.Len(t, feeHistory["baseFeePerGas"], 3)
+	assert.Len(t, feeHistory["gasUsedRatio"], 2)
+	assert.Len(t, feeHistory["reward"], 2)
+	var maxPriorityFee hexutil.Big
+	err = client.Call(&maxPriorityFee, "eth_maxPriorityFeePerGas")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, maxPriorityFee.ToInt().Sign())
+	var networkVersion string
+	err = client.Call(&networkVersion, "net_version")
+	assert.NoError(t, err)
+	assert.EqualValues(t, testChain.ChainConfig().ChainID.String(), networkVersion)
+
+	// Verify a call returns its result. We call the identity precompile, which returns its input.
+	var result hexutil.Bytes
+	err = client.Call(&result, "eth_call", map[string]any{
+		"from": sender,
+		"to":   common.BytesToAddress([]byte{4}),
+		"data": hexutil.Bytes{1, 2, 3},
+	}, "latest")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []byte{1, 2, 3}, []byte(result))
+
+	// Revert to our snapshot and verify our deployment was removed, along with its receipt.
+	var reverted bool
+	err = client.Call(&reverted, "evm_revert", snapshotId)
+	assert.NoError(t, err)
+	assert.True(t, reverted)
+	assert.EqualValues(t, 0, testChain.HeadBlockNumber())
+	assert.EqualValues(t, 0, testChain.State().GetNonce(sender))
+	receipt = nil
+	err = client.Call(&receipt, "eth_getTransactionReceipt", txHash)
+	assert.NoError(t, err)
+	assert.Nil(t, receipt)
+
+	// Our snapshot should no longer be available.
+	err = client.Call(&reverted, "evm_revert", snapshotId)
+	assert.NoError(t, err)
+	assert.False(t, reverted)
+}
+
+// TestServerCloseBeforeListen ensures a Server which is closed before it starts listening does not begin serving
+// requests.
+func TestServerCloseBeforeListen(t *testing.T) {
+	// Create a test chain and a server for it.
+	testChain, err := chain.NewTestChain(types.GenesisAlloc{}, nil)
+	assert.NoError(t, err)
+	defer testChain.Close()
+	server, err := NewServer(testChain, nil, nil)
+	assert.NoError(t, err)
+
+	// Close our server, then verify it returns immediately when asked to listen.
+	err = server.Close()
+	assert.NoError(t, err)
+	err = server.ListenAndServe("127.0.0.1:0")
+	assert.NoError(t, err)
+}
@@ -4,6 +4,7 @@ import (
 	"math/big"
 	"sync"
 
+	"github.com/crytic/medusa/chain"
 	"github.com/crytic/medusa/compilation/abiutils"
 	"github.com/crytic/medusa/fuzzing/calls"
 	"github.com/crytic/medusa/fuzzing/config"
@@ -71,6 +72,12 @@ func (t *AssertionTestCaseProvider) checkAssertionFailures(callSequence calls.Ca
 		failure = encounteredAssertionFailure(panicCode.Uint64(), t.fuzzer.config.Fuzzing.Testing.AssertionTesting.PanicCodeConfig)
 	}
 
+	// Cheat code expectations which were not met (e.g. a call which was expected to revert did not) are treated as
+	// failed assertions.
+	if len(chain.GetCheatCodeExpectationFailures(lastCall.ChainReference.MessageResults())) > 0 {
+		failure = failure || t.fuzzer.config.Fuzzing.Testing.AssertionTesting.PanicCodeConfig.FailOnAssertion
+	}
+
 	return &methodId, failure, nil
 }
 
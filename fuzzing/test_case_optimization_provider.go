@@ -79,15 +79,17 @@ func (t *OptimizationTestCaseProvider) runOptimizationTest(worker *FuzzerWorker,
 	value := big.NewInt(0)
 	// TODO: Determine if we should use `Senders[0]` or have a separate funded account for the optimizations.
 	msg := calls.NewCallMessage(worker.Fuzzer().senders[0], &optimizationTestMethod.Address, 0, value, worker.fuzzer.config.Fuzzing.TransactionGasLimit, nil, nil, nil, data)
-	msg.FillFromTestChainProperties(worker.chain)
+	testChain := worker.ContractChain(optimizationTestMethod.Address)
+	msg.FillFromTestChainProperties(testChain)
 
-	// Execute the call. If we are tracing, we attach an execution tracer and obtain the result.
+	// Execute the call on the chain the contract was deployed to. If we are tracing, we attach an execution tracer and
+	// obtain the result.
 	var executionResult *core.ExecutionResult
 	var executionTrace *executiontracer.ExecutionTrace
 	if trace {
-		executionResult, executionTrace, err = executiontracer.CallWithExecutionTrace(worker.chain, worker.fuzzer.contractDefinitions, msg.ToCoreMessage(), nil)
+		executionResult, executionTrace, err = executiontracer.CallWithExecutionTrace(testChain, worker.fuzzer.contractDefinitions, msg.ToCoreMessage(), nil)
 	} else {
-		executionResult, err = worker.Chain().CallContract(msg.ToCoreMessage(), nil)
+		executionResult, err = testChain.CallContract(msg.ToCoreMessage(), nil)
 	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to call optimization test method: %v", err)
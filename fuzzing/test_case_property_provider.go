@@ -82,15 +82,17 @@ func (t *PropertyTestCaseProvider) checkPropertyTestFailed(worker *FuzzerWorker,
 	// Create a call targeting our property test method
 	// TODO: Determine if we should use `Senders[0]` or have a separate funded account for the assertions.
 	msg := calls.NewCallMessage(worker.Fuzzer().senders[0], &propertyTestMethod.Address, 0, big.NewInt(0), worker.fuzzer.config.Fuzzing.TransactionGasLimit, nil, nil, nil, data)
-	msg.FillFromTestChainProperties(worker.chain)
+	testChain := worker.ContractChain(propertyTestMethod.Address)
+	msg.FillFromTestChainProperties(testChain)
 
-	// Execute the call. If we are tracing, we attach an execution tracer and obtain the result.
+	// Execute the call on the chain the contract was deployed to. If we are tracing, we attach an execution tracer and
+	// obtain the result.
 	var executionResult *core.ExecutionResult
 	var executionTrace *executiontracer.ExecutionTrace
 	if trace {
-		executionResult, executionTrace, err = executiontracer.CallWithExecutionTrace(worker.chain, worker.fuzzer.contractDefinitions, msg.ToCoreMessage(), nil)
+		executionResult, executionTrace, err = executiontracer.CallWithExecutionTrace(testChain, worker.fuzzer.contractDefinitions, msg.ToCoreMessage(), nil)
 	} else {
-		executionResult, err = worker.Chain().CallContract(msg.ToCoreMessage(), nil)
+		executionResult, err = testChain.CallContract(msg.ToCoreMessage(), nil)
 	}
 	if err != nil {
 		return false, nil, fmt.Errorf("failed to call property test method: %v", err)
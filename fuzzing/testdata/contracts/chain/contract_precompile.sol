@@ -0,0 +1,23 @@
+// This contract is installed as a precompile. It initializes an immutable value and a storage slot in its constructor,
+// so the precompile only returns the expected value if its constructor was executed.
+contract ConstructedPrecompile {
+    uint256 immutable immutableValue;
+    uint256 storedValue;
+
+    constructor() {
+        immutableValue = 7;
+        storedValue = 35;
+    }
+
+    fallback(bytes calldata) external returns (bytes memory) {
+        return abi.encode(immutableValue + storedValue);
+    }
+}
+
+// This contract verifies the precompile was constructed before it was installed.
+contract TestContract {
+    function property_precompile_constructed() public view returns (bool) {
+        (bool success, bytes memory data) = address(0x1000).staticcall("");
+        return success && data.length == 32 && abi.decode(data, (uint256)) == 42;
+    }
+}
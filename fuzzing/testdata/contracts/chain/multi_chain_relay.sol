@@ -0,0 +1,51 @@
+// This contract verifies messages emitted on one chain are relayed to another in multi-chain mode, and that property
+// tests can query contracts on other chains.
+interface CheatCodes {
+    function crossChainCall(string calldata, address, bytes calldata) external returns (bool, bytes memory);
+}
+
+contract Minter {
+    address escrow;
+    uint256 minted;
+
+    function mint(uint256 amount) public {
+        // Only accept messages relayed from the escrow, rather than calls from the fuzzer's senders.
+        require(msg.sender != address(0x10000) && msg.sender != address(0x20000) && msg.sender != address(0x30000));
+        escrow = msg.sender;
+        minted += amount;
+    }
+
+    function property_minted_never_exceeds_escrowed() public returns (bool) {
+        // If no message was relayed yet, there is nothing to compare.
+        if (escrow == address(0)) {
+            return true;
+        }
+
+        // Query the amount escrowed on the other chain.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+        (bool success, bytes memory data) = cheats.crossChainCall("l1", escrow, abi.encodeWithSignature("escrowed()"));
+        require(success);
+
+        // ASSERTION: we should never mint more than was escrowed.
+        return minted <= abi.decode(data, (uint256));
+    }
+}
+
+contract Escrow {
+    event MessageSent(address target, bytes data);
+
+    address minter;
+    uint256 public escrowed;
+
+    constructor(address _minter) {
+        minter = _minter;
+    }
+
+    function deposit(uint256 amount) public {
+        escrowed += amount;
+
+        // Large deposits mint more than was escrowed.
+        uint256 mintAmount = amount > 1000 ? amount + 1 : amount;
+        emit MessageSent(minter, abi.encodeWithSignature("mint(uint256)", mintAmount));
+    }
+}
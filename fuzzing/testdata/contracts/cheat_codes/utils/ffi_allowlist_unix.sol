@@ -0,0 +1,45 @@
+// This test ensures that the ffi cheat code only executes the commands allowed by the cheat code configuration.
+interface CheatCodes {
+    function ffi(string[] calldata) external returns (bytes memory);
+}
+
+contract TestContract {
+    // Obtain our cheat code contract reference.
+    CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+    function testAllowedCommand() public {
+        string[] memory inputs = new string[](3);
+        inputs[0] = "echo";
+        inputs[1] = "-n";
+        inputs[2] = "hello";
+        bytes memory res = cheats.ffi(inputs);
+        assert(keccak256(res) == keccak256("hello"));
+    }
+
+    function testDeniedArgument() public {
+        string[] memory inputs = new string[](3);
+        inputs[0] = "echo";
+        inputs[1] = "-n";
+        inputs[2] = "hello; rm -rf ~";
+        (bool success, ) = address(cheats).call(abi.encodeWithSelector(CheatCodes.ffi.selector, inputs));
+        assert(!success);
+    }
+
+    function testDeniedArgumentCount() public {
+        string[] memory inputs = new string[](4);
+        inputs[0] = "echo";
+        inputs[1] = "-n";
+        inputs[2] = "hello";
+        inputs[3] = "world";
+        (bool success, ) = address(cheats).call(abi.encodeWithSelector(CheatCodes.ffi.selector, inputs));
+        assert(!success);
+    }
+
+    function testDeniedExecutable() public {
+        string[] memory inputs = new string[](2);
+        inputs[0] = "ls";
+        inputs[1] = "-n";
+        (bool success, ) = address(cheats).call(abi.encodeWithSelector(CheatCodes.ffi.selector, inputs));
+        assert(!success);
+    }
+}
@@ -0,0 +1,93 @@
+// This test ensures that keys can be derived, remembered, and used to sign digests and EIP-712 typed data.
+interface CheatCodes {
+    struct Wallet {
+        address addr;
+        uint256 publicKeyX;
+        uint256 publicKeyY;
+        uint256 privateKey;
+    }
+
+    function addr(uint256) external returns (address);
+
+    function deriveKey(string calldata, uint32) external returns (uint256);
+
+    function deriveKey(string calldata, string calldata, uint32) external returns (uint256);
+
+    function rememberKey(uint256) external returns (address);
+
+    function createWallet(string calldata) external returns (Wallet memory);
+
+    function createWallet(uint256) external returns (Wallet memory);
+
+    function sign(address, bytes32) external returns (uint8, bytes32, bytes32);
+
+    function signCompact(uint256, bytes32) external returns (bytes32, bytes32);
+
+    function signCompact(address, bytes32) external returns (bytes32, bytes32);
+
+    function eip712HashTypedData(string calldata) external returns (bytes32);
+}
+
+contract TestContract {
+    string constant MNEMONIC = "test test test test test test test test test test test junk";
+
+    function test() public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Derive keys from the default derivation path, and a provided one.
+        uint256 key0 = cheats.deriveKey(MNEMONIC, 0);
+        assert(key0 == 0xac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80);
+        assert(cheats.addr(key0) == 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266);
+        uint256 key1 = cheats.deriveKey(MNEMONIC, "m/44'/60'/0'/0/", 1);
+        assert(key1 == 0x59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d);
+
+        // Remember a key and sign with it by its address.
+        bytes32 digest = keccak256("Data To Sign");
+        address signer = cheats.rememberKey(key1);
+        assert(signer == 0x70997970C51812dc3A010C7d01b50e0d17dc79C8);
+        (uint8 v, bytes32 r, bytes32 s) = cheats.sign(signer, digest);
+        assert(ecrecover(digest, v, r, s) == signer);
+
+        // Sign compactly, and verify the signature by expanding it.
+        (bytes32 cr, bytes32 vs) = cheats.signCompact(key0, digest);
+        assert(_recoverCompact(digest, cr, vs) == 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266);
+        (cr, vs) = cheats.signCompact(signer, digest);
+        assert(_recoverCompact(digest, cr, vs) == signer);
+
+        // Create wallets from a label and a private key.
+        CheatCodes.Wallet memory wallet = cheats.createWallet("alice");
+        assert(wallet.privateKey == uint256(keccak256("alice")));
+        assert(wallet.addr == cheats.addr(wallet.privateKey));
+        wallet = cheats.createWallet(key0);
+        assert(wallet.addr == 0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266);
+        assert(address(uint160(uint256(keccak256(abi.encode(wallet.publicKeyX, wallet.publicKeyY))))) == wallet.addr);
+
+        // Hash typed data, and verify it against the digest computed on-chain.
+        bytes32 domainSeparator = keccak256(
+            abi.encode(
+                keccak256("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"),
+                keccak256("Test"),
+                keccak256("1"),
+                1,
+                address(0x1234)
+            )
+        );
+        bytes32 structHash = keccak256(abi.encode(keccak256("Mail(address to,uint256 amount)"), address(0x5678), 100));
+        bytes32 expectedDigest = keccak256(abi.encodePacked("\x19\x01", domainSeparator, structHash));
+        bytes32 typedDataDigest = cheats.eip712HashTypedData(
+            '{"types":{"EIP712Domain":[{"name":"name","type":"string"},{"name":"version","type":"string"},'
+            '{"name":"chainId","type":"uint256"},{"name":"verifyingContract","type":"address"}],'
+            '"Mail":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}]},"primaryType":"Mail",'
+            '"domain":{"name":"Test","version":"1","chainId":1,"verifyingContract":"0x0000000000000000000000000000000000001234"},'
+            '"message":{"to":"0x0000000000000000000000000000000000005678","amount":"100"}}'
+        );
+        assert(typedDataDigest == expectedDigest);
+    }
+
+    function _recoverCompact(bytes32 digest, bytes32 r, bytes32 vs) internal pure returns (address) {
+        bytes32 s = vs & bytes32(uint256(type(uint256).max >> 1));
+        uint8 v = uint8(uint256(vs) >> 255) + 27;
+        return ecrecover(digest, v, r, s);
+    }
+}
@@ -0,0 +1,22 @@
+// This test ensures that values added to the value dictionary with cheat codes are used as inputs by the fuzzer.
+interface CheatCodes {
+    function addToDictionary(uint256) external;
+}
+
+contract TestContract {
+    uint256 magic;
+
+    constructor() {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Compute a value the fuzzer is unlikely to generate on its own, and add it to the value dictionary.
+        magic = uint256(keccak256(abi.encode(address(this))));
+        cheats.addToDictionary(magic);
+    }
+
+    function checkMagic(uint256 x) public view {
+        // This should fail once the fuzzer uses our magic value from the dictionary.
+        assert(x != magic);
+    }
+}
@@ -0,0 +1,31 @@
+// This test ensures that only the callers allowed by the cheat code configuration can call cheat codes.
+interface CheatCodes {
+    function warp(uint256) external;
+}
+
+contract TrustedContract {
+    // Obtain our cheat code contract reference. This contract is predeployed, so we cannot rely on storage.
+    CheatCodes constant cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+    function warp(uint256 timestamp) public {
+        cheats.warp(timestamp);
+    }
+}
+
+contract TestContract {
+    // Obtain our cheat code contract reference.
+    CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+    // Obtain our trusted contract reference, which is the only allowed caller.
+    TrustedContract trusted = TrustedContract(address(0x1234));
+
+    function testAllowedCaller(uint64 timestamp) public {
+        trusted.warp(timestamp);
+        assert(block.timestamp == timestamp);
+    }
+
+    function testDeniedCaller(uint64 timestamp) public {
+        (bool success, ) = address(cheats).call(abi.encodeWithSelector(CheatCodes.warp.selector, timestamp));
+        assert(!success);
+    }
+}
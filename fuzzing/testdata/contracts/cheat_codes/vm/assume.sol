@@ -0,0 +1,15 @@
+// This test ensures that calls whose assumptions are not met are discarded rather than treated as failures.
+interface CheatCodes {
+    function assume(bool) external;
+}
+
+contract TestContract {
+    function test(uint256 x) public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Discard any odd inputs, so only even inputs reach our assertion.
+        cheats.assume(x % 2 == 0);
+        assert(x % 2 == 0);
+    }
+}
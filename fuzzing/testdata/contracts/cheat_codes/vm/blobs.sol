@@ -0,0 +1,51 @@
+// This test ensures that the blob hashes and blob base fee can be set with cheat codes
+interface CheatCodes {
+    function blobhashes(bytes32[] calldata) external;
+    function blobBaseFee(uint256) external;
+}
+
+contract TestContract {
+    // The BLOBHASH and BLOBBASEFEE opcodes are not accessible in this solidity version, so we deploy a contract which
+    // returns BLOBHASH for the index provided in calldata, followed by BLOBBASEFEE.
+    address reader;
+
+    constructor() {
+        bytes memory initCode = hex"6010600c60003960106000f3600035496000524a60205260406000f3";
+        address deployed;
+        assembly {
+            deployed := create(0, add(initCode, 0x20), mload(initCode))
+        }
+        reader = deployed;
+    }
+
+    function readBlobValues(uint256 index) internal view returns (bytes32, uint256) {
+        (bool success, bytes memory data) = reader.staticcall(abi.encode(index));
+        assert(success);
+        return abi.decode(data, (bytes32, uint256));
+    }
+
+    function test(bytes32 x, uint256 y) public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Change blob hashes and verify.
+        bytes32[] memory hashes = new bytes32[](2);
+        hashes[0] = x;
+        hashes[1] = bytes32(uint256(7));
+        cheats.blobhashes(hashes);
+        (bytes32 blobHash, ) = readBlobValues(0);
+        assert(blobHash == x);
+        (blobHash, ) = readBlobValues(1);
+        assert(blobHash == bytes32(uint256(7)));
+        (blobHash, ) = readBlobValues(2);
+        assert(blobHash == bytes32(0));
+
+        // Change blob base fee and verify.
+        cheats.blobBaseFee(y);
+        (, uint256 fee) = readBlobValues(0);
+        assert(fee == y);
+        cheats.blobBaseFee(7);
+        (, fee) = readBlobValues(0);
+        assert(fee == 7);
+    }
+}
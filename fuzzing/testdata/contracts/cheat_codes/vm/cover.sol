@@ -0,0 +1,26 @@
+// This test ensures that milestones reached with the cover cheat code are recorded, unless the call reverts.
+interface CheatCodes {
+    function cover(string calldata) external;
+}
+
+contract TestContract {
+    // Obtain our cheat code contract reference.
+    CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+    uint256 stage;
+
+    function advance(uint256 x) public {
+        if (stage == 0 && x % 2 == 0) {
+            stage = 1;
+            cheats.cover("stage one");
+        } else if (stage == 1 && x % 2 == 1) {
+            stage = 2;
+            cheats.cover("stage two");
+        }
+    }
+
+    function coverAndRevert() public {
+        cheats.cover("reverted");
+        revert();
+    }
+}
@@ -0,0 +1,55 @@
+// This test ensures that contracts can be deployed from the compilation artifacts with cheat codes.
+interface CheatCodes {
+    function getCode(string calldata) external returns (bytes memory);
+
+    function getDeployedCode(string calldata) external returns (bytes memory);
+
+    function deployCode(string calldata) external returns (address);
+
+    function deployCode(string calldata, bytes calldata) external returns (address);
+}
+
+contract Counter {
+    uint256 public count;
+
+    constructor(uint256 initialCount) {
+        count = initialCount;
+    }
+
+    function increment() public {
+        count++;
+    }
+}
+
+contract Empty {
+}
+
+contract TestContract {
+    function test() public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Verify we obtain the same bytecode as the compiler, whether we refer to a contract by its name alone or
+        // alongside its source file.
+        assert(keccak256(cheats.getCode("Counter")) == keccak256(type(Counter).creationCode));
+        assert(keccak256(cheats.getCode("deploy_code.sol:Counter")) == keccak256(type(Counter).creationCode));
+        assert(keccak256(cheats.getDeployedCode("Empty")) == keccak256(type(Empty).runtimeCode));
+
+        // Deploy a contract with constructor arguments, and verify it was constructed with them.
+        Counter counter = Counter(cheats.deployCode("Counter", abi.encode(uint256(7))));
+        assert(address(counter).code.length > 0);
+        assert(counter.count() == 7);
+        counter.increment();
+        assert(counter.count() == 8);
+
+        // Deploy a contract without constructor arguments.
+        address empty = cheats.deployCode("deploy_code.sol:Empty");
+        assert(keccak256(empty.code) == keccak256(type(Empty).runtimeCode));
+        assert(empty != address(counter));
+
+        // Verify a contract which does not exist cannot be deployed.
+        try cheats.deployCode("Missing") {
+            assert(false);
+        } catch {}
+    }
+}
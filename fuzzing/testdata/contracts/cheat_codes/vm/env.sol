@@ -0,0 +1,50 @@
+// This test ensures that environment variables can be read with cheat codes. The environment variables are set by the
+// test which runs this contract.
+interface CheatCodes {
+    function envUint(string calldata) external returns (uint256);
+
+    function envInt(string calldata) external returns (int256);
+
+    function envAddress(string calldata) external returns (address);
+
+    function envBool(string calldata) external returns (bool);
+
+    function envBytes32(string calldata) external returns (bytes32);
+
+    function envString(string calldata) external returns (string memory);
+
+    function envOr(string calldata, uint256) external returns (uint256);
+
+    function envOr(string calldata, string calldata) external returns (string memory);
+}
+
+contract TestContract {
+    function test() public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Verify each environment variable is parsed as expected.
+        assert(cheats.envUint("MEDUSA_TEST_UINT") == 42);
+        assert(cheats.envInt("MEDUSA_TEST_INT") == -42);
+        assert(cheats.envAddress("MEDUSA_TEST_ADDRESS") == address(cheats));
+        assert(cheats.envBool("MEDUSA_TEST_BOOL"));
+        assert(cheats.envBytes32("MEDUSA_TEST_BYTES32") == bytes32(hex"1234"));
+        assert(keccak256(bytes(cheats.envString("MEDUSA_TEST_STRING"))) == keccak256("medusa"));
+
+        // Verify defaults are only used if an environment variable is not set.
+        assert(cheats.envOr("MEDUSA_TEST_UINT", uint256(7)) == 42);
+        assert(cheats.envOr("MEDUSA_TEST_UNSET", uint256(7)) == 7);
+        assert(keccak256(bytes(cheats.envOr("MEDUSA_TEST_UNSET", "default"))) == keccak256("default"));
+
+        // Verify environment variables which are not set, or cannot be parsed, cause a revert.
+        try cheats.envUint("MEDUSA_TEST_UNSET") {
+            assert(false);
+        } catch {}
+        try cheats.envUint("MEDUSA_TEST_STRING") {
+            assert(false);
+        } catch {}
+        try cheats.envUint("MEDUSA_TEST_INT") {
+            assert(false);
+        } catch {}
+    }
+}
@@ -0,0 +1,53 @@
+// This test ensures that expected calls can be set with cheat codes, and that they are matched against calls made by
+// the caller and any calls it makes before it exits.
+interface CheatCodes {
+    function expectCall(address, bytes calldata) external;
+    function expectCall(address, bytes calldata, uint256) external;
+}
+
+contract Vault {
+    uint256 public deposits;
+
+    function deposit(uint256 amount) public payable {
+        deposits += amount;
+    }
+}
+
+contract Router {
+    Vault vault;
+
+    constructor(Vault _vault) {
+        vault = _vault;
+    }
+
+    function route(uint256 amount) public payable {
+        vault.deposit{value: msg.value}(amount);
+    }
+}
+
+contract TestContract {
+    Vault vault;
+    Router router;
+
+    constructor() payable {
+        vault = new Vault();
+        router = new Router(vault);
+    }
+
+    function test() public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Expect a call with exact call data, made by a call we make.
+        cheats.expectCall(address(vault), abi.encodeWithSelector(Vault.deposit.selector, 100));
+        router.route(100);
+
+        // Expect a call to a function with any arguments.
+        cheats.expectCall(address(vault), abi.encodeWithSelector(Vault.deposit.selector));
+        router.route(200);
+
+        // Expect a call with a specific value.
+        cheats.expectCall(address(vault), abi.encodeWithSelector(Vault.deposit.selector, 0), 0);
+        router.route(0);
+    }
+}
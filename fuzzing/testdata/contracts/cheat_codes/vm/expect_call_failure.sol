@@ -0,0 +1,28 @@
+// This test ensures that a call which is expected but not made before the caller exits is reported as an assertion
+// failure.
+interface CheatCodes {
+    function expectCall(address, bytes calldata) external;
+}
+
+contract Vault {
+    function deposit(uint256 amount) public {}
+}
+
+contract TestContract {
+    Vault vault;
+
+    constructor() {
+        vault = new Vault();
+    }
+
+    function test(uint256 amount) public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Expect a deposit which is only made for non-zero amounts, so the expectation is unmet for zero.
+        cheats.expectCall(address(vault), abi.encodeWithSelector(Vault.deposit.selector, amount));
+        if (amount > 0) {
+            vault.deposit(amount);
+        }
+    }
+}
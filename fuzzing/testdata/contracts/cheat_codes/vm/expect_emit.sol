@@ -0,0 +1,58 @@
+// This test ensures that expected events can be set with cheat codes, and that they are matched against events emitted
+// by the caller and any calls it makes before it exits.
+interface CheatCodes {
+    function expectEmit(bool, bool, bool, bool) external;
+    function expectEmit(bool, bool, bool, bool, address) external;
+}
+
+contract Token {
+    event Transfer(address indexed from, address indexed to, uint256 amount);
+    event Approval(address indexed owner, address indexed spender, uint256 amount);
+
+    function transfer(address to, uint256 amount) public {
+        emit Transfer(msg.sender, to, amount);
+    }
+
+    function transferAndApprove(address to, uint256 amount) public {
+        emit Transfer(msg.sender, to, amount);
+        emit Approval(msg.sender, to, amount);
+    }
+}
+
+contract TestContract {
+    event Transfer(address indexed from, address indexed to, uint256 amount);
+    event Approval(address indexed owner, address indexed spender, uint256 amount);
+
+    Token token;
+
+    constructor() {
+        token = new Token();
+    }
+
+    function test() public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Expect an event matching all topics and data.
+        cheats.expectEmit(true, true, true, true);
+        emit Transfer(address(this), address(1), 100);
+        token.transfer(address(1), 100);
+
+        // Expect an event while ignoring its recipient and amount.
+        cheats.expectEmit(true, false, false, false);
+        emit Transfer(address(this), address(0), 0);
+        token.transfer(address(2), 200);
+
+        // Expect an event to be emitted by a specific address.
+        cheats.expectEmit(true, true, false, true, address(token));
+        emit Transfer(address(this), address(3), 300);
+        token.transfer(address(3), 300);
+
+        // Expect multiple events, in the order they are emitted.
+        cheats.expectEmit(true, true, true, true);
+        emit Transfer(address(this), address(4), 400);
+        cheats.expectEmit(true, true, true, true);
+        emit Approval(address(this), address(4), 400);
+        token.transferAndApprove(address(4), 400);
+    }
+}
@@ -0,0 +1,25 @@
+// This test ensures that an event which is expected but not emitted before the caller exits is reported as an
+// assertion failure.
+interface CheatCodes {
+    function expectEmit(bool, bool, bool, bool) external;
+}
+
+contract TestContract {
+    event Transfer(address indexed from, address indexed to, uint256 amount);
+
+    function transfer(address to, uint256 amount) public {
+        // Only emit the event for non-zero amounts, so the expectation is unmet for zero.
+        if (amount > 0) {
+            emit Transfer(msg.sender, to, amount);
+        }
+    }
+
+    function test(uint256 amount) public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        cheats.expectEmit(true, true, false, false);
+        emit Transfer(address(this), address(1), amount);
+        this.transfer(address(1), amount);
+    }
+}
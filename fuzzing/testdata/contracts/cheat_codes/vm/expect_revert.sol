@@ -0,0 +1,77 @@
+// This test ensures that expected reverts can be set with cheat codes, and that calls which revert as expected are
+// reported as successful, with their return data cleared.
+interface CheatCodes {
+    function expectRevert() external;
+    function expectRevert(bytes4) external;
+    function expectRevert(bytes calldata) external;
+    function prank(address) external;
+}
+
+contract TestContract {
+    error Unauthorized(address caller);
+
+    address owner = address(7);
+
+    function restricted() public view {
+        if (msg.sender != owner) {
+            revert Unauthorized(msg.sender);
+        }
+    }
+
+    function positive(uint256 x) public pure {
+        require(x > 0, "x must be positive");
+    }
+
+    function test() public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Expect any revert.
+        cheats.expectRevert();
+        this.restricted();
+
+        // Expect a custom error by its selector alone.
+        cheats.expectRevert(Unauthorized.selector);
+        this.restricted();
+
+        // Expect a custom error with exact arguments.
+        cheats.expectRevert(abi.encodeWithSelector(Unauthorized.selector, address(this)));
+        this.restricted();
+
+        // Expect a revert reason string.
+        cheats.expectRevert(abi.encodeWithSignature("Error(string)", "x must be positive"));
+        this.positive(0);
+
+        // Calls to cheat codes between the expectation and the call it applies to should not satisfy it.
+        cheats.expectRevert(Unauthorized.selector);
+        cheats.prank(address(8));
+        this.restricted();
+
+        // Calls without an expected revert should behave as usual.
+        this.positive(1);
+        cheats.prank(owner);
+        this.restricted();
+
+        // A low-level call which reverts as expected should be reported as successful, with no return data.
+        cheats.expectRevert();
+        (bool success, bytes memory returnData) = address(this).call(abi.encodeWithSelector(this.restricted.selector));
+        assert(success);
+        assert(returnData.length == 0);
+
+        // The memory a call which reverts as expected would copy its return data to should be left unchanged.
+        bytes memory callData = abi.encodeWithSelector(this.restricted.selector);
+        uint256 returnDataSize;
+        uint256 outputWord;
+        cheats.expectRevert(Unauthorized.selector);
+        assembly {
+            let output := mload(0x40)
+            mstore(output, 0x1234)
+            success := call(gas(), address(), 0, add(callData, 0x20), mload(callData), output, 0x24)
+            returnDataSize := returndatasize()
+            outputWord := mload(output)
+        }
+        assert(success);
+        assert(returnDataSize == 0);
+        assert(outputWord == 0x1234);
+    }
+}
@@ -0,0 +1,20 @@
+// This test ensures that a call which does not revert as expected is reported as failed, and that the unmet
+// expectation is reported as an assertion failure.
+interface CheatCodes {
+    function expectRevert(bytes calldata) external;
+}
+
+contract TestContract {
+    function positive(uint256 x) public pure {
+        require(x > 0, "x must be positive");
+    }
+
+    function test(uint256 x) public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Expect a revert which only occurs for zero, so the expectation is unmet for any other input.
+        cheats.expectRevert(abi.encodeWithSignature("Error(string)", "x must be positive"));
+        this.positive(x);
+    }
+}
@@ -0,0 +1,47 @@
+// This test ensures that files can be read and written with cheat codes, within the paths allowed by the chain
+// configuration. The test which runs this contract allows "fixtures" to be read and "out" to be written, and creates
+// "fixtures/fixture.txt" containing "fixture".
+interface CheatCodes {
+    function projectRoot() external returns (string memory);
+
+    function readFile(string calldata) external returns (string memory);
+
+    function readFileBinary(string calldata) external returns (bytes memory);
+
+    function writeFile(string calldata, string calldata) external;
+
+    function exists(string calldata) external returns (bool);
+}
+
+contract TestContract {
+    function test() public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+        assert(bytes(cheats.projectRoot()).length > 0);
+
+        // Verify we can read our fixture.
+        assert(cheats.exists("fixtures/fixture.txt"));
+        assert(!cheats.exists("fixtures/missing.txt"));
+        assert(keccak256(bytes(cheats.readFile("fixtures/fixture.txt"))) == keccak256("fixture"));
+        assert(keccak256(cheats.readFileBinary("fixtures/fixture.txt")) == keccak256("fixture"));
+
+        // Verify we can write a file and read it back, as writable paths are also readable.
+        cheats.writeFile("out/nested/output.txt", "output");
+        assert(cheats.exists("out/nested/output.txt"));
+        assert(keccak256(bytes(cheats.readFile("out/nested/output.txt"))) == keccak256("output"));
+
+        // Verify paths outside those allowed cannot be accessed.
+        try cheats.writeFile("fixtures/fixture.txt", "overwritten") {
+            assert(false);
+        } catch {}
+        try cheats.readFile("file_system.sol") {
+            assert(false);
+        } catch {}
+        try cheats.readFile("fixtures/../file_system.sol") {
+            assert(false);
+        } catch {}
+        try cheats.exists("file_system.sol") {
+            assert(false);
+        } catch {}
+    }
+}
@@ -0,0 +1,51 @@
+// This test ensures that gas snapshots measure the gas used between startSnapshotGas and stopSnapshotGas, excluding
+// any gas used while gas metering is paused.
+interface CheatCodes {
+    function startSnapshotGas(string calldata) external;
+    function stopSnapshotGas() external returns (uint256);
+    function pauseGasMetering() external;
+    function resumeGasMetering() external;
+}
+
+contract TestContract {
+    // Obtain our cheat code contract reference.
+    CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+    mapping(uint256 => uint256) values;
+
+    function writeValues(uint256 count) public {
+        count = count % 10;
+
+        // Measure the gas used to write our values, which should grow with the number of values written.
+        cheats.startSnapshotGas("writeValues");
+        for (uint256 i = 0; i < count; i++) {
+            values[i] += 1;
+        }
+        uint256 gasUsed = cheats.stopSnapshotGas();
+        assert(gasUsed >= count * 5_000);
+    }
+
+    function writeValuesPaused() public {
+        // Measure the gas used to write our values while gas metering is paused, which should be negligible.
+        cheats.startSnapshotGas("writeValuesPaused");
+        cheats.pauseGasMetering();
+        for (uint256 i = 0; i < 10; i++) {
+            values[i] += 1;
+        }
+        cheats.resumeGasMetering();
+        uint256 gasUsed = cheats.stopSnapshotGas();
+        assert(gasUsed < 5_000);
+    }
+
+    function testSnapshotMisuse() public {
+        // Stopping a snapshot which was never started should revert.
+        (bool success, ) = address(cheats).call(abi.encodeWithSelector(CheatCodes.stopSnapshotGas.selector));
+        assert(!success);
+
+        // Starting a snapshot while another is in progress should revert.
+        cheats.startSnapshotGas("misuse");
+        (success, ) = address(cheats).call(abi.encodeWithSelector(CheatCodes.startSnapshotGas.selector, "misuse"));
+        assert(!success);
+        cheats.stopSnapshotGas();
+    }
+}
@@ -0,0 +1,102 @@
+// This test ensures that JSON strings can be parsed and serialized with cheat codes.
+interface CheatCodes {
+    function parseJson(string calldata) external returns (bytes memory);
+
+    function parseJson(string calldata, string calldata) external returns (bytes memory);
+
+    function keyExists(string calldata, string calldata) external returns (bool);
+
+    function parseJsonUint(string calldata, string calldata) external returns (uint256);
+
+    function parseJsonInt(string calldata, string calldata) external returns (int256);
+
+    function parseJsonAddress(string calldata, string calldata) external returns (address);
+
+    function parseJsonBool(string calldata, string calldata) external returns (bool);
+
+    function parseJsonBytes32(string calldata, string calldata) external returns (bytes32);
+
+    function parseJsonString(string calldata, string calldata) external returns (string memory);
+
+    function parseJsonUintArray(string calldata, string calldata) external returns (uint256[] memory);
+
+    function parseJsonStringArray(string calldata, string calldata) external returns (string[] memory);
+
+    function serializeUint(string calldata, string calldata, uint256) external returns (string memory);
+
+    function serializeAddress(string calldata, string calldata, address) external returns (string memory);
+
+    function serializeBool(string calldata, string calldata, bool[] calldata) external returns (string memory);
+
+    function serializeString(string calldata, string calldata, string calldata) external returns (string memory);
+}
+
+contract TestContract {
+    // Point describes a tuple matching the "point" object in our JSON, with members sorted alphabetically by key.
+    struct Point {
+        string label;
+        uint256 x;
+        int256 y;
+    }
+
+    string constant json =
+        '{"amount":"0x2a","big":1e18,"enabled":true,"owner":"0x7109709ECfa91a80626fF3989D68f67F5b1DD12D",'
+        '"root":"0x1111111111111111111111111111111111111111111111111111111111111111","values":[1,2,3],'
+        '"names":["a","b"],"point":{"x":5,"y":-6,"label":"p"}}';
+
+    function test() public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Verify typed values are parsed.
+        assert(cheats.parseJsonUint(json, ".amount") == 42);
+        assert(cheats.parseJsonUint(json, "$.big") == 1e18);
+        assert(cheats.parseJsonInt(json, ".point.y") == -6);
+        assert(cheats.parseJsonAddress(json, ".owner") == address(cheats));
+        assert(cheats.parseJsonBool(json, "['enabled']"));
+        assert(cheats.parseJsonBytes32(json, ".root") == bytes32(0x1111111111111111111111111111111111111111111111111111111111111111));
+        assert(keccak256(bytes(cheats.parseJsonString(json, ".names[1]"))) == keccak256("b"));
+        uint256[] memory values = cheats.parseJsonUintArray(json, ".values");
+        assert(values.length == 3 && values[0] == 1 && values[2] == 3);
+        string[] memory names = cheats.parseJsonStringArray(json, ".names[*]");
+        assert(names.length == 2 && keccak256(bytes(names[0])) == keccak256("a"));
+
+        // Verify values are ABI-encoded with their inferred types.
+        assert(abi.decode(cheats.parseJson(json, ".values[0]"), (uint256)) == 1);
+        assert(abi.decode(cheats.parseJson(json, ".owner"), (address)) == address(cheats));
+        Point memory point = abi.decode(cheats.parseJson(json, ".point"), (Point));
+        assert(point.x == 5 && point.y == -6 && keccak256(bytes(point.label)) == keccak256("p"));
+        uint256[] memory decodedValues = abi.decode(cheats.parseJson('{"a":[4,5]}'), (uint256[]));
+        assert(decodedValues.length == 2 && decodedValues[1] == 5);
+
+        // Verify key existence is reported.
+        assert(cheats.keyExists(json, ".point.x"));
+        assert(!cheats.keyExists(json, ".point.z"));
+        assert(!cheats.keyExists(json, ".values[3]"));
+
+        // Verify missing keys and mismatched types cause a revert.
+        try cheats.parseJsonUint(json, ".missing") {
+            assert(false);
+        } catch {}
+        try cheats.parseJsonUint(json, ".names") {
+            assert(false);
+        } catch {}
+        try cheats.parseJsonUint(json, ".point.y") {
+            assert(false);
+        } catch {}
+
+        // Verify objects are serialized with their members sorted by key, and can be nested.
+        cheats.serializeUint("inner", "b", 2);
+        string memory inner = cheats.serializeAddress("inner", "a", address(1));
+        assert(keccak256(bytes(inner)) == keccak256('{"a":"0x0000000000000000000000000000000000000001","b":2}'));
+        bool[] memory flags = new bool[](2);
+        flags[1] = true;
+        cheats.serializeBool("outer", "flags", flags);
+        string memory outer = cheats.serializeString("outer", "inner", inner);
+        assert(
+            keccak256(bytes(outer)) ==
+                keccak256('{"flags":[false,true],"inner":{"a":"0x0000000000000000000000000000000000000001","b":2}}')
+        );
+        assert(cheats.parseJsonUint(outer, ".inner.b") == 2);
+    }
+}
@@ -0,0 +1,72 @@
+// This test ensures that the results of calls can be mocked with cheat codes.
+interface CheatCodes {
+    function mockCall(address, bytes calldata, bytes calldata) external;
+    function mockCall(address, uint256, bytes calldata, bytes calldata) external;
+    function mockCallRevert(address, bytes calldata, bytes calldata) external;
+    function clearMockedCalls() external;
+    function deal(address, uint256) external;
+}
+
+interface IOracle {
+    function price(address token) external view returns (uint256);
+}
+
+contract Oracle is IOracle {
+    function price(address) external pure returns (uint256) {
+        return 1;
+    }
+
+    function deposit() external payable returns (uint256) {
+        return msg.value;
+    }
+}
+
+contract TestContract {
+    error Stale(uint256 updatedAt);
+
+    Oracle oracle;
+
+    constructor() {
+        oracle = new Oracle();
+    }
+
+    function test() public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+        address tokenA = address(10);
+        address tokenB = address(11);
+
+        // Verify our oracle returns its original price.
+        assert(oracle.price(tokenA) == 1);
+
+        // Mock any price query, then a specific one, and verify the most specific mock applies.
+        cheats.mockCall(address(oracle), abi.encodeWithSelector(Oracle.price.selector), abi.encode(5));
+        cheats.mockCall(address(oracle), abi.encodeWithSelector(Oracle.price.selector, tokenB), abi.encode(7));
+        assert(oracle.price(tokenA) == 5);
+        assert(oracle.price(tokenB) == 7);
+
+        // Mock a call with a specific value, and verify other values are unaffected.
+        cheats.deal(address(this), 3);
+        cheats.mockCall(address(oracle), 1, abi.encodeWithSelector(Oracle.deposit.selector), abi.encode(100));
+        assert(oracle.deposit{value: 1}() == 100);
+        assert(oracle.deposit{value: 2}() == 2);
+
+        // Mock a revert, and verify it reverts with the mocked data.
+        cheats.mockCallRevert(address(oracle), abi.encodeWithSelector(Oracle.price.selector, tokenA), abi.encodeWithSelector(Stale.selector, 42));
+        try oracle.price(tokenA) returns (uint256) {
+            assert(false);
+        } catch (bytes memory reason) {
+            assert(keccak256(reason) == keccak256(abi.encodeWithSelector(Stale.selector, 42)));
+        }
+
+        // Mock a call to an address without code, and verify it can be called.
+        IOracle missingOracle = IOracle(address(12));
+        cheats.mockCall(address(missingOracle), abi.encodeWithSelector(IOracle.price.selector), abi.encode(9));
+        assert(missingOracle.price(tokenA) == 9);
+
+        // Clear our mocks, and verify the original results are restored.
+        cheats.clearMockedCalls();
+        assert(oracle.price(tokenA) == 1);
+        assert(oracle.price(tokenB) == 1);
+    }
+}
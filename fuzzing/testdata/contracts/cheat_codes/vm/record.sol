@@ -0,0 +1,98 @@
+// This test ensures that storage accesses and state diffs can be recorded with cheat codes.
+interface CheatCodes {
+    struct ChainInfo {
+        uint256 forkId;
+        uint256 chainId;
+    }
+
+    struct StorageAccess {
+        address account;
+        bytes32 slot;
+        bool isWrite;
+        bytes32 previousValue;
+        bytes32 newValue;
+        bool reverted;
+    }
+
+    struct AccountAccess {
+        ChainInfo chainInfo;
+        uint8 kind;
+        address account;
+        address accessor;
+        bool initialized;
+        uint256 oldBalance;
+        uint256 newBalance;
+        bytes deployedCode;
+        uint256 value;
+        bytes data;
+        bool reverted;
+        StorageAccess[] storageAccesses;
+        uint64 depth;
+    }
+
+    function record() external;
+    function accesses(address) external returns (bytes32[] memory, bytes32[] memory);
+    function startStateDiffRecording() external;
+    function stopAndReturnStateDiff() external returns (AccountAccess[] memory);
+}
+
+contract Token {
+    mapping(address => uint256) public balanceOf;
+
+    function mint(address to, uint256 amount) public {
+        balanceOf[to] += amount;
+    }
+
+    function failingMint(address to, uint256 amount) public {
+        balanceOf[to] += amount;
+        revert();
+    }
+}
+
+contract TestContract {
+    Token immutable token;
+
+    constructor() {
+        token = new Token();
+    }
+
+    function test() public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Record storage accesses and locate the balance slot of an account.
+        cheats.record();
+        token.balanceOf(address(7));
+        (bytes32[] memory reads, bytes32[] memory writes) = cheats.accesses(address(token));
+        bytes32 balanceSlot = keccak256(abi.encode(address(7), uint256(0)));
+        assert(reads.length == 1 && reads[0] == balanceSlot);
+        assert(writes.length == 0);
+
+        // Verify writes are recorded as both reads and writes.
+        token.mint(address(7), 5);
+        (reads, writes) = cheats.accesses(address(token));
+        assert(reads.length == 3 && writes.length == 1 && writes[0] == balanceSlot);
+
+        // Verify accounts which were not accessed have no accesses.
+        (reads, writes) = cheats.accesses(address(this));
+        assert(reads.length == 0 && writes.length == 0);
+
+        // Record a state diff for a mint, and verify the call and storage changes are reported.
+        uint256 balance = token.balanceOf(address(7));
+        cheats.startStateDiffRecording();
+        token.mint(address(7), 10);
+        try token.failingMint(address(7), 20) {} catch {}
+        CheatCodes.AccountAccess[] memory diff = cheats.stopAndReturnStateDiff();
+        assert(diff.length == 2);
+
+        assert(diff[0].kind == 0 && diff[0].account == address(token) && diff[0].accessor == address(this));
+        assert(diff[0].chainInfo.chainId == block.chainid && !diff[0].reverted && diff[0].depth == 1);
+        assert(diff[0].storageAccesses.length == 2);
+        assert(!diff[0].storageAccesses[1].reverted && diff[0].storageAccesses[1].isWrite);
+        assert(diff[0].storageAccesses[1].slot == balanceSlot);
+        assert(uint256(diff[0].storageAccesses[1].previousValue) == balance);
+        assert(uint256(diff[0].storageAccesses[1].newValue) == balance + 10);
+
+        assert(diff[1].reverted && diff[1].storageAccesses.length == 2 && diff[1].storageAccesses[1].reverted);
+    }
+}
@@ -0,0 +1,69 @@
+// This test ensures that logs can be recorded with cheat codes, and that logs emitted by reverted calls are discarded.
+interface CheatCodes {
+    struct Log {
+        bytes32[] topics;
+        bytes data;
+        address emitter;
+    }
+
+    function recordLogs() external;
+
+    function getRecordedLogs() external returns (Log[] memory);
+}
+
+contract Emitter {
+    event Transfer(address indexed from, address indexed to, uint256 value);
+
+    function transfer(address to, uint256 value) public {
+        emit Transfer(msg.sender, to, value);
+    }
+
+    function transferAndRevert(address to, uint256 value) public {
+        emit Transfer(msg.sender, to, value);
+        revert();
+    }
+}
+
+contract TestContract {
+    event Checkpoint(uint256 id);
+
+    Emitter immutable emitter = new Emitter();
+
+    function test() public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Logs emitted before recording starts should not be recorded.
+        emit Checkpoint(0);
+        cheats.recordLogs();
+        assert(cheats.getRecordedLogs().length == 0);
+
+        // Emit logs in this contract and another, including one in a reverted call.
+        emit Checkpoint(1);
+        emitter.transfer(address(0x1234), 100);
+        try emitter.transferAndRevert(address(0x5678), 200) {
+            assert(false);
+        } catch {}
+
+        // Verify the logs which were not reverted were recorded in the order they were emitted.
+        CheatCodes.Log[] memory logs = cheats.getRecordedLogs();
+        assert(logs.length == 2);
+        assert(logs[0].emitter == address(this));
+        assert(logs[0].topics.length == 1);
+        assert(logs[0].topics[0] == keccak256("Checkpoint(uint256)"));
+        assert(abi.decode(logs[0].data, (uint256)) == 1);
+        assert(logs[1].emitter == address(emitter));
+        assert(logs[1].topics.length == 3);
+        assert(logs[1].topics[0] == keccak256("Transfer(address,address,uint256)"));
+        assert(logs[1].topics[1] == bytes32(uint256(uint160(address(this)))));
+        assert(logs[1].topics[2] == bytes32(uint256(0x1234)));
+        assert(abi.decode(logs[1].data, (uint256)) == 100);
+
+        // Verify logs which were returned are not returned again.
+        assert(cheats.getRecordedLogs().length == 0);
+        emit Checkpoint(2);
+        logs = cheats.getRecordedLogs();
+        assert(logs.length == 1);
+        assert(abi.decode(logs[0].data, (uint256)) == 2);
+    }
+}
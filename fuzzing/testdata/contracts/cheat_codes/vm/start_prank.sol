@@ -0,0 +1,81 @@
+// This test ensures that the msg.sender and tx.origin of all calls made by a contract can be set with cheat codes.
+// It tests startPrank (spoof msg.sender on all calls in the same scope), startPrank with an origin (also spoof
+// tx.origin), stopPrank, and readCallers.
+interface CheatCodes {
+    function prank(address) external;
+    function startPrank(address) external;
+    function startPrank(address, address) external;
+    function stopPrank() external;
+    function readCallers() external returns (uint8, address, address);
+}
+
+contract TestContract {
+    TestContract thisExternal = TestContract(address(this));
+
+    function getSender() public view returns (address) {
+        return msg.sender;
+    }
+
+    function getOrigin() public view returns (address) {
+        return tx.origin;
+    }
+
+    function getNestedSender() public view returns (address) {
+        return thisExternal.getSender();
+    }
+
+    function startPrankAndReturn(address sender) public {
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+        cheats.startPrank(sender);
+    }
+
+    function test() public {
+        // Obtain our cheat code contract reference.
+        CheatCodes cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+
+        // Cache some original variables
+        address prankSender = address(7);
+        address prankOrigin = address(8);
+        address originalOrigin = tx.origin;
+
+        // Verify no callers are modified by default.
+        (uint8 mode, address sender, address origin) = cheats.readCallers();
+        assert(mode == 0 && sender == address(this) && origin == originalOrigin);
+
+        // Start a prank and verify it applies to every call we make, but not calls made by those calls.
+        cheats.startPrank(prankSender);
+        assert(thisExternal.getSender() == prankSender);
+        assert(thisExternal.getSender() == prankSender);
+        assert(thisExternal.getOrigin() == originalOrigin);
+        assert(thisExternal.getNestedSender() == address(this));
+        (mode, sender, origin) = cheats.readCallers();
+        assert(mode == 4 && sender == prankSender && origin == originalOrigin);
+
+        // Verify a prank of a single call takes precedence over the started prank.
+        cheats.prank(address(9));
+        (mode, sender, origin) = cheats.readCallers();
+        assert(mode == 3 && sender == address(9));
+        assert(thisExternal.getSender() == address(9));
+        assert(thisExternal.getSender() == prankSender);
+
+        // Stop the prank and verify calls are no longer modified.
+        cheats.stopPrank();
+        assert(thisExternal.getSender() == address(this));
+        (mode, sender, origin) = cheats.readCallers();
+        assert(mode == 0 && sender == address(this) && origin == originalOrigin);
+
+        // Start a prank with an origin, and verify both apply to our calls, while our own tx.origin is unaffected.
+        cheats.startPrank(prankSender, prankOrigin);
+        assert(thisExternal.getSender() == prankSender);
+        assert(thisExternal.getOrigin() == prankOrigin);
+        assert(tx.origin == originalOrigin);
+        (mode, sender, origin) = cheats.readCallers();
+        assert(mode == 4 && sender == prankSender && origin == prankOrigin);
+        cheats.stopPrank();
+        assert(thisExternal.getOrigin() == originalOrigin);
+
+        // Verify a prank started by a call ends when that call exits.
+        thisExternal.startPrankAndReturn(prankSender);
+        assert(thisExternal.getSender() == address(this));
+    }
+}
@@ -0,0 +1,24 @@
+interface CheatCodes {
+    function expectEmit(bool, bool, bool, bool) external;
+}
+
+contract TestContract {
+    event Transfer(address indexed from, address indexed to, uint256 amount);
+
+    CheatCodes cheats;
+
+    constructor() {
+        cheats = CheatCodes(0x7109709ECfa91a80626fF3989D68f67F5b1DD12D);
+    }
+
+    function transfer(address to, uint256 amount) public {
+        // Emit nothing, so the expected event is never emitted.
+    }
+
+    function testExpectEmitAndFail() public {
+        // Expect a transfer event which is never emitted.
+        cheats.expectEmit(true, true, false, true);
+        emit Transfer(address(this), address(1), 100);
+        this.transfer(address(1), 100);
+    }
+}
@@ -0,0 +1,10 @@
+// This test ensures that gas prices, block base fees, and block prevrandao values are fuzzed, by asserting that they
+// never differ from their default values all at once.
+contract TestContract {
+    function checkValues() public {
+        bool gasPriceFuzzed = tx.gasprice != 1;
+        bool baseFeeFuzzed = block.basefee != 1_000_000_000;
+        bool prevRandaoFuzzed = block.prevrandao != uint256(blockhash(block.number - 1));
+        assert(!(gasPriceFuzzed && baseFeeFuzzed && prevRandaoFuzzed));
+    }
+}